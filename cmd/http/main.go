@@ -3,18 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/audricimanuel/laundry-routine-tracking-service/docs"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/app"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
-	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
-	"github.com/audricimanuel/laundry-routine-tracking-service/internal/middleware"
-	authController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/controller"
-	authRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/repository"
-	authService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/service"
-	laundryController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/controller"
-	laundryRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
-	laundryService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/service"
-	httpServer "github.com/audricimanuel/laundry-routine-tracking-service/internal/server/http"
-	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
 	"github.com/sirupsen/logrus"
 	"net/http"
 	"os"
@@ -22,14 +12,6 @@ import (
 	"time"
 )
 
-func setSwaggerInfo() {
-	docs.SwaggerInfo.Title = "Laundry Tracking API"
-	docs.SwaggerInfo.Description = "Laundry Tracking API"
-	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.BasePath = "/"
-	docs.SwaggerInfo.Schemes = []string{"https", "http"}
-}
-
 func main() {
 	// load config
 	cfg, err := config.LoadConfig()
@@ -37,45 +19,19 @@ func main() {
 		logrus.Fatal(err)
 	}
 
-	// initialize mongodb connection
-	databaseCollection := database.NewDatabaseCollection(cfg)
-	defer func() {
-		databaseCollection.PostgresDBSqlx.Close()
-	}()
-
-	// tools
-	smtpClient := tools.NewSMTPClient(cfg)
-
-	// repositories
-	authRepo := authRepository.NewAuthRepository(cfg, databaseCollection)
-	laundryRepo := laundryRepository.NewLaundryRepository(databaseCollection)
-
-	// services
-	authServ := authService.NewAuthService(cfg, smtpClient, authRepo)
-	laundrySvc := laundryService.NewLaundryService(laundryRepo)
+	service := app.New(cfg)
+	defer service.Close()
 
-	// controllers
-	authCtrl := authController.NewAuthController(cfg, authServ)
-	laundryCtrl := laundryController.NewLaundryController(laundrySvc)
-
-	// set swagger info
-	setSwaggerInfo()
-
-	// registering router
-	router := httpServer.RegisterRouter(
-		cfg,
-
-		// register additional middlewares here
-		middleware.NewAuthMiddleware(cfg, authRepo),
-
-		// register controllers in here
-		authCtrl,
-		laundryCtrl,
-	)
+	// background workers, stopped after the server has shut down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	runner := service.RunWorkers(workerCtx)
 
 	// running server
 	logrus.Println("[INFO] Loading server")
-	runServer(cfg, router)
+	runServer(cfg, service.Router)
+
+	stopWorkers()
+	runner.Wait()
 }
 
 func runServer(cfg config.Config, route http.Handler) {
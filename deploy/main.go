@@ -1,7 +1,34 @@
+// Package handler serves the service as a serverless function. The templates and assets are embedded in the
+// binary, the background workers don't run here and are left to the long-lived server in cmd/http.
 package handler
 
-import "net/http"
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/app"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+)
 
+var (
+	initOnce sync.Once
+	router   http.Handler
+)
+
+// Handler builds the router on the first invocation and reuses it while the function instance stays warm
 func Handler(w http.ResponseWriter, r *http.Request) {
+	initOnce.Do(func() {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			logrus.Error("error when loading config:", err)
+			return
+		}
+		router = app.New(cfg).Router
+	})
 
+	if router == nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	router.ServeHTTP(w, r)
 }
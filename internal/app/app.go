@@ -0,0 +1,196 @@
+// Package app wires the repositories, services and controllers of the service together. The HTTP server
+// (cmd/http) and the serverless handler (deploy) are both built from it.
+package app
+
+import (
+	"context"
+	"github.com/audricimanuel/laundry-routine-tracking-service/docs"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/middleware"
+	authController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/controller"
+	authRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/repository"
+	authService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/service"
+	calendarController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar/controller"
+	calendarRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar/repository"
+	calendarService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign"
+	campaignController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign/controller"
+	campaignRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign/repository"
+	campaignService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign/service"
+	choreController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/controller"
+	choreRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/repository"
+	choreService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/digest"
+	digestRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/digest/repository"
+	digestService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/digest/service"
+	householdController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household/controller"
+	householdRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household/repository"
+	householdService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household/service"
+	laundryController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/controller"
+	laundryRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	laundryService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/service"
+	notificationController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/controller"
+	notificationRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/repository"
+	notificationService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox"
+	outboxRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	outboxService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/service"
+	preferenceController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/preference/controller"
+	preferenceRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/preference/repository"
+	preferenceService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/preference/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder"
+	reminderController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder/controller"
+	reminderRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder/repository"
+	reminderService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series"
+	seriesController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/controller"
+	seriesRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/repository"
+	seriesService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/service"
+	statsController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats/controller"
+	statsRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats/repository"
+	statsService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats/service"
+	vendorController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors/controller"
+	vendorRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors/repository"
+	vendorService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors/service"
+	httpServer "github.com/audricimanuel/laundry-routine-tracking-service/internal/server/http"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/worker"
+	"net/http"
+	"time"
+)
+
+type (
+	// App is the wired service: its HTTP router and the services run by the background workers
+	App struct {
+		cfg    config.Config
+		db     database.DBCollection
+		Router http.Handler
+
+		outboxSvc   outboxService.OutboxService
+		campaignSvc campaignService.CampaignService
+		digestSvc   digestService.DigestService
+		reminderSvc reminderService.ReminderService
+		seriesSvc   seriesService.SeriesService
+	}
+)
+
+func setSwaggerInfo() {
+	docs.SwaggerInfo.Title = "Laundry Tracking API"
+	docs.SwaggerInfo.Description = "Laundry Tracking API"
+	docs.SwaggerInfo.Version = "1.0"
+	docs.SwaggerInfo.BasePath = "/"
+	docs.SwaggerInfo.Schemes = []string{"https", "http"}
+}
+
+// New connects to the database and builds the router, the workers are only started by RunWorkers
+func New(cfg config.Config) *App {
+	// initialize postgres connection
+	databaseCollection := database.NewDatabaseCollection(cfg)
+	a := &App{cfg: cfg, db: databaseCollection}
+
+	// tools
+	unsubscribeTokens := tools.NewUnsubscribeTokens(cfg)
+	smtpClient := tools.NewSMTPClient(cfg, unsubscribeTokens)
+	trackingTokens := tools.NewTrackingTokens(cfg)
+	flashStore := tools.NewFlashStore(cfg)
+	invitationTokens := tools.NewInvitationTokens(cfg)
+
+	// repositories
+	outboxRepo := outboxRepository.NewOutboxRepository(databaseCollection, cfg.Outbox.MaxAttempts)
+	authRepo := authRepository.NewAuthRepository(cfg, databaseCollection, outboxRepo)
+	laundryRepo := laundryRepository.NewLaundryRepository(databaseCollection)
+	campaignRepo := campaignRepository.NewCampaignRepository(databaseCollection, outboxRepo)
+	preferenceRepo := preferenceRepository.NewPreferenceRepository(databaseCollection)
+	digestRepo := digestRepository.NewDigestRepository(databaseCollection, outboxRepo)
+	notificationRepo := notificationRepository.NewNotificationRepository(databaseCollection)
+	reminderRepo := reminderRepository.NewReminderRepository(databaseCollection, outboxRepo, notificationRepo)
+	choreRepo := choreRepository.NewChoreRepository(databaseCollection, notificationRepo)
+	seriesRepo := seriesRepository.NewSeriesRepository(databaseCollection, laundryRepo, choreRepo)
+	calendarRepo := calendarRepository.NewCalendarRepository(databaseCollection)
+	statsRepo := statsRepository.NewStatsRepository(databaseCollection)
+	vendorRepo := vendorRepository.NewVendorRepository(databaseCollection)
+	householdRepo := householdRepository.NewHouseholdRepository(databaseCollection, outboxRepo)
+
+	// services
+	authServ := authService.NewAuthService(cfg, authRepo)
+	laundrySvc := laundryService.NewLaundryService(laundryRepo)
+	a.outboxSvc = outboxService.NewOutboxService(smtpClient, outboxRepo, cfg.Outbox.BatchSize)
+	a.campaignSvc = campaignService.NewCampaignService(campaignRepo, trackingTokens)
+	preferenceSvc := preferenceService.NewPreferenceService(preferenceRepo, unsubscribeTokens)
+	a.digestSvc = digestService.NewDigestService(digestRepo, laundryRepo)
+	notificationSvc := notificationService.NewNotificationService(notificationRepo)
+	a.reminderSvc = reminderService.NewReminderService(reminderRepo)
+	choreSvc := choreService.NewChoreService(choreRepo, householdRepo, seriesRepo, laundrySvc)
+	a.seriesSvc = seriesService.NewSeriesService(seriesRepo, laundrySvc, choreSvc)
+	calendarSvc := calendarService.NewCalendarService(cfg, calendarRepo, laundryRepo, seriesRepo)
+	statsSvc := statsService.NewStatsService(statsRepo, preferenceRepo)
+	vendorSvc := vendorService.NewVendorService(vendorRepo, laundryRepo)
+	householdSvc := householdService.NewHouseholdService(householdRepo, invitationTokens)
+
+	// controllers
+	authCtrl := authController.NewAuthController(cfg, authServ, flashStore)
+	laundryCtrl := laundryController.NewLaundryController(laundrySvc, flashStore)
+	campaignCtrl := campaignController.NewCampaignController(a.campaignSvc)
+	preferenceCtrl := preferenceController.NewPreferenceController(preferenceSvc)
+	reminderCtrl := reminderController.NewReminderController(a.reminderSvc)
+	notificationCtrl := notificationController.NewNotificationController(notificationSvc)
+	seriesCtrl := seriesController.NewSeriesController(a.seriesSvc)
+	calendarCtrl := calendarController.NewCalendarController(calendarSvc, flashStore)
+	statsCtrl := statsController.NewStatsController(statsSvc)
+	vendorCtrl := vendorController.NewVendorController(vendorSvc, flashStore)
+	householdCtrl := householdController.NewHouseholdController(householdSvc, flashStore)
+	choreCtrl := choreController.NewChoreController(choreSvc)
+
+	// set swagger info
+	setSwaggerInfo()
+
+	// registering router
+	a.Router = httpServer.RegisterRouter(
+		cfg,
+
+		// register additional middlewares here
+		middleware.InitMiddleware(cfg, flashStore),
+		middleware.NewAuthMiddleware(cfg, authRepo, flashStore),
+
+		// register controllers in here
+		authCtrl,
+		laundryCtrl,
+		campaignCtrl,
+		preferenceCtrl,
+		reminderCtrl,
+		notificationCtrl,
+		seriesCtrl,
+		calendarCtrl,
+		statsCtrl,
+		vendorCtrl,
+		householdCtrl,
+		choreCtrl,
+	)
+
+	return a
+}
+
+// RunWorkers starts the background jobs of a long-lived process, they run until ctx is cancelled
+func (a *App) RunWorkers(ctx context.Context) *worker.Runner {
+	runner := worker.NewRunner()
+	runner.Every(ctx, "email-outbox", outboxPollInterval(a.cfg), a.outboxSvc.DeliverPending)
+	runner.Every(ctx, "campaign-scheduler", campaign.DISPATCH_INTERVAL_SECONDS*time.Second, a.campaignSvc.DispatchDueCampaigns)
+	runner.Every(ctx, "weekly-digest", digest.DISPATCH_INTERVAL_MINUTES*time.Minute, a.digestSvc.SendWeeklyDigests)
+	runner.Every(ctx, "reminder-scheduler", reminder.DISPATCH_INTERVAL_SECONDS*time.Second, a.reminderSvc.FireDueReminders)
+	runner.Every(ctx, "series-generator", series.GENERATE_INTERVAL_MINUTES*time.Minute, a.seriesSvc.GenerateOccurrences)
+
+	return runner
+}
+
+// Close releases the database connections
+func (a *App) Close() {
+	a.db.PostgresDBSqlx.Close()
+}
+
+func outboxPollInterval(cfg config.Config) time.Duration {
+	if cfg.Outbox.PollInterval <= 0 {
+		return outbox.DEFAULT_POLL_INTERVAL_SECONDS * time.Second
+	}
+	return time.Duration(cfg.Outbox.PollInterval) * time.Second
+}
@@ -7,9 +7,13 @@ type (
 		SwaggerPassword       string     `mapstructure:"SWAGGER_PASSWORD"`
 		JWTSecret             string     `mapstructure:"JWT_SECRET"`
 		JWTExpirationDuration float64    `mapstructure:"JWT_EXPIRATION_DURATION"`
+		CookieSecret          string     `mapstructure:"COOKIE_SECRET"`
+		ViewDevMode           bool       `mapstructure:"VIEW_DEV_MODE"`
+		EmailTrackingEnabled  bool       `mapstructure:"EMAIL_TRACKING_ENABLED"`
 		Host                  Host       `mapstructure:",squash"`
 		DataSource            DataSource `mapstructure:",squash"`
 		SMTPConfig            SMTPConfig `mapstructure:",squash"`
+		Outbox                Outbox     `mapstructure:",squash"`
 	}
 
 	Host struct {
@@ -19,6 +23,8 @@ type (
 		ReadTimeout  int    `mapstructure:"HOST_READ_TIMEOUT"`
 		IdleTimeout  int    `mapstructure:"HOST_IDLE_TIMEOUT"`
 		FEBaseUrl    string `mapstructure:"FE_BASE_URL"`
+		// BaseUrl is the public URL of this service, used for the links in emails
+		BaseUrl string `mapstructure:"BASE_URL"`
 	}
 
 	DataSource struct {
@@ -41,5 +47,21 @@ type (
 		Password       string `mapstructure:"SMTP_HOST_PASSWORD"`
 		Port           string `mapstructure:"SMTP_PORT"`
 		CSEmailAddress string `mapstructure:"CS_EMAIL_ADDRESS"`
+		FromName       string `mapstructure:"SMTP_FROM_NAME"`
+		// UseTLS connects with implicit TLS, otherwise the connection is upgraded with STARTTLS when offered
+		UseTLS bool `mapstructure:"SMTP_USE_TLS"`
+		// Timeout bounds a whole SMTP delivery, in seconds
+		Timeout int `mapstructure:"SMTP_TIMEOUT"`
+		// Transport is one of smtp, file, maildir, log or memory
+		Transport string `mapstructure:"MAIL_TRANSPORT"`
+		// FileDir is where the file and maildir transports write the emails to
+		FileDir string `mapstructure:"MAIL_FILE_DIR"`
+	}
+
+	Outbox struct {
+		// PollInterval is in seconds
+		PollInterval int `mapstructure:"OUTBOX_POLL_INTERVAL"`
+		BatchSize    int `mapstructure:"OUTBOX_BATCH_SIZE"`
+		MaxAttempts  int `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
 	}
 )
@@ -13,6 +13,15 @@ func ViperBind() {
 	viper.BindEnv("JWT_SECRET")
 	viper.BindEnv("JWT_EXPIRATION_DURATION")
 
+	// Binding signed cookies
+	viper.BindEnv("COOKIE_SECRET")
+
+	// Binding view
+	viper.BindEnv("VIEW_DEV_MODE")
+
+	// Binding email tracking
+	viper.BindEnv("EMAIL_TRACKING_ENABLED")
+
 	// Binding host
 	viper.BindEnv("HOST_ADDRESS")
 	viper.BindEnv("HOST_PORT")
@@ -20,6 +29,7 @@ func ViperBind() {
 	viper.BindEnv("HOST_READ_TIMEOUT")
 	viper.BindEnv("HOST_IDLE_TIMEOUT")
 	viper.BindEnv("FE_BASE_URL")
+	viper.BindEnv("BASE_URL")
 
 	// Binding Database
 	viper.BindEnv("POSTGRES_DB_HOST")
@@ -36,4 +46,14 @@ func ViperBind() {
 	viper.BindEnv("SMTP_HOST_PASSWORD")
 	viper.BindEnv("SMTP_PORT")
 	viper.BindEnv("CS_EMAIL_ADDRESS")
+	viper.BindEnv("SMTP_FROM_NAME")
+	viper.BindEnv("SMTP_USE_TLS")
+	viper.BindEnv("SMTP_TIMEOUT")
+	viper.BindEnv("MAIL_TRANSPORT")
+	viper.BindEnv("MAIL_FILE_DIR")
+
+	// Binding email outbox
+	viper.BindEnv("OUTBOX_POLL_INTERVAL")
+	viper.BindEnv("OUTBOX_BATCH_SIZE")
+	viper.BindEnv("OUTBOX_MAX_ATTEMPTS")
 }
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+)
+
+// AccessibleBy matches the rows of the user and the ones shared with the household the user is a member of.
+// Only the owners and members of a household can change its rows, writable leaves out the households the user
+// only views. The alias is the table prefix of the columns, e.g. "l."
+func AccessibleBy(alias, userId string, writable bool) squirrel.Sqlizer {
+	households := squirrel.Select("household_id").
+		From("household_members").
+		Where(squirrel.Eq{"user_id": userId, "role": householdRoles(writable)})
+
+	return squirrel.Or{
+		squirrel.Eq{alias + "user_id": userId},
+		squirrel.Expr(alias+"household_id IN (?)", households),
+	}
+}
+
+// AccessibleByColumn is AccessibleBy for a user of the outer query, userColumn holds the user id, e.g. "u.id"
+func AccessibleByColumn(alias, userColumn string, writable bool) squirrel.Sqlizer {
+	households := squirrel.Select("household_id").
+		From("household_members").
+		Where(squirrel.Eq{"role": householdRoles(writable)}).
+		Where("user_id = " + userColumn)
+
+	return squirrel.Or{
+		squirrel.Expr(alias + "user_id = " + userColumn),
+		squirrel.Expr(alias+"household_id IN (?)", households),
+	}
+}
+
+// HouseholdOf is the household the new rows of the user are shared with, none when the user only views it
+func HouseholdOf(userId string) squirrel.Sqlizer {
+	households := squirrel.Select("household_id").
+		From("household_members").
+		Where(squirrel.Eq{"user_id": userId, "role": constants.HouseholdEditorRoles()})
+
+	return squirrel.Expr("(?)", households)
+}
+
+func householdRoles(writable bool) []constants.HouseholdRole {
+	if writable {
+		return constants.HouseholdEditorRoles()
+	}
+	return []constants.HouseholdRole{constants.HOUSEHOLD_ROLE_OWNER, constants.HOUSEHOLD_ROLE_MEMBER, constants.HOUSEHOLD_ROLE_VIEWER}
+}
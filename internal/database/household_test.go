@@ -0,0 +1,79 @@
+package database
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"reflect"
+	"testing"
+)
+
+func TestAccessibleBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		alias    string
+		writable bool
+		wantSql  string
+		wantArgs []any
+	}{
+		{
+			name:     "readable",
+			wantSql:  "(user_id = ? OR household_id IN (SELECT household_id FROM household_members WHERE role IN (?,?,?) AND user_id = ?))",
+			wantArgs: []any{"user-1", constants.HOUSEHOLD_ROLE_OWNER, constants.HOUSEHOLD_ROLE_MEMBER, constants.HOUSEHOLD_ROLE_VIEWER, "user-1"},
+		},
+		{
+			name:     "writable",
+			writable: true,
+			wantSql:  "(user_id = ? OR household_id IN (SELECT household_id FROM household_members WHERE role IN (?,?) AND user_id = ?))",
+			wantArgs: []any{"user-1", constants.HOUSEHOLD_ROLE_OWNER, constants.HOUSEHOLD_ROLE_MEMBER, "user-1"},
+		},
+		{
+			name:     "aliased",
+			alias:    "l.",
+			writable: true,
+			wantSql:  "(l.user_id = ? OR l.household_id IN (SELECT household_id FROM household_members WHERE role IN (?,?) AND user_id = ?))",
+			wantArgs: []any{"user-1", constants.HOUSEHOLD_ROLE_OWNER, constants.HOUSEHOLD_ROLE_MEMBER, "user-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := AccessibleBy(tt.alias, "user-1", tt.writable).ToSql()
+			if err != nil {
+				t.Fatalf("ToSql: %v", err)
+			}
+			if sql != tt.wantSql {
+				t.Errorf("sql = %q, want %q", sql, tt.wantSql)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestAccessibleByColumn(t *testing.T) {
+	sql, args, err := AccessibleByColumn("l.", "u.id", false).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %v", err)
+	}
+
+	if want := "(l.user_id = u.id OR l.household_id IN (SELECT household_id FROM household_members WHERE role IN (?,?,?) AND user_id = u.id))"; sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+	if want := []any{constants.HOUSEHOLD_ROLE_OWNER, constants.HOUSEHOLD_ROLE_MEMBER, constants.HOUSEHOLD_ROLE_VIEWER}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestHouseholdOf(t *testing.T) {
+	sql, args, err := HouseholdOf("user-1").ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %v", err)
+	}
+
+	if want := "(SELECT household_id FROM household_members WHERE role IN (?,?) AND user_id = ?)"; sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+	if want := []any{constants.HOUSEHOLD_ROLE_OWNER, constants.HOUSEHOLD_ROLE_MEMBER, "user-1"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
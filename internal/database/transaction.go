@@ -0,0 +1,22 @@
+package database
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+// WithTransaction runs fn inside a transaction, committing when it returns nil and rolling back otherwise
+func WithTransaction(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"strings"
+	"time"
+)
+
+var (
+	monthNames = map[string][]string{
+		LOCALE_EN: {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		LOCALE_ID: {"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+	}
+
+	weekdayNames = map[string][]string{
+		LOCALE_EN: {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		LOCALE_ID: {"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+	}
+
+	// date layouts per locale, "MMM" is replaced with the localized short month name
+	dateLayouts = map[string]string{
+		LOCALE_EN: "MMM 02 2006",
+		LOCALE_ID: "02 MMM 2006",
+	}
+
+	dateTimeLayouts = map[string]string{
+		LOCALE_EN: "MMM 02 2006 15:04",
+		LOCALE_ID: "02 MMM 2006 15.04",
+	}
+)
+
+// FormatDate formats a date the way it's usually written in the locale, e.g. "Jan 02 2025" or "02 Jan 2025"
+func FormatDate(locale string, t time.Time) string {
+	return formatLayout(locale, t, dateLayouts)
+}
+
+// FormatDateTime is FormatDate with the time of day
+func FormatDateTime(locale string, t time.Time) string {
+	return formatLayout(locale, t, dateTimeLayouts)
+}
+
+// MonthName returns the full localized month name
+func MonthName(locale string, month time.Month) string {
+	names, ok := monthNames[locale]
+	if !ok {
+		names = monthNames[DEFAULT_LOCALE]
+	}
+	return names[month-1]
+}
+
+// WeekdayName returns the full localized weekday name
+func WeekdayName(locale string, weekday time.Weekday) string {
+	names, ok := weekdayNames[locale]
+	if !ok {
+		names = weekdayNames[DEFAULT_LOCALE]
+	}
+	return names[weekday]
+}
+
+func formatLayout(locale string, t time.Time, layouts map[string]string) string {
+	layout, ok := layouts[locale]
+	if !ok {
+		locale = DEFAULT_LOCALE
+		layout = layouts[DEFAULT_LOCALE]
+	}
+
+	month := MonthName(locale, t.Month())
+	shortMonth := string([]rune(month)[:3])
+
+	// the month placeholder is swapped out before formatting so time.Format doesn't touch it
+	const placeholder = "\x00"
+	result := t.Format(strings.Replace(layout, "MMM", placeholder, 1))
+	return strings.Replace(result, placeholder, shortMonth, 1)
+}
@@ -0,0 +1,122 @@
+// Package i18n holds the message catalogs of the UI, emails and API errors,
+// and negotiates the locale of a request.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"golang.org/x/text/language"
+	"path"
+	"strings"
+)
+
+const (
+	LOCALE_EN = "en"
+	LOCALE_ID = "id"
+
+	DEFAULT_LOCALE = LOCALE_EN
+)
+
+type (
+	// catalog of a single locale.
+	// Messages are looked up by key, errors by their English message as returned by errorutils.
+	catalog struct {
+		Messages map[string]string `json:"messages"`
+		Errors   map[string]string `json:"errors"`
+	}
+)
+
+var (
+	//go:embed locales/*.json
+	localeFiles embed.FS
+
+	catalogs = map[string]catalog{}
+
+	supportedLocales = []string{LOCALE_EN, LOCALE_ID}
+	matcher          = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+)
+
+func init() {
+	for _, locale := range supportedLocales {
+		content, err := localeFiles.ReadFile(path.Join("locales", locale+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog %s: %s", locale, err))
+		}
+
+		var c catalog
+		if err := json.Unmarshal(content, &c); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %s", locale, err))
+		}
+		catalogs[locale] = c
+	}
+}
+
+// IsSupported reports whether there is a catalog for the locale
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// SupportedLocales returns the locales that have a catalog, the default one first
+func SupportedLocales() []string {
+	return supportedLocales
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header value
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DEFAULT_LOCALE
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DEFAULT_LOCALE
+	}
+
+	return supportedLocales[index]
+}
+
+// Normalize returns the locale if it's supported, or the default locale otherwise
+func Normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if IsSupported(locale) {
+		return locale
+	}
+	return DEFAULT_LOCALE
+}
+
+// LocaleFromContext returns the locale negotiated for the request, see middleware.Localize
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(constants.LOCALE).(string); ok && locale != "" {
+		return locale
+	}
+	return DEFAULT_LOCALE
+}
+
+// T translates a message key, formatting it with args when given.
+// Missing keys fall back to the default locale, then to the key itself.
+func T(locale, key string, args ...any) string {
+	message, ok := catalogs[locale].Messages[key]
+	if !ok {
+		if message, ok = catalogs[DEFAULT_LOCALE].Messages[key]; !ok {
+			message = key
+		}
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// TranslateError translates an error message, returning it unchanged when it has no translation
+func TranslateError(locale, message string) string {
+	if translated, ok := catalogs[locale].Errors[message]; ok {
+		return translated
+	}
+	return message
+}
@@ -0,0 +1,297 @@
+// Package ical writes and reads the iCalendar (RFC 5545) events the laundry routines are exchanged as.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FORMAT_DATE     = "20060102"
+	FORMAT_DATETIME = "20060102T150405"
+
+	STATUS_TENTATIVE = "TENTATIVE"
+	STATUS_CONFIRMED = "CONFIRMED"
+	STATUS_CANCELLED = "CANCELLED"
+
+	// maxLineOctets is the length lines are folded at, excluding the line break
+	maxLineOctets = 75
+	// timezoneYears is how long after the last time written in a timezone its VTIMEZONE describes the offsets,
+	// recurring events go on past their first occurrence
+	timezoneYears = 10
+)
+
+type (
+	Calendar struct {
+		ProdId string
+		Name   string
+		// TimeZone is the default timezone of the calendar (X-WR-TIMEZONE), only read by Parse
+		TimeZone string
+		Events   []Event
+	}
+
+	// Event is a VEVENT. Start is in UTC unless TimeZone is set, then its wall-clock time is written in that
+	// timezone (TZID), which keeps recurring events at the same local time across daylight saving changes.
+	// Every timezone used is described by a VTIMEZONE, an unknown one is written in UTC instead.
+	Event struct {
+		UID         string
+		Stamp       time.Time
+		Start       time.Time
+		AllDay      bool
+		Duration    time.Duration
+		TimeZone    string
+		Summary     string
+		Description string
+		Categories  []string
+		// Status is one of the STATUS_ constants
+		Status string
+		// RRule is the recurrence rule of the master event of a series, e.g. FREQ=WEEKLY;BYDAY=SA
+		RRule   string
+		ExDates []time.Time
+		// RecurrenceId is the original start of the occurrence this event overrides, in TimeZone
+		RecurrenceId *time.Time
+	}
+)
+
+// Encode writes the calendar with CRLF line breaks and folded lines
+func (c Calendar) Encode() []byte {
+	w := &writer{}
+
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", c.ProdId)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", EscapeText(c.Name))
+	}
+
+	for _, zone := range c.timezones() {
+		w.timezone(zone)
+	}
+
+	for _, event := range c.Events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", event.UID)
+		w.line("DTSTAMP", event.Stamp.UTC().Format(FORMAT_DATETIME)+"Z")
+
+		if event.RecurrenceId != nil {
+			w.time("RECURRENCE-ID", *event.RecurrenceId, event.TimeZone, event.AllDay)
+		}
+
+		w.time("DTSTART", event.Start, event.TimeZone, event.AllDay)
+		if event.AllDay {
+			w.time("DTEND", event.Start.AddDate(0, 0, 1), "", true)
+		} else if event.Duration > 0 {
+			w.line("DURATION", formatDuration(event.Duration))
+		}
+
+		if event.RRule != "" {
+			w.line("RRULE", event.RRule)
+		}
+
+		for _, exDate := range event.ExDates {
+			w.time("EXDATE", exDate, event.TimeZone, event.AllDay)
+		}
+
+		w.line("SUMMARY", EscapeText(event.Summary))
+		if event.Description != "" {
+			w.line("DESCRIPTION", EscapeText(event.Description))
+		}
+
+		if len(event.Categories) > 0 {
+			categories := make([]string, 0, len(event.Categories))
+			for _, category := range event.Categories {
+				categories = append(categories, EscapeText(category))
+			}
+			w.line("CATEGORIES", strings.Join(categories, ","))
+		}
+
+		if event.Status != "" {
+			w.line("STATUS", event.Status)
+		}
+
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+
+	return w.buf.Bytes()
+}
+
+// EscapeText escapes a TEXT value
+func EscapeText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// time writes a DATE or DATE-TIME property, in UTC unless a known timezone is given
+func (w *writer) time(name string, value time.Time, timezone string, allDay bool) {
+	if allDay {
+		w.line(name+";VALUE=DATE", value.Format(FORMAT_DATE))
+		return
+	}
+
+	if timezone != "" {
+		if location, err := utils.LoadTimezone(timezone); err == nil {
+			w.line(name+";TZID="+timezone, value.In(location).Format(FORMAT_DATETIME))
+			return
+		}
+	}
+
+	w.line(name, value.UTC().Format(FORMAT_DATETIME)+"Z")
+}
+
+// usedTimezone is a timezone the events are written in, with the range of the times written in it
+type usedTimezone struct {
+	location    *time.Location
+	first, last time.Time
+}
+
+// timezones returns the known timezones the times of the events are written in, by name
+func (c Calendar) timezones() []usedTimezone {
+	used := map[string]*usedTimezone{}
+
+	for _, event := range c.Events {
+		if event.AllDay || event.TimeZone == "" {
+			continue
+		}
+
+		location, err := utils.LoadTimezone(event.TimeZone)
+		if err != nil {
+			continue
+		}
+
+		times := append([]time.Time{event.Start}, event.ExDates...)
+		if event.RecurrenceId != nil {
+			times = append(times, *event.RecurrenceId)
+		}
+
+		for _, t := range times {
+			zone, ok := used[event.TimeZone]
+			if !ok {
+				zone = &usedTimezone{location: location, first: t, last: t}
+				used[event.TimeZone] = zone
+			}
+			if t.Before(zone.first) {
+				zone.first = t
+			}
+			if t.After(zone.last) {
+				zone.last = t
+			}
+		}
+	}
+
+	result := make([]usedTimezone, 0, len(used))
+	for _, zone := range used {
+		result = append(result, *zone)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].location.String() < result[j].location.String()
+	})
+
+	return result
+}
+
+// timezone writes the VTIMEZONE of a timezone: one observance per offset change from the zone in effect at the
+// first time written in it until timezoneYears after the last one, or a single one when the offset never changes
+func (w *writer) timezone(zone usedTimezone) {
+	w.line("BEGIN", "VTIMEZONE")
+	w.line("TZID", zone.location.String())
+
+	t := zone.first.In(zone.location)
+	until := zone.last.AddDate(timezoneYears, 0, 0)
+
+	start, end := t.ZoneBounds()
+	name, offset := t.Zone()
+	if start.IsZero() {
+		start = t
+	}
+	w.observance(t.IsDST(), start.In(time.FixedZone("", offset)), name, offset, offset)
+
+	for !end.IsZero() && end.Before(until) {
+		t = end.In(zone.location)
+		previousOffset := offset
+		name, offset = t.Zone()
+		w.observance(t.IsDST(), t.In(time.FixedZone("", previousOffset)), name, previousOffset, offset)
+
+		_, end = t.ZoneBounds()
+	}
+
+	w.line("END", "VTIMEZONE")
+}
+
+// observance writes a STANDARD or DAYLIGHT component, its start is the wall-clock time before the change
+func (w *writer) observance(isDST bool, start time.Time, name string, offsetFrom, offsetTo int) {
+	kind := "STANDARD"
+	if isDST {
+		kind = "DAYLIGHT"
+	}
+
+	w.line("BEGIN", kind)
+	w.line("DTSTART", start.Format(FORMAT_DATETIME))
+	w.line("TZOFFSETFROM", formatOffset(offsetFrom))
+	w.line("TZOFFSETTO", formatOffset(offsetTo))
+	if name != "" {
+		w.line("TZNAME", EscapeText(name))
+	}
+	w.line("END", kind)
+}
+
+// line writes a content line, folding it at 75 octets without splitting a UTF-8 character
+func (w *writer) line(name, value string) {
+	content := name + ":" + value
+
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+
+		w.buf.WriteString(content[:cut])
+		w.buf.WriteString("\r\n ")
+		content = content[cut:]
+		// continuation lines start with the folding space
+		limit = maxLineOctets - 1
+	}
+
+	w.buf.WriteString(content)
+	w.buf.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// formatOffset writes a UTC offset in seconds as a UTC-OFFSET value, e.g. +0700
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	result := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		result += fmt.Sprintf("%02d", offset%60)
+	}
+	return result
+}
+
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes%60 == 0 {
+		return "PT" + strconv.Itoa(minutes/60) + "H"
+	}
+	return "PT" + strconv.Itoa(minutes) + "M"
+}
@@ -0,0 +1,184 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "Weekly wash", want: "Weekly wash"},
+		{name: "separators", value: "shirts, towels; socks", want: `shirts\, towels\; socks`},
+		{name: "backslash", value: `a\b`, want: `a\\b`},
+		{name: "line breaks", value: "first\r\nsecond\nthird", want: `first\nsecond\nthird`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeText(tt.value); got != tt.want {
+				t.Errorf("EscapeText(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+			// the CRLF line breaks are read back as LF
+			if got, want := UnescapeText(tt.want), strings.ReplaceAll(tt.value, "\r\n", "\n"); got != want {
+				t.Errorf("UnescapeText(%q) = %q, want %q", tt.want, got, want)
+			}
+		})
+	}
+}
+
+func TestWriterLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "short", value: "Weekly wash"},
+		{name: "ascii folded", value: strings.Repeat("a", 200)},
+		{name: "multibyte folded", value: strings.Repeat("cucian 洗濯 ", 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writer{}
+			w.line("SUMMARY", tt.value)
+
+			output := w.buf.String()
+			if !strings.HasSuffix(output, "\r\n") {
+				t.Fatalf("line doesn't end with CRLF: %q", output)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d is %d octets long", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character: %q", i, line)
+				}
+				if i > 0 && line[0] != ' ' {
+					t.Errorf("continuation line %d doesn't start with a space: %q", i, line)
+				}
+			}
+
+			unfolded, err := unfold(strings.NewReader(output))
+			if err != nil {
+				t.Fatalf("unfold: %v", err)
+			}
+			if want := "SUMMARY:" + tt.value; len(unfolded) != 1 || unfolded[0] != want {
+				t.Errorf("unfolded = %q, want %q", unfolded, want)
+			}
+		})
+	}
+}
+
+func TestWriterTime(t *testing.T) {
+	value := time.Date(2024, 3, 9, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		timezone string
+		allDay   bool
+		want     string
+	}{
+		{name: "utc", want: "DTSTART:20240309T020000Z\r\n"},
+		{name: "timezone", timezone: "Asia/Jakarta", want: "DTSTART;TZID=Asia/Jakarta:20240309T090000\r\n"},
+		{name: "unknown timezone", timezone: "Mars/Olympus", want: "DTSTART:20240309T020000Z\r\n"},
+		{name: "all day", timezone: "Asia/Jakarta", allDay: true, want: "DTSTART;VALUE=DATE:20240309\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writer{}
+			w.time("DTSTART", value, tt.timezone, tt.allDay)
+			if got := w.buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTimezones(t *testing.T) {
+	start := time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		events   []Event
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "fixed offset",
+			events:   []Event{{UID: "1", Start: start, TimeZone: "Asia/Jakarta"}},
+			contains: []string{"BEGIN:VTIMEZONE\r\nTZID:Asia/Jakarta\r\n", "TZOFFSETTO:+0700\r\n", "DTSTART;TZID=Asia/Jakarta:20240302T210000\r\n"},
+			excludes: []string{"BEGIN:DAYLIGHT"},
+		},
+		{
+			name:     "daylight saving",
+			events:   []Event{{UID: "1", Start: start, TimeZone: "America/New_York", RRule: "FREQ=WEEKLY"}},
+			contains: []string{"TZID:America/New_York\r\n", "BEGIN:DAYLIGHT\r\nDTSTART:20240310T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\n"},
+		},
+		{
+			name:     "unknown and all day events",
+			events:   []Event{{UID: "1", Start: start, TimeZone: "Mars/Olympus"}, {UID: "2", Start: start, TimeZone: "Asia/Jakarta", AllDay: true}},
+			excludes: []string{"BEGIN:VTIMEZONE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := string(Calendar{ProdId: "-//test//EN", Events: tt.events}.Encode())
+
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("output doesn't contain %q:\n%s", want, output)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, output)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		{offset: 0, want: "+0000"},
+		{offset: 7 * 3600, want: "+0700"},
+		{offset: -5 * 3600, want: "-0500"},
+		{offset: 5*3600 + 45*60, want: "+0545"},
+		{offset: -(3600 + 30*60 + 15), want: "-013015"},
+	}
+
+	for _, tt := range tests {
+		if got := formatOffset(tt.offset); got != tt.want {
+			t.Errorf("formatOffset(%d) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{duration: time.Hour, want: "PT1H"},
+		{duration: 3 * time.Hour, want: "PT3H"},
+		{duration: 90 * time.Minute, want: "PT90M"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDuration(tt.duration); got != tt.want {
+			t.Errorf("formatDuration(%s) = %q, want %q", tt.duration, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("invalid calendar file")
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events of a calendar. Components other than VEVENT are ignored, and so are the properties
+// that aren't mapped to Event. Start is left zero when DTSTART is missing or malformed. Times with an unknown
+// TZID or without a timezone are read in the timezone of the calendar, or UTC when it has none. The timezone a
+// local start time was read in is set as the TimeZone of the event.
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Calendar{}
+	found := false
+
+	// components are nested, only the properties directly in VCALENDAR and VEVENT are read
+	stack := []string{}
+	var event *Event
+	var start *property
+
+	for _, line := range lines {
+		prop, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			stack = append(stack, component)
+			if component == "VCALENDAR" {
+				found = true
+			}
+			if component == "VEVENT" && len(stack) == 2 {
+				event = &Event{}
+				start = nil
+			}
+			continue
+		case "END":
+			if len(stack) == 0 {
+				return nil, ErrInvalidCalendar
+			}
+			if len(stack) == 2 && stack[1] == "VEVENT" && event != nil {
+				if start != nil {
+					event.Start, event.TimeZone, event.AllDay = parseTime(*start, result.TimeZone)
+				}
+				result.Events = append(result.Events, *event)
+				event = nil
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		switch {
+		case len(stack) == 1 && stack[0] == "VCALENDAR":
+			switch prop.name {
+			case "PRODID":
+				result.ProdId = prop.value
+			case "X-WR-CALNAME":
+				result.Name = UnescapeText(prop.value)
+			case "X-WR-TIMEZONE":
+				result.TimeZone = prop.value
+			}
+		case len(stack) == 2 && event != nil:
+			switch prop.name {
+			case "UID":
+				event.UID = prop.value
+			case "SUMMARY":
+				event.Summary = UnescapeText(prop.value)
+			case "DESCRIPTION":
+				event.Description = UnescapeText(prop.value)
+			case "CATEGORIES":
+				for _, category := range splitText(prop.value) {
+					event.Categories = append(event.Categories, UnescapeText(category))
+				}
+			case "STATUS":
+				event.Status = strings.ToUpper(prop.value)
+			case "RRULE":
+				event.RRule = prop.value
+			case "DTSTART":
+				p := prop
+				start = &p
+			}
+		}
+	}
+
+	if !found || len(stack) != 0 {
+		return nil, ErrInvalidCalendar
+	}
+
+	return result, nil
+}
+
+// UnescapeText reverts EscapeText
+func UnescapeText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// unfold joins the folded lines back, a line starting with a space or a tab continues the previous one
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrInvalidCalendar
+	}
+
+	return lines, nil
+}
+
+// parseProperty splits a content line into its name, parameters and value, e.g. DTSTART;TZID=Asia/Jakarta:20240101T090000
+func parseProperty(line string) (property, bool) {
+	prop := property{params: map[string]string{}}
+
+	quoted := false
+	separator := -1
+	for i := 0; i < len(line) && separator < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				separator = i
+			}
+		}
+	}
+	if separator <= 0 {
+		return prop, false
+	}
+
+	prop.value = line[separator+1:]
+
+	parts := strings.Split(line[:separator], ";")
+	prop.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+
+	return prop, true
+}
+
+// parseTime reads a DATE or DATE-TIME value, returning the timezone a local time was read in and whether it's a date
+func parseTime(prop property, defaultTimezone string) (time.Time, string, bool) {
+	value := strings.TrimSpace(prop.value)
+
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(value) == len(FORMAT_DATE) {
+		t, err := time.Parse(FORMAT_DATE, value)
+		if err != nil {
+			return time.Time{}, "", false
+		}
+		return t, "", true
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(FORMAT_DATETIME, strings.TrimSuffix(value, "Z"))
+		if err != nil {
+			return time.Time{}, "", false
+		}
+		return t, "", false
+	}
+
+	location := time.UTC
+	for _, timezone := range []string{prop.params["TZID"], defaultTimezone} {
+		if timezone == "" {
+			continue
+		}
+		if l, err := utils.LoadTimezone(timezone); err == nil {
+			location = l
+			break
+		}
+	}
+
+	t, err := time.ParseInLocation(FORMAT_DATETIME, value, location)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return t.UTC(), location.String(), false
+}
+
+// splitText splits a list value at the commas that aren't escaped
+func splitText(value string) []string {
+	result := []string{}
+	last := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			result = append(result, value[last:i])
+			last = i + 1
+		}
+	}
+	return append(result, value[last:])
+}
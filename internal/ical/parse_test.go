@@ -0,0 +1,251 @@
+package ical
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *Calendar
+		wantErr error
+	}{
+		{
+			name: "utc event",
+			input: "BEGIN:VCALENDAR\r\nPRODID:-//test//EN\r\nX-WR-CALNAME:Laundry\r\nBEGIN:VEVENT\r\nUID:1\r\nDTSTART:20240302T140000Z\r\n" +
+				"SUMMARY:Weekly\\, wash\r\nCATEGORIES:Shirts,Towels\\, big\r\nSTATUS:confirmed\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			want: &Calendar{ProdId: "-//test//EN", Name: "Laundry", Events: []Event{{
+				UID:        "1",
+				Start:      time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC),
+				Summary:    "Weekly, wash",
+				Categories: []string{"Shirts", "Towels, big"},
+				Status:     STATUS_CONFIRMED,
+			}}},
+		},
+		{
+			name:  "local time in the event timezone",
+			input: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nDTSTART;TZID=Asia/Jakarta:20240302T210000\nEND:VEVENT\nEND:VCALENDAR\n",
+			want: &Calendar{Events: []Event{{
+				UID:      "1",
+				Start:    time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC),
+				TimeZone: "Asia/Jakarta",
+			}}},
+		},
+		{
+			name: "local time in the calendar timezone",
+			input: "BEGIN:VCALENDAR\nX-WR-TIMEZONE:Asia/Jakarta\nBEGIN:VEVENT\nUID:1\nDTSTART;TZID=Mars/Olympus:20240302T210000\n" +
+				"END:VEVENT\nEND:VCALENDAR\n",
+			want: &Calendar{TimeZone: "Asia/Jakarta", Events: []Event{{
+				UID:      "1",
+				Start:    time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC),
+				TimeZone: "Asia/Jakarta",
+			}}},
+		},
+		{
+			name:  "all day event",
+			input: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nDTSTART;VALUE=DATE:20240302\nRRULE:FREQ=WEEKLY;BYDAY=SA\nEND:VEVENT\nEND:VCALENDAR\n",
+			want: &Calendar{Events: []Event{{
+				UID:    "1",
+				Start:  time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+				AllDay: true,
+				RRule:  "FREQ=WEEKLY;BYDAY=SA",
+			}}},
+		},
+		{
+			name: "folded lines and nested components",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Weekly\r\n  wash\r\nBEGIN:VALARM\r\nSUMMARY:Alarm\r\nEND:VALARM\r\n" +
+				"END:VEVENT\r\nEND:VCALENDAR\r\n",
+			want: &Calendar{Events: []Event{{UID: "1", Summary: "Weekly wash"}}},
+		},
+		{
+			name:  "malformed start",
+			input: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nDTSTART:tomorrow\nEND:VEVENT\nEND:VCALENDAR\n",
+			want:  &Calendar{Events: []Event{{UID: "1"}}},
+		},
+		{
+			name:    "no calendar",
+			input:   "BEGIN:VEVENT\nUID:1\nEND:VEVENT\n",
+			wantErr: ErrInvalidCalendar,
+		},
+		{
+			name:    "unterminated calendar",
+			input:   "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nEND:VEVENT\n",
+			wantErr: ErrInvalidCalendar,
+		},
+		{
+			name:    "unbalanced end",
+			input:   "BEGIN:VCALENDAR\nEND:VCALENDAR\nEND:VCALENDAR\n",
+			wantErr: ErrInvalidCalendar,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEncoded(t *testing.T) {
+	events := []Event{
+		{
+			UID:        "timed",
+			Stamp:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Start:      time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC),
+			TimeZone:   "America/New_York",
+			Summary:    strings.Repeat("Weekly wash; shirts, towels ", 5),
+			Categories: []string{"Shirts", "Towels, big"},
+			Status:     STATUS_TENTATIVE,
+		},
+		{
+			UID:    "all-day",
+			Stamp:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Start:  time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+			AllDay: true,
+		},
+	}
+
+	got, err := Parse(strings.NewReader(string(Calendar{ProdId: "-//test//EN", Events: events}.Encode())))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(got.Events) != len(events) {
+		t.Fatalf("got %d events, want %d", len(got.Events), len(events))
+	}
+
+	for i, want := range events {
+		event := got.Events[i]
+		if event.UID != want.UID || !event.Start.Equal(want.Start) || event.AllDay != want.AllDay || event.TimeZone != want.TimeZone {
+			t.Errorf("event %d = %+v, want %+v", i, event, want)
+		}
+		if event.Summary != want.Summary || !reflect.DeepEqual(event.Categories, want.Categories) || event.Status != want.Status {
+			t.Errorf("event %d text = %q %q %q, want %q %q %q", i, event.Summary, event.Categories, event.Status, want.Summary, want.Categories, want.Status)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name            string
+		prop            property
+		defaultTimezone string
+		want            time.Time
+		wantTimezone    string
+		wantAllDay      bool
+	}{
+		{
+			name:       "date",
+			prop:       property{params: map[string]string{"VALUE": "DATE"}, value: "20240302"},
+			want:       time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			wantAllDay: true,
+		},
+		{
+			name:       "date without value type",
+			prop:       property{params: map[string]string{}, value: "20240302"},
+			want:       time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			wantAllDay: true,
+		},
+		{
+			name:            "utc",
+			prop:            property{params: map[string]string{"TZID": "Asia/Jakarta"}, value: "20240302T140000Z"},
+			defaultTimezone: "Asia/Jakarta",
+			want:            time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "tzid",
+			prop:         property{params: map[string]string{"TZID": "Asia/Jakarta"}, value: "20240302T210000"},
+			want:         time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC),
+			wantTimezone: "Asia/Jakarta",
+		},
+		{
+			name:            "default timezone",
+			prop:            property{params: map[string]string{}, value: "20240302T210000"},
+			defaultTimezone: "Asia/Jakarta",
+			want:            time.Date(2024, 3, 2, 14, 0, 0, 0, time.UTC),
+			wantTimezone:    "Asia/Jakarta",
+		},
+		{
+			name:         "floating",
+			prop:         property{params: map[string]string{"TZID": "Local"}, value: "20240302T210000"},
+			want:         time.Date(2024, 3, 2, 21, 0, 0, 0, time.UTC),
+			wantTimezone: "UTC",
+		},
+		{
+			name: "malformed",
+			prop: property{params: map[string]string{}, value: "2024-03-02T21:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, timezone, allDay := parseTime(tt.prop, tt.defaultTimezone)
+			if !got.Equal(tt.want) || timezone != tt.wantTimezone || allDay != tt.wantAllDay {
+				t.Errorf("got %s %q %t, want %s %q %t", got, timezone, allDay, tt.want, tt.wantTimezone, tt.wantAllDay)
+			}
+		})
+	}
+}
+
+func TestParseProperty(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   property
+		wantOk bool
+	}{
+		{
+			line:   "dtstart;tzid=Asia/Jakarta:20240302T210000",
+			want:   property{name: "DTSTART", params: map[string]string{"TZID": "Asia/Jakarta"}, value: "20240302T210000"},
+			wantOk: true,
+		},
+		{
+			line:   `ATTENDEE;CN="Doe: Jane":mailto:jane@example.com`,
+			want:   property{name: "ATTENDEE", params: map[string]string{"CN": "Doe: Jane"}, value: "mailto:jane@example.com"},
+			wantOk: true,
+		},
+		{
+			line: "no separator",
+			want: property{params: map[string]string{}},
+		},
+		{
+			line: ":no name",
+			want: property{params: map[string]string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseProperty(tt.line)
+		if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseProperty(%q) = %+v %t, want %+v %t", tt.line, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "Shirts", want: []string{"Shirts"}},
+		{value: "Shirts,Towels", want: []string{"Shirts", "Towels"}},
+		{value: `Towels\, big,Socks`, want: []string{`Towels\, big`, "Socks"}},
+		{value: "", want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		if got := splitText(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// Package mail renders the emails sent by the service and builds them into MIME messages.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	EmailType string
+
+	// Category decides whether the recipient can opt out of an email, see the CATEGORY_* constants
+	Category string
+
+	// Data is the template data of an email, kept as a map so a message can be stored as JSON
+	Data map[string]any
+
+	Attachment struct {
+		Filename    string `json:"filename"`
+		ContentType string `json:"content_type"`
+		Content     []byte `json:"content"`
+	}
+
+	// Message is an email to be sent. When HTMLBody and TextBody are empty they are
+	// rendered from the templates of Type, in Locale, with Data (see Render).
+	//
+	// Non-transactional messages must carry the UserId of their recipient, it's used for the unsubscribe link.
+	Message struct {
+		Type        EmailType         `json:"type"`
+		Category    Category          `json:"category,omitempty"`
+		UserId      string            `json:"user_id,omitempty"`
+		Locale      string            `json:"locale"`
+		Data        Data              `json:"data,omitempty"`
+		From        string            `json:"from,omitempty"`
+		To          []string          `json:"to"`
+		Cc          []string          `json:"cc,omitempty"`
+		Subject     string            `json:"subject,omitempty"`
+		HTMLBody    string            `json:"html_body,omitempty"`
+		TextBody    string            `json:"text_body,omitempty"`
+		Headers     map[string]string `json:"headers,omitempty"`
+		Attachments []Attachment      `json:"attachments,omitempty"`
+	}
+)
+
+const (
+	EMAIL_TYPE_OTP_SIGNUP    EmailType = "otp_signup"
+	EMAIL_TYPE_WEEKLY_DIGEST EmailType = "weekly_digest"
+	EMAIL_TYPE_REMINDER      EmailType = "reminder"
+	// EMAIL_TYPE_HOUSEHOLD_INVITATION invites someone to join a household, they may not have an account yet
+	EMAIL_TYPE_HOUSEHOLD_INVITATION EmailType = "household_invitation"
+	// EMAIL_TYPE_CAMPAIGN carries the content written by an admin, rendered per recipient beforehand
+	EMAIL_TYPE_CAMPAIGN EmailType = "campaign"
+
+	// CATEGORY_TRANSACTIONAL is mail the user asked for (e.g. OTP), it's always sent. It's the default category.
+	CATEGORY_TRANSACTIONAL Category = "transactional"
+	CATEGORY_REMINDERS     Category = "reminders"
+	CATEGORY_DIGESTS       Category = "digests"
+	CATEGORY_MARKETING     Category = "marketing"
+
+	// lineLength is the maximum length of a base64 encoded line (RFC 2045)
+	lineLength = 76
+)
+
+// OptionalCategories are the categories a user can unsubscribe from
+func OptionalCategories() []Category {
+	return []Category{CATEGORY_REMINDERS, CATEGORY_DIGESTS, CATEGORY_MARKETING}
+}
+
+// IsOptional reports whether the user can unsubscribe from the category
+func (c Category) IsOptional() bool {
+	for _, category := range OptionalCategories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSubscribed is whether users who haven't made a choice receive the category, digests are opt-in
+func (c Category) DefaultSubscribed() bool {
+	return c != CATEGORY_DIGESTS
+}
+
+// Recipients returns every address the message has to be delivered to
+func (m Message) Recipients() []string {
+	return append(append([]string{}, m.To...), m.Cc...)
+}
+
+// Bytes builds the message as multipart/alternative MIME, wrapped in multipart/mixed when it has attachments
+func (m Message) Bytes(now time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+
+	var buf bytes.Buffer
+
+	headers := map[string]string{
+		"From":         sender.String(),
+		"To":           formatAddressList(m.To),
+		"Subject":      mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":         now.Format(time.RFC1123Z),
+		"Message-ID":   generateMessageID(sender.Address),
+		"MIME-Version": "1.0",
+	}
+
+	if len(m.Cc) > 0 {
+		headers["Cc"] = formatAddressList(m.Cc)
+	}
+
+	for key, value := range m.Headers {
+		headers[textproto.CanonicalMIMEHeaderKey(key)] = mime.QEncoding.Encode("utf-8", value)
+	}
+
+	body, contentType, err := m.buildAlternative()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Attachments) > 0 {
+		if body, contentType, err = m.buildMixed(body, contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	headers["Content-Type"] = contentType
+	writeHeaders(&buf, headers)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// buildAlternative writes the text and HTML bodies as multipart/alternative, returning the body and its content type
+func (m Message) buildAlternative() ([]byte, string, error) {
+	var buf bytes.Buffer
+	alternative := multipart.NewWriter(&buf)
+
+	bodies := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=\"utf-8\"", m.TextBody},
+		{"text/html; charset=\"utf-8\"", m.HTMLBody},
+	}
+
+	for _, body := range bodies {
+		if body.content == "" {
+			continue
+		}
+
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		writer := quotedprintable.NewWriter(part)
+		if _, err := writer.Write([]byte(body.content)); err != nil {
+			return nil, "", err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := alternative.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": alternative.Boundary()}), nil
+}
+
+// buildMixed wraps the alternative body and the attachments in multipart/mixed
+func (m Message) buildMixed(alternativeBody []byte, alternativeContentType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	mixed := multipart.NewWriter(&buf)
+
+	part, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {alternativeContentType}})
+	if err != nil {
+		return nil, "", err
+	}
+	part.Write(alternativeBody)
+
+	for _, attachment := range m.Attachments {
+		if err := writeAttachment(mixed, attachment); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mixed.Boundary()}), nil
+}
+
+func writeAttachment(writer *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": attachment.Filename})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	for len(encoded) > lineLength {
+		fmt.Fprintf(part, "%s\r\n", encoded[:lineLength])
+		encoded = encoded[lineLength:]
+	}
+	_, err = fmt.Fprintf(part, "%s\r\n", encoded)
+
+	return err
+}
+
+func writeHeaders(buf *bytes.Buffer, headers map[string]string) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(buf, "%s: %s\r\n", key, headers[key])
+	}
+}
+
+func formatAddressList(addresses []string) string {
+	result := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if parsed, err := mail.ParseAddress(address); err == nil {
+			result = append(result, parsed.String())
+			continue
+		}
+		result = append(result, address)
+	}
+	return strings.Join(result, ", ")
+}
+
+func generateMessageID(senderAddress string) string {
+	domain := "localhost"
+	if _, host, ok := strings.Cut(senderAddress, "@"); ok && host != "" {
+		domain = host
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
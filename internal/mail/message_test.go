@@ -0,0 +1,230 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mimePart is a leaf part of a parsed message, with its transfer encoding undone
+type mimePart struct {
+	contentType string
+	filename    string
+	content     string
+}
+
+// readParts walks the multipart tree of the body, collecting its leaf parts in order
+func readParts(t *testing.T, contentType string, body io.Reader) []mimePart {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("ParseMediaType(%q): %v", contentType, err)
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		content, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		return []mimePart{{contentType: mediaType, content: string(content)}}
+	}
+
+	var result []mimePart
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		// NextPart undoes the quoted-printable encoding of the bodies
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+
+		if part.FileName() != "" {
+			if encoding := part.Header.Get("Content-Transfer-Encoding"); encoding != "base64" {
+				t.Errorf("attachment %s encoded as %q, want base64", part.FileName(), encoding)
+			}
+			content, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+			if err != nil {
+				t.Fatalf("decoding attachment %s: %v", part.FileName(), err)
+			}
+			attachmentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			result = append(result, mimePart{contentType: attachmentType, filename: part.FileName(), content: string(content)})
+			continue
+		}
+
+		result = append(result, readParts(t, part.Header.Get("Content-Type"), part)...)
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	now := time.Date(2024, 3, 2, 9, 30, 0, 0, time.FixedZone("WIB", 7*3600))
+	attachment := bytes.Repeat([]byte("laundry,3\n"), 20)
+
+	tests := []struct {
+		name        string
+		message     Message
+		wantType    string
+		wantParts   []mimePart
+		wantCc      []string
+		wantHeaders map[string]string
+	}{
+		{
+			name: "text and html",
+			message: Message{
+				From:     "Laundry Tracker <cs@example.com>",
+				To:       []string{"jane@example.com"},
+				Subject:  "Your Signup OTP",
+				TextBody: "Your code is 123456",
+				HTMLBody: "<p>Your code is <strong>123456</strong></p>",
+			},
+			wantType: "multipart/alternative",
+			wantParts: []mimePart{
+				{contentType: "text/plain", content: "Your code is 123456"},
+				{contentType: "text/html", content: "<p>Your code is <strong>123456</strong></p>"},
+			},
+		},
+		{
+			name: "non-ascii subject, cc and extra headers",
+			message: Message{
+				From:     "cs@example.com",
+				To:       []string{"Jane Doe <jane@example.com>", "john@example.com"},
+				Cc:       []string{"Budi Santoso <budi@example.com>"},
+				Subject:  "Ringkasan cucian minggu ini ✓ — 洗濯",
+				TextBody: "Halo Jane, cucian Anda sudah selesai dicuci dan siap diambil hari ini. ✓ Terima kasih telah menggunakan layanan kami!",
+				Headers:  map[string]string{"list-unsubscribe": "<https://example.com/unsubscribe?token=abc>"},
+			},
+			wantType: "multipart/alternative",
+			wantParts: []mimePart{
+				{contentType: "text/plain", content: "Halo Jane, cucian Anda sudah selesai dicuci dan siap diambil hari ini. ✓ Terima kasih telah menggunakan layanan kami!"},
+			},
+			wantCc:      []string{"budi@example.com"},
+			wantHeaders: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe?token=abc>"},
+		},
+		{
+			name: "attachment",
+			message: Message{
+				From:        "cs@example.com",
+				To:          []string{"jane@example.com"},
+				Subject:     "Laundry export",
+				TextBody:    "The export is attached.",
+				HTMLBody:    "<p>The export is attached.</p>",
+				Attachments: []Attachment{{Filename: "laundry export.csv", ContentType: "text/csv", Content: attachment}},
+			},
+			wantType: "multipart/mixed",
+			wantParts: []mimePart{
+				{contentType: "text/plain", content: "The export is attached."},
+				{contentType: "text/html", content: "<p>The export is attached.</p>"},
+				{contentType: "text/csv", filename: "laundry export.csv", content: string(attachment)},
+			},
+		},
+	}
+
+	messageId := regexp.MustCompile(`^<\d+\.[0-9a-f]{32}@example\.com>$`)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := tt.message.Bytes(now)
+			if err != nil {
+				t.Fatalf("Bytes: %v", err)
+			}
+
+			parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+
+			subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+			if err != nil || subject != tt.message.Subject {
+				t.Errorf("subject = %q (%v), want %q", subject, err, tt.message.Subject)
+			}
+
+			if date, err := parsed.Header.Date(); err != nil || !date.Equal(now) {
+				t.Errorf("date = %s (%v), want %s", date, err, now)
+			}
+			if id := parsed.Header.Get("Message-Id"); !messageId.MatchString(id) {
+				t.Errorf("Message-ID = %q", id)
+			}
+			if version := parsed.Header.Get("Mime-Version"); version != "1.0" {
+				t.Errorf("MIME-Version = %q", version)
+			}
+
+			from, err := parsed.Header.AddressList("From")
+			if err != nil || len(from) != 1 || from[0].Address != "cs@example.com" {
+				t.Errorf("From = %v (%v)", from, err)
+			}
+			to, err := parsed.Header.AddressList("To")
+			if err != nil || len(to) != len(tt.message.To) {
+				t.Errorf("To = %v (%v), want %d addresses", to, err, len(tt.message.To))
+			}
+
+			if _, ok := parsed.Header["Cc"]; ok != (len(tt.wantCc) > 0) {
+				t.Errorf("Cc header = %q, want %v", parsed.Header.Get("Cc"), tt.wantCc)
+			}
+			if len(tt.wantCc) > 0 {
+				cc, err := parsed.Header.AddressList("Cc")
+				if err != nil || len(cc) != len(tt.wantCc) || cc[0].Address != tt.wantCc[0] {
+					t.Errorf("Cc = %v (%v), want %v", cc, err, tt.wantCc)
+				}
+			}
+
+			for key, want := range tt.wantHeaders {
+				value, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get(key))
+				if err != nil || value != want {
+					t.Errorf("%s = %q (%v), want %q", key, value, err, want)
+				}
+			}
+
+			contentType := parsed.Header.Get("Content-Type")
+			if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != tt.wantType {
+				t.Errorf("content type = %q, want %s", mediaType, tt.wantType)
+			}
+
+			parts := readParts(t, contentType, parsed.Body)
+			if len(parts) != len(tt.wantParts) {
+				t.Fatalf("got %d parts %+v, want %d", len(parts), parts, len(tt.wantParts))
+			}
+			for i, want := range tt.wantParts {
+				if parts[i] != want {
+					t.Errorf("part %d = %+v, want %+v", i, parts[i], want)
+				}
+			}
+
+			for i, line := range strings.Split(string(raw), "\r\n") {
+				if len(line) > 998 {
+					t.Errorf("line %d is %d octets long", i, len(line))
+				}
+			}
+		})
+	}
+}
+
+func TestMessageBytesInvalidSender(t *testing.T) {
+	message := Message{From: "not an address", To: []string{"jane@example.com"}, TextBody: "hello"}
+	if _, err := message.Bytes(time.Now()); err == nil {
+		t.Error("Bytes accepted an invalid sender")
+	}
+}
+
+func TestMessageRecipients(t *testing.T) {
+	message := Message{To: []string{"jane@example.com"}, Cc: []string{"budi@example.com"}}
+
+	recipients := message.Recipients()
+	if strings.Join(recipients, ",") != "jane@example.com,budi@example.com" {
+		t.Errorf("recipients = %v", recipients)
+	}
+
+	recipients[0] = "changed@example.com"
+	if message.To[0] != "jane@example.com" {
+		t.Error("Recipients shares its slice with To")
+	}
+}
@@ -0,0 +1,134 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	htmlTemplate "html/template"
+	"io/fs"
+	"strings"
+	textTemplate "text/template"
+)
+
+const (
+	layoutTemplate = "layout"
+)
+
+type (
+	// templateData is what the email templates are executed with
+	templateData struct {
+		Locale  string
+		AppName string
+		Subject string
+		Data    Data
+	}
+
+	emailTemplates struct {
+		html *htmlTemplate.Template
+		text *textTemplate.Template
+	}
+)
+
+var (
+	//go:embed templates
+	templateFiles embed.FS
+
+	templates = map[EmailType]emailTemplates{}
+)
+
+// placeholderFuncs lets the templates be parsed once, "t" is bound to the message locale on render
+var placeholderFuncs = map[string]any{
+	"t": func(key string, args ...any) string { return key },
+	// raw outputs HTML that has already been rendered and escaped, e.g. the body of a campaign
+	"raw": func(value any) htmlTemplate.HTML { return htmlTemplate.HTML(fmt.Sprint(value)) },
+}
+
+func init() {
+	files, err := fs.Sub(templateFiles, "templates")
+	if err != nil {
+		panic(err)
+	}
+
+	layout := htmlTemplate.Must(htmlTemplate.New("").Funcs(placeholderFuncs).ParseFS(files, "layout.html"))
+
+	htmlFiles, _ := fs.Glob(files, "*.html")
+	for _, file := range htmlFiles {
+		if file == "layout.html" {
+			continue
+		}
+
+		emailType := EmailType(strings.TrimSuffix(file, ".html"))
+		textFile := string(emailType) + ".txt"
+
+		html := htmlTemplate.Must(htmlTemplate.Must(layout.Clone()).ParseFS(files, file))
+		text := textTemplate.Must(textTemplate.New(textFile).Funcs(placeholderFuncs).ParseFS(files, textFile))
+
+		templates[emailType] = emailTemplates{html: html, text: text}
+	}
+}
+
+// HasTemplate reports whether the email type has its HTML and plain-text templates
+func HasTemplate(emailType EmailType) bool {
+	_, ok := templates[emailType]
+	return ok
+}
+
+// Render fills the subject and both bodies of the message from the templates of its type.
+// Fields that are already set are kept, so a message can carry its own content.
+func Render(message Message) (Message, error) {
+	locale := i18n.Normalize(message.Locale)
+	message.Locale = locale
+
+	if message.Subject == "" {
+		message.Subject = i18n.T(locale, fmt.Sprintf("email.%s.subject", message.Type))
+	}
+
+	if message.HTMLBody != "" && message.TextBody != "" {
+		return message, nil
+	}
+
+	tmpl, ok := templates[message.Type]
+	if !ok {
+		return message, fmt.Errorf("email template %s is not found", message.Type)
+	}
+
+	data := templateData{
+		Locale:  locale,
+		AppName: i18n.T(locale, "app.name"),
+		Subject: message.Subject,
+		Data:    message.Data,
+	}
+
+	funcs := map[string]any{
+		"t": func(key string, args ...any) string { return i18n.T(locale, key, args...) },
+	}
+
+	if message.HTMLBody == "" {
+		html, err := tmpl.html.Clone()
+		if err != nil {
+			return message, err
+		}
+
+		var buf bytes.Buffer
+		if err := html.Funcs(funcs).ExecuteTemplate(&buf, layoutTemplate, data); err != nil {
+			return message, fmt.Errorf("error when rendering %s html: %w", message.Type, err)
+		}
+		message.HTMLBody = buf.String()
+	}
+
+	if message.TextBody == "" {
+		text, err := tmpl.text.Clone()
+		if err != nil {
+			return message, err
+		}
+
+		var buf bytes.Buffer
+		if err := text.Funcs(funcs).Execute(&buf, data); err != nil {
+			return message, fmt.Errorf("error when rendering %s text: %w", message.Type, err)
+		}
+		message.TextBody = buf.String()
+	}
+
+	return message, nil
+}
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"bytes"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	message := Message{
+		Type:   EMAIL_TYPE_OTP_SIGNUP,
+		Locale: " ID ",
+		From:   "cs@example.com",
+		To:     []string{"jane@example.com"},
+		Data:   Data{"FullName": "Jane <Doe>", "OTP": "123456", "ExpiresInMinutes": 5},
+	}
+
+	rendered, err := Render(message)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	if rendered.Locale != "id" {
+		t.Errorf("locale = %q, want id", rendered.Locale)
+	}
+	if want := i18n.T("id", "email.otp_signup.subject"); rendered.Subject != want {
+		t.Errorf("subject = %q, want %q", rendered.Subject, want)
+	}
+
+	expiry := i18n.T("id", "email.otp_signup.expiry", 5)
+	for name, body := range map[string]string{"text": rendered.TextBody, "html": rendered.HTMLBody} {
+		if !strings.Contains(body, "123456") || !strings.Contains(body, expiry) {
+			t.Errorf("%s body doesn't carry the OTP and its expiry:\n%s", name, body)
+		}
+	}
+	if !strings.Contains(rendered.TextBody, "Jane <Doe>") {
+		t.Errorf("text body escaped the name:\n%s", rendered.TextBody)
+	}
+	if !strings.Contains(rendered.HTMLBody, "Jane &lt;Doe&gt;") || !strings.Contains(rendered.HTMLBody, "<html") {
+		t.Errorf("html body isn't escaped or misses the layout:\n%s", rendered.HTMLBody)
+	}
+
+	// the rendered message builds into a MIME message that reads back as rendered
+	raw, err := rendered.Bytes(time.Now())
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); err != nil || subject != rendered.Subject {
+		t.Errorf("subject = %q (%v), want %q", subject, err, rendered.Subject)
+	}
+
+	// quoted-printable carries the line breaks as CRLF
+	parts := readParts(t, parsed.Header.Get("Content-Type"), parsed.Body)
+	unfold := strings.NewReplacer("\r\n", "\n").Replace
+	if len(parts) != 2 || unfold(parts[0].content) != rendered.TextBody || unfold(parts[1].content) != rendered.HTMLBody {
+		t.Errorf("parts = %+v, want the rendered text and html bodies", parts)
+	}
+}
+
+func TestRenderKeepsContent(t *testing.T) {
+	message := Message{
+		Type:     EMAIL_TYPE_CAMPAIGN,
+		Locale:   "en",
+		Subject:  "Spring sale",
+		HTMLBody: "<p>Spring sale</p>",
+		TextBody: "Spring sale",
+	}
+
+	rendered, err := Render(message)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if rendered.Subject != message.Subject || rendered.HTMLBody != message.HTMLBody || rendered.TextBody != message.TextBody {
+		t.Errorf("rendered = %+v, want the content of the message", rendered)
+	}
+}
+
+func TestRenderUnknownType(t *testing.T) {
+	if _, err := Render(Message{Type: "unknown", Locale: "en"}); err == nil {
+		t.Error("Render accepted an email type without templates")
+	}
+	if HasTemplate("unknown") || !HasTemplate(EMAIL_TYPE_OTP_SIGNUP) {
+		t.Error("HasTemplate doesn't match the templates")
+	}
+}
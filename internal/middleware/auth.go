@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"github.com/audricimanuel/errorutils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
@@ -17,24 +20,31 @@ import (
 	"time"
 )
 
+// errExpiredToken is returned by validateToken for a well-signed token past its expiry
+var errExpiredToken = errorutils.NewHttpError(http.StatusUnauthorized, "expired token")
+
 type (
 	AuthMiddleware interface {
 		ValidateJWT() gin.HandlerFunc
 		RefreshJWT() gin.HandlerFunc
 		ValidateJWTFromCookie() gin.HandlerFunc
 		ValidateGetLoginPage() gin.HandlerFunc
+		// RequireRole only lets users with one of the roles through, it must come after ValidateJWT
+		RequireRole(roles ...constants.Role) gin.HandlerFunc
 	}
 
 	AuthMiddlewareImpl struct {
-		cfg      config.Config
-		authRepo repository.AuthRepository
+		cfg        config.Config
+		authRepo   repository.AuthRepository
+		flashStore tools.FlashStore
 	}
 )
 
-func NewAuthMiddleware(cfg config.Config, a repository.AuthRepository) AuthMiddleware {
+func NewAuthMiddleware(cfg config.Config, a repository.AuthRepository, f tools.FlashStore) AuthMiddleware {
 	return &AuthMiddlewareImpl{
-		cfg:      cfg,
-		authRepo: a,
+		cfg:        cfg,
+		authRepo:   a,
+		flashStore: f,
 	}
 }
 
@@ -70,6 +80,7 @@ func (a *AuthMiddlewareImpl) ValidateJWT() gin.HandlerFunc {
 
 		c.Set(constants.USER_DATA, *claims)
 		c.Set(constants.USER_TOKEN, tokenString)
+		setUserLocale(c, *claims)
 
 		c.Next()
 	}
@@ -79,7 +90,7 @@ func (a *AuthMiddlewareImpl) ValidateJWTFromCookie() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		cookie, err := c.Request.Cookie(constants.COOKIE_AUTH_TOKEN)
 		if err != nil || cookie == nil || cookie.Value == "" {
-			c.Redirect(http.StatusTemporaryRedirect, "/login")
+			a.redirectToLogin(c, "")
 			return
 		}
 
@@ -88,18 +99,17 @@ func (a *AuthMiddlewareImpl) ValidateJWTFromCookie() gin.HandlerFunc {
 		claims, err := a.validateToken(tokenString)
 		if err != nil {
 			httputils.InvalidateCookie(c, constants.COOKIE_AUTH_TOKEN)
-			c.Redirect(http.StatusTemporaryRedirect, "/login")
-			return
-		}
-
-		if claims.IsExpired() {
-			httputils.InvalidateCookie(c, constants.COOKIE_AUTH_TOKEN)
-			c.Redirect(http.StatusTemporaryRedirect, "/login")
+			if errors.Is(err, errExpiredToken) {
+				a.redirectToLogin(c, "flash.session_expired")
+			} else {
+				a.redirectToLogin(c, "flash.session_invalid")
+			}
 			return
 		}
 
 		c.Set(constants.USER_DATA, *claims)
 		c.Set(constants.USER_TOKEN, tokenString)
+		setUserLocale(c, *claims)
 
 		c.Next()
 	}
@@ -173,6 +183,50 @@ func (a *AuthMiddlewareImpl) ValidateGetLoginPage() gin.HandlerFunc {
 	}
 }
 
+func (a *AuthMiddlewareImpl) RequireRole(roles ...constants.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userDataCtx, ok := c.Get(constants.USER_DATA)
+		if !ok {
+			httputils.SetHttpResponse(c, nil, errorutils.ErrorUnauthorized, nil)
+			c.Abort()
+			return
+		}
+
+		userData := userDataCtx.(model.UserClaims)
+		for _, role := range roles {
+			if constants.Role(userData.Role) == role {
+				c.Next()
+				return
+			}
+		}
+
+		httputils.SetHttpResponse(c, nil, errorutils.ErrorForbidden, nil)
+		c.Abort()
+	}
+}
+
+// redirectToLogin sends the browser to the login page, optionally leaving a flash message to be shown there
+func (a *AuthMiddlewareImpl) redirectToLogin(c *gin.Context, messageKey string) {
+	if messageKey != "" {
+		a.flashStore.Add(c, tools.FLASH_LEVEL_WARNING, i18n.T(i18n.LocaleFromContext(c), messageKey))
+	}
+
+	// scripted requests can't show the login page, let the script navigate there so the flash message is kept
+	if c.GetHeader(constants.HEADER_REQUESTED_WITH) != "" {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// only GET can be replayed on the login page, other methods must be turned into a GET
+	statusCode := http.StatusTemporaryRedirect
+	if c.Request.Method != http.MethodGet {
+		statusCode = http.StatusSeeOther
+	}
+
+	c.Redirect(statusCode, "/login")
+	c.Abort()
+}
+
 func (a *AuthMiddlewareImpl) validateToken(jwtString string) (*model.UserClaims, error) {
 	token, err := jwt.ParseWithClaims(jwtString, &model.UserClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -180,6 +234,9 @@ func (a *AuthMiddlewareImpl) validateToken(jwtString string) (*model.UserClaims,
 		}
 		return []byte(a.cfg.JWTSecret), nil
 	})
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, errExpiredToken
+	}
 	if err != nil {
 		return nil, errorutils.ErrorInvalidToken
 	}
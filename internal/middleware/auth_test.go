@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func signedToken(t *testing.T, secret string, expiresAt time.Time) string {
+	t.Helper()
+
+	claims := model.UserClaims{
+		UserId: "user-1",
+		Email:  "user@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(expiresAt.Add(-time.Hour)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return token
+}
+
+// poppedFlashes reads back the flash messages the response queued for the next page
+func poppedFlashes(flashStore tools.FlashStore, response *http.Response) []httputils.FlashMessage {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/login", nil)
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == constants.COOKIE_FLASH {
+			ctx.Request.AddCookie(cookie)
+		}
+	}
+	return flashStore.Pop(ctx)
+}
+
+func TestValidateJWTFromCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{JWTSecret: "jwt-secret", CookieSecret: "cookie-secret"}
+	flashStore := tools.NewFlashStore(cfg)
+	now := time.Now()
+
+	warning := func(key string) []httputils.FlashMessage {
+		return []httputils.FlashMessage{{Level: tools.FLASH_LEVEL_WARNING, Message: i18n.T(i18n.DEFAULT_LOCALE, key)}}
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		wantStatus  int
+		wantFlashes []httputils.FlashMessage
+	}{
+		{
+			name:       "valid session",
+			token:      signedToken(t, cfg.JWTSecret, now.Add(time.Hour)),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "expired session",
+			token:       signedToken(t, cfg.JWTSecret, now.Add(-time.Minute)),
+			wantStatus:  http.StatusTemporaryRedirect,
+			wantFlashes: warning("flash.session_expired"),
+		},
+		{
+			name:        "token signed with another secret",
+			token:       signedToken(t, "another-secret", now.Add(time.Hour)),
+			wantStatus:  http.StatusTemporaryRedirect,
+			wantFlashes: warning("flash.session_invalid"),
+		},
+		{
+			name:        "malformed token",
+			token:       "not-a-token",
+			wantStatus:  http.StatusTemporaryRedirect,
+			wantFlashes: warning("flash.session_invalid"),
+		},
+		{
+			name:       "signed out",
+			wantStatus: http.StatusTemporaryRedirect,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/", NewAuthMiddleware(cfg, nil, flashStore).ValidateJWTFromCookie(), func(ctx *gin.Context) {
+				ctx.String(http.StatusOK, ctx.MustGet(constants.USER_DATA).(model.UserClaims).UserId)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				request.AddCookie(&http.Cookie{Name: constants.COOKIE_AUTH_TOKEN, Value: tt.token})
+			}
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if body := recorder.Body.String(); body != "user-1" {
+					t.Errorf("body = %q, want the user id", body)
+				}
+				return
+			}
+
+			if location := recorder.Header().Get("Location"); location != "/login" {
+				t.Errorf("location = %q, want /login", location)
+			}
+			if got := poppedFlashes(flashStore, recorder.Result()); !reflect.DeepEqual(got, tt.wantFlashes) {
+				t.Errorf("flashes = %+v, want %+v", got, tt.wantFlashes)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
 	"github.com/gin-gonic/gin"
 	"io"
 	"log"
@@ -19,10 +20,14 @@ type (
 		LogRequest() gin.HandlerFunc
 		RecoverPanic() gin.HandlerFunc
 		BasicAuth(username, password string) gin.HandlerFunc
+		Localize() gin.HandlerFunc
+		ViewContext() gin.HandlerFunc
+		CSRFProtect() gin.HandlerFunc
 	}
 
 	GoMiddlewareImpl struct {
-		Config config.Config
+		Config     config.Config
+		FlashStore tools.FlashStore
 	}
 )
 
@@ -33,9 +38,10 @@ const (
 	ParamQueryKeyword = "keyword"
 )
 
-func InitMiddleware(cfg config.Config) GoMiddleware {
+func InitMiddleware(cfg config.Config, flashStore tools.FlashStore) GoMiddleware {
 	return &GoMiddlewareImpl{
-		Config: cfg,
+		Config:     cfg,
+		FlashStore: flashStore,
 	}
 }
 
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	localeCookieMaxAge = 365 * 24 * 60 * 60
+)
+
+// Localize negotiates the locale of the request. An explicit choice (?lang= or the lang cookie) wins,
+// then the user's saved locale (see setUserLocale), then the Accept-Language header.
+func (m *GoMiddlewareImpl) Localize() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if locale := ctx.Query(constants.QUERY_LOCALE); locale != "" && i18n.IsSupported(locale) {
+			http.SetCookie(ctx.Writer, &http.Cookie{
+				Name:     constants.COOKIE_LOCALE,
+				Value:    locale,
+				Path:     "/",
+				MaxAge:   localeCookieMaxAge,
+				HttpOnly: true,
+				Secure:   true,
+				SameSite: http.SameSiteLaxMode,
+			})
+			ctx.Set(constants.LOCALE, locale)
+			ctx.Set(constants.LOCALE_EXPLICIT, true)
+			ctx.Next()
+			return
+		}
+
+		if locale, err := ctx.Cookie(constants.COOKIE_LOCALE); err == nil && i18n.IsSupported(locale) {
+			ctx.Set(constants.LOCALE, locale)
+			ctx.Set(constants.LOCALE_EXPLICIT, true)
+			ctx.Next()
+			return
+		}
+
+		ctx.Set(constants.LOCALE, i18n.Negotiate(ctx.GetHeader(constants.HEADER_ACCEPT_LANGUAGE)))
+
+		ctx.Next()
+	}
+}
+
+// setUserLocale switches the request to the locale saved by the authenticated user, unless one was chosen explicitly
+func setUserLocale(ctx *gin.Context, claims model.UserClaims) {
+	if ctx.GetBool(constants.LOCALE_EXPLICIT) || !i18n.IsSupported(claims.Locale) {
+		return
+	}
+	ctx.Set(constants.LOCALE, claims.Locale)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	csrfTokenLength = 32
+)
+
+// ViewContext stores the data shared by every HTML page, to be picked up by httputils.SetHtmlResponse
+func (m *GoMiddlewareImpl) ViewContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(constants.VIEW_ENV, m.Config.Env)
+
+		// the flashes are only popped when a full page is rendered
+		ctx.Set(constants.FLASH_MESSAGES, httputils.FlashReader(func() []httputils.FlashMessage {
+			return m.FlashStore.Pop(ctx)
+		}))
+
+		ctx.Next()
+	}
+}
+
+// CSRFProtect implements the double-submit cookie pattern for the cookie-authenticated web routes.
+// Unsafe requests must echo the cookie value in the X-CSRF-Token header or the _csrf form field.
+func (m *GoMiddlewareImpl) CSRFProtect() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie(constants.COOKIE_CSRF_TOKEN)
+		if err != nil || token == "" {
+			token = generateCSRFToken()
+			http.SetCookie(ctx.Writer, &http.Cookie{
+				Name:     constants.COOKIE_CSRF_TOKEN,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   true,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+
+		ctx.Set(constants.CSRF_TOKEN, token)
+
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+
+		submitted := ctx.GetHeader(constants.HEADER_CSRF_TOKEN)
+		if submitted == "" {
+			submitted = ctx.PostForm(constants.FORM_CSRF_TOKEN)
+		}
+
+		if submitted == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "invalid csrf token"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func generateCSRFToken() string {
+	b := make([]byte, csrfTokenLength)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -17,6 +17,11 @@ type (
 		Email           string `json:"email" validate:"required"`
 		Password        string `json:"password" validate:"required"`
 		ConfirmPassword string `json:"confirm_password" validate:"required"`
+		Locale          string `json:"locale"`
+	}
+
+	UserUpdateLocaleRequest struct {
+		Locale string `json:"locale" validate:"required"`
 	}
 
 	UserVerifyEmailRequest struct {
@@ -36,6 +41,7 @@ type (
 		Password   string     `json:"-" db:"password"`
 		Role       int        `json:"role" db:"role"`
 		IsVerified bool       `json:"is_verified" db:"is_verified"`
+		Locale     string     `json:"locale" db:"locale"`
 		IsActive   bool       `json:"-" db:"is_active"`
 		CreatedAt  time.Time  `json:"-" db:"created_at"`
 		UpdatedAt  time.Time  `json:"-" db:"updated_at"`
@@ -44,9 +50,11 @@ type (
 	}
 
 	UserClaims struct {
-		UserId string `json:"user_id"`
-		Email  string `json:"email"`
-		Role   int    `json:"role"`
+		UserId   string `json:"user_id"`
+		Email    string `json:"email"`
+		FullName string `json:"full_name"`
+		Role     int    `json:"role"`
+		Locale   string `json:"locale"`
 		jwt.RegisteredClaims
 	}
 )
@@ -54,9 +62,11 @@ type (
 func (u *UserInfoResponse) ToJWT(cfg config.Config) *jwt.Token {
 	expireDuration := time.Duration(cfg.JWTExpirationDuration) * time.Hour
 	claims := UserClaims{
-		UserId: u.Id,
-		Email:  u.Email,
-		Role:   u.Role,
+		UserId:   u.Id,
+		Email:    u.Email,
+		FullName: u.FullName,
+		Role:     u.Role,
+		Locale:   u.Locale,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expireDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
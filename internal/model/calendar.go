@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	CalendarFeedResponse struct {
+		Url       string    `json:"url"`
+		CreatedAt time.Time `json:"created_at" db:"created_at"`
+	}
+
+	CalendarFeed struct {
+		UserId    string    `db:"user_id"`
+		Token     string    `db:"token"`
+		Locale    string    `db:"locale"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+
+	// CalendarLaundry is a routine exported to the feed, OccurrenceAt is set when it was generated by a series
+	CalendarLaundry struct {
+		Id           string     `db:"id"`
+		Title        string     `db:"title"`
+		LaundryDate  time.Time  `db:"laundry_date"`
+		LaundryTime  *string    `db:"laundry_time"`
+		Timezone     *string    `db:"timezone"`
+		Status       int        `db:"status"`
+		SeriesId     *string    `db:"series_id"`
+		OccurrenceAt *time.Time `db:"occurrence_at"`
+		Items        []LaundryItemResponse
+	}
+
+	CalendarLaundryItem struct {
+		LaundryId string `db:"laundry_id"`
+		LaundryItemResponse
+	}
+
+	CalendarSkippedOccurrence struct {
+		SeriesId     string    `db:"series_id"`
+		OccurrenceAt time.Time `db:"occurrence_at"`
+	}
+)
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	// CampaignRequest creates or replaces a campaign. Subject and both bodies are Go templates
+	// executed with the recipient, e.g. {{ .FullName }}, text_body is generated from html_body when empty.
+	CampaignRequest struct {
+		Name         string  `json:"name" validate:"required"`
+		Subject      string  `json:"subject" validate:"required"`
+		HTMLBody     string  `json:"html_body" validate:"required"`
+		TextBody     *string `json:"text_body"`
+		Audience     string  `json:"audience" validate:"required"`
+		InactiveDays *int    `json:"inactive_days"`
+	}
+
+	ScheduleCampaignRequest struct {
+		ScheduledAt time.Time `json:"scheduled_at" validate:"required"`
+	}
+
+	// PreviewCampaignRequest renders the campaign for the given user, or for the first user of the audience
+	PreviewCampaignRequest struct {
+		UserId string `json:"user_id"`
+	}
+)
+
+type (
+	CampaignResponse struct {
+		Id           string  `json:"id" db:"id"`
+		Name         string  `json:"name" db:"name"`
+		Subject      string  `json:"subject" db:"subject"`
+		HTMLBody     string  `json:"html_body" db:"html_body"`
+		TextBody     *string `json:"text_body" db:"text_body"`
+		Audience     string  `json:"audience" db:"audience"`
+		InactiveDays *int    `json:"inactive_days" db:"inactive_days"`
+		Status       string  `json:"status" db:"status"`
+		// FailureReason explains why a failed campaign couldn't be sent
+		FailureReason *string                `json:"failure_reason" db:"failure_reason"`
+		ScheduledAt   *time.Time             `json:"scheduled_at" db:"scheduled_at"`
+		SentAt        *time.Time             `json:"sent_at" db:"sent_at"`
+		CreatedBy     string                 `json:"created_by" db:"created_by"`
+		CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+		UpdatedAt     time.Time              `json:"updated_at" db:"updated_at"`
+		Delivery      *CampaignDeliveryStats `json:"delivery,omitempty"`
+	}
+
+	// CampaignDeliveryStats counts the recipients by the status of their email in the outbox
+	CampaignDeliveryStats struct {
+		TotalRecipients int `json:"total_recipients" db:"total_recipients"`
+		Pending         int `json:"pending" db:"pending"`
+		Sending         int `json:"sending" db:"sending"`
+		Sent            int `json:"sent" db:"sent"`
+		Failed          int `json:"failed" db:"failed"`
+	}
+
+	// CampaignStatsResponse summarizes the campaign per recipient. Opened includes the recipients who clicked
+	// without loading the images, unsubscribed counts the ones who opted out of marketing after receiving it.
+	CampaignStatsResponse struct {
+		Recipients   int     `json:"recipients" db:"recipients"`
+		Delivered    int     `json:"delivered" db:"delivered"`
+		Failed       int     `json:"failed" db:"failed"`
+		Opened       int     `json:"opened" db:"opened"`
+		Clicked      int     `json:"clicked" db:"clicked"`
+		Unsubscribed int     `json:"unsubscribed" db:"unsubscribed"`
+		OpenRate     float64 `json:"open_rate"`
+		ClickRate    float64 `json:"click_rate"`
+		// TrackingEnabled is false when opens and clicks aren't being recorded
+		TrackingEnabled bool `json:"tracking_enabled"`
+	}
+
+	CampaignRecipientResponse struct {
+		UserId    string     `json:"user_id" db:"user_id"`
+		Email     string     `json:"email" db:"email"`
+		Status    string     `json:"status" db:"status"`
+		Attempts  int        `json:"attempts" db:"attempts"`
+		LastError *string    `json:"last_error" db:"last_error"`
+		QueuedAt  time.Time  `json:"queued_at" db:"created_at"`
+		SentAt    *time.Time `json:"sent_at" db:"sent_at"`
+	}
+
+	CampaignAudienceUser struct {
+		Id       string `json:"id" db:"id"`
+		Email    string `json:"email" db:"email"`
+		FullName string `json:"full_name" db:"full_name"`
+		Locale   string `json:"locale" db:"locale"`
+	}
+
+	CampaignPreviewResponse struct {
+		Recipient    CampaignAudienceUser `json:"recipient"`
+		AudienceSize int                  `json:"audience_size"`
+		Subject      string               `json:"subject"`
+		HTMLBody     string               `json:"html_body"`
+		TextBody     string               `json:"text_body"`
+	}
+)
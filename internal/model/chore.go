@@ -0,0 +1,156 @@
+package model
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"time"
+)
+
+type (
+	// ChoreAssignmentRequest gives a turn of the routine to a household member, the step is the whole routine or
+	// one of its parts
+	ChoreAssignmentRequest struct {
+		Step   string `json:"step" validate:"required,oneof=routine washing drying folding"`
+		UserId string `json:"user_id" validate:"required"`
+	}
+
+	ChoreAssignmentResponse struct {
+		LaundryId  string    `json:"laundry_id" db:"laundry_id"`
+		Step       string    `json:"step" db:"step"`
+		StepLabel  string    `json:"step_label"`
+		UserId     string    `json:"user_id" db:"user_id"`
+		FullName   string    `json:"full_name" db:"full_name"`
+		AssignedAt time.Time `json:"assigned_at" db:"updated_at"`
+	}
+
+	// NewChoreAssignment is an assignment to be stored, AssignedBy is nil for the turns given by a rotation
+	NewChoreAssignment struct {
+		LaundryId  string
+		Step       string
+		UserId     string
+		AssignedBy *string
+	}
+
+	// ChoreAssignment is a turn as its assignee is notified of it
+	ChoreAssignment struct {
+		LaundryId    string    `db:"laundry_id"`
+		LaundryTitle string    `db:"laundry_title"`
+		LaundryDate  time.Time `db:"laundry_date"`
+		Step         string    `db:"step"`
+		UserId       string    `db:"user_id"`
+		Locale       string    `db:"locale"`
+	}
+)
+
+type (
+	// ChoreRotationRequest gives the routines generated for the series to the members in turn, in the given order
+	ChoreRotationRequest struct {
+		Step      string   `json:"step" validate:"required,oneof=routine washing drying folding"`
+		MemberIds []string `json:"member_ids" validate:"required,gt=0,dive,required"`
+	}
+
+	ChoreRotationResponse struct {
+		SeriesId  string                `json:"series_id" db:"series_id"`
+		Step      string                `json:"step" db:"step"`
+		StepLabel string                `json:"step_label"`
+		NextTurn  int                   `json:"-" db:"next_turn"`
+		Members   []ChoreRotationMember `json:"members" db:"-"`
+		// NextUserId is the member who gets the next generated routine
+		NextUserId *string `json:"next_user_id" db:"-"`
+	}
+
+	ChoreRotationMember struct {
+		UserId   string `json:"user_id" db:"user_id"`
+		FullName string `json:"full_name" db:"full_name"`
+		Position int    `json:"position" db:"position"`
+	}
+)
+
+type (
+	// ChoreSwapRequest asks another member to take a turn of the user, optionally giving them one of their turns
+	// in exchange
+	ChoreSwapRequest struct {
+		LaundryId         string  `json:"laundry_id" validate:"required"`
+		Step              string  `json:"step" validate:"required,oneof=routine washing drying folding"`
+		UserId            string  `json:"user_id" validate:"required"`
+		ExchangeLaundryId *string `json:"exchange_laundry_id"`
+		ExchangeStep      *string `json:"exchange_step" validate:"omitempty,oneof=routine washing drying folding"`
+	}
+
+	// NewChoreSwap is a swap request to be stored
+	NewChoreSwap struct {
+		LaundryId         string
+		Step              string
+		RequestedBy       string
+		RequestedTo       string
+		ExchangeLaundryId *string
+		ExchangeStep      *string
+	}
+
+	ChoreSwapResponse struct {
+		Id                   string     `json:"id" db:"id"`
+		LaundryId            string     `json:"laundry_id" db:"laundry_id"`
+		LaundryTitle         string     `json:"laundry_title" db:"laundry_title"`
+		LaundryDate          time.Time  `json:"laundry_date" db:"laundry_date"`
+		Step                 string     `json:"step" db:"step"`
+		StepLabel            string     `json:"step_label"`
+		RequestedBy          string     `json:"requested_by" db:"requested_by"`
+		RequestedByName      string     `json:"requested_by_name" db:"requested_by_name"`
+		RequestedByLocale    string     `json:"-" db:"requested_by_locale"`
+		RequestedTo          string     `json:"requested_to" db:"requested_to"`
+		RequestedToName      string     `json:"requested_to_name" db:"requested_to_name"`
+		RequestedToLocale    string     `json:"-" db:"requested_to_locale"`
+		ExchangeLaundryId    *string    `json:"exchange_laundry_id" db:"exchange_laundry_id"`
+		ExchangeLaundryTitle *string    `json:"exchange_laundry_title" db:"exchange_laundry_title"`
+		ExchangeStep         *string    `json:"exchange_step" db:"exchange_step"`
+		ExchangeStepLabel    *string    `json:"exchange_step_label"`
+		Status               string     `json:"status" db:"status"`
+		CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+		RespondedAt          *time.Time `json:"responded_at" db:"responded_at"`
+	}
+)
+
+type (
+	// ChoreWorkloadQueryParam is the range of the workload, From and To are dates (YYYY-MM-DD), both inclusive
+	ChoreWorkloadQueryParam struct {
+		From string
+		To   string
+	}
+
+	// ChoreWorkloadRow counts the turns of a member on a step for the routines of a status
+	ChoreWorkloadRow struct {
+		UserId   string `db:"user_id"`
+		FullName string `db:"full_name"`
+		Step     string `db:"step"`
+		Status   int    `db:"status"`
+		Total    int    `db:"total"`
+	}
+
+	ChoreWorkloadResponse struct {
+		From    string                `json:"from"`
+		To      string                `json:"to"`
+		Members []ChoreMemberWorkload `json:"members"`
+	}
+
+	// ChoreMemberWorkload is how many turns a member has, the cancelled routines are left out
+	ChoreMemberWorkload struct {
+		UserId   string `json:"user_id"`
+		FullName string `json:"full_name"`
+		Total    int    `json:"total"`
+		Done     int    `json:"done"`
+		Pending  int    `json:"pending"`
+		// Steps counts the turns per step
+		Steps map[string]int `json:"steps"`
+	}
+)
+
+func (c *ChoreSwapResponse) FillDisplayFields(locale string) {
+	c.StepLabel = ChoreStepLabel(locale, c.Step)
+	if c.ExchangeStep != nil {
+		label := ChoreStepLabel(locale, *c.ExchangeStep)
+		c.ExchangeStepLabel = &label
+	}
+}
+
+func ChoreStepLabel(locale, step string) string {
+	return i18n.T(locale, "chore_step."+step)
+}
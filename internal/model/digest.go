@@ -0,0 +1,12 @@
+package model
+
+type (
+	// DigestRecipient is a user whose weekly digest is due
+	DigestRecipient struct {
+		Id       string `db:"id"`
+		Email    string `db:"email"`
+		FullName string `db:"full_name"`
+		Locale   string `db:"locale"`
+		Timezone string `db:"timezone"`
+	}
+)
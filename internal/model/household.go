@@ -0,0 +1,92 @@
+package model
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"time"
+)
+
+type (
+	HouseholdRequest struct {
+		Name string `json:"name" validate:"required,max=255"`
+	}
+
+	// HouseholdResponse is the household of the user with its members, the pending invitations are only listed to its owners
+	HouseholdResponse struct {
+		Id          string                        `json:"id" db:"id"`
+		Name        string                        `json:"name" db:"name"`
+		Role        constants.HouseholdRole       `json:"role" db:"role"`
+		Members     []HouseholdMemberResponse     `json:"members" db:"-"`
+		Invitations []HouseholdInvitationResponse `json:"invitations" db:"-"`
+		CreatedAt   time.Time                     `json:"created_at" db:"created_at"`
+	}
+
+	HouseholdMemberResponse struct {
+		UserId   string                  `json:"user_id" db:"user_id"`
+		FullName string                  `json:"full_name" db:"full_name"`
+		Email    string                  `json:"email" db:"email"`
+		Role     constants.HouseholdRole `json:"role" db:"role"`
+		JoinedAt time.Time               `json:"joined_at" db:"created_at"`
+	}
+
+	// HouseholdMembership is the household a user belongs to and the role the user has in it
+	HouseholdMembership struct {
+		HouseholdId string                  `db:"household_id"`
+		Role        constants.HouseholdRole `db:"role"`
+	}
+
+	HouseholdMemberRoleRequest struct {
+		Role string `json:"role" validate:"required,oneof=owner member viewer"`
+	}
+)
+
+type (
+	// HouseholdInvitationRequest invites someone to the household by email, they don't need an account yet
+	HouseholdInvitationRequest struct {
+		Email string `json:"email" validate:"required,email,max=255"`
+		Role  string `json:"role" validate:"required,oneof=owner member viewer"`
+	}
+
+	HouseholdInvitationResponse struct {
+		Id        string                  `json:"id" db:"id"`
+		Email     string                  `json:"email" db:"email"`
+		Role      constants.HouseholdRole `json:"role" db:"role"`
+		ExpiresAt time.Time               `json:"expires_at" db:"expires_at"`
+		CreatedAt time.Time               `json:"created_at" db:"created_at"`
+	}
+
+	// NewHouseholdInvitation is an invitation to be stored
+	NewHouseholdInvitation struct {
+		HouseholdId string
+		Email       string
+		Role        constants.HouseholdRole
+		InvitedBy   string
+		ExpiresAt   time.Time
+	}
+
+	// HouseholdInvitation is an invitation as the invitee sees it, it can be accepted while it's pending
+	HouseholdInvitation struct {
+		Id            string                  `json:"id" db:"id"`
+		HouseholdId   string                  `json:"household_id" db:"household_id"`
+		HouseholdName string                  `json:"household_name" db:"household_name"`
+		Email         string                  `json:"email" db:"email"`
+		Role          constants.HouseholdRole `json:"role" db:"role"`
+		InvitedByName string                  `json:"invited_by_name" db:"invited_by_name"`
+		ExpiresAt     time.Time               `json:"expires_at" db:"expires_at"`
+		AcceptedAt    *time.Time              `json:"accepted_at" db:"accepted_at"`
+		RevokedAt     *time.Time              `json:"revoked_at" db:"revoked_at"`
+	}
+
+	AcceptHouseholdInvitationRequest struct {
+		Token string `json:"token" form:"token" validate:"required"`
+	}
+)
+
+// IsPending reports whether the invitation can still be accepted at the given time
+func (h *HouseholdInvitation) IsPending(now time.Time) bool {
+	return h.AcceptedAt == nil && h.RevokedAt == nil && now.Before(h.ExpiresAt)
+}
+
+func HouseholdRoleLabel(locale string, role constants.HouseholdRole) string {
+	return i18n.T(locale, "household_role."+string(role))
+}
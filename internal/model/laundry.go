@@ -1,6 +1,14 @@
 package model
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"time"
+)
 
 type (
 	LaundryResponse struct {
@@ -9,8 +17,81 @@ type (
 		LaundryDate       time.Time `json:"-" db:"laundry_date"`
 		LaundryDateString string    `json:"laundry_date"`
 		TotalItems        int       `json:"total_items" db:"total_items"`
-		Status            int       `json:"-" db:"status"`
-		StatusLabel       string    `json:"status_label"`
+		// TotalReturnedItems is set once the returned amounts of the items are recorded
+		TotalReturnedItems *int   `json:"total_returned_items" db:"total_returned_items"`
+		HasDiscrepancy     bool   `json:"has_discrepancy" db:"has_discrepancy"`
+		Status             int    `json:"-" db:"status"`
+		StatusLabel        string `json:"status_label"`
+	}
+
+	// LaundryExportRow is an item of a routine in the export, the item fields are empty for a routine without items
+	LaundryExportRow struct {
+		LaundryId    string    `db:"laundry_id"`
+		Title        string    `db:"title"`
+		LaundryDate  time.Time `db:"laundry_date"`
+		Status       int       `db:"status"`
+		CategoryName *string   `db:"category_name"`
+		Amount       *int      `db:"amount"`
+		Notes        *string   `db:"notes"`
+	}
+
+	LaundryDetailResponse struct {
+		LaundryResponse
+		// LaundryTime is the wall-clock time (HH:MM:SS) in Timezone of a routine planned at a time of the day,
+		// both are nil on the routines planned by their date only
+		LaundryTime *string `json:"laundry_time" db:"laundry_time"`
+		Timezone    *string `json:"timezone" db:"timezone"`
+		// ResolutionNote explains a discrepancy between the sent and returned items
+		ResolutionNote *string    `json:"resolution_note" db:"resolution_note"`
+		ResolvedAt     *time.Time `json:"resolved_at" db:"resolved_at"`
+		// HouseholdId is the household the routine is shared with, nil on a personal routine
+		HouseholdId *string `json:"household_id" db:"household_id"`
+		// CanEdit is false on the routines of a household the user only views
+		CanEdit     bool                       `json:"can_edit" db:"can_edit"`
+		Items       []LaundryItemResponse      `json:"items"`
+		StatusLogs  []LaundryStatusLogResponse `json:"status_logs"`
+		NextActions []LaundryStatusAction      `json:"next_actions"`
+		// Vendor is set when the routine was sent to a laundry shop
+		Vendor *LaundryVendorResponse `json:"vendor"`
+		// Assignments are the household members whose turn it is to do the routine or its steps
+		Assignments []ChoreAssignmentResponse `json:"assignments"`
+	}
+
+	LaundryItemResponse struct {
+		Id           string `json:"id" db:"id"`
+		CategoryId   string `json:"category_id" db:"category_id"`
+		CategoryName string `json:"category_name" db:"category_name"`
+		Amount       int    `json:"amount" db:"amount"`
+		// ReturnedAmount is how many came back, nil until the returns are recorded
+		ReturnedAmount *int    `json:"returned_amount" db:"returned_amount"`
+		Notes          *string `json:"notes" db:"notes"`
+	}
+
+	LaundryStatusLogResponse struct {
+		Status          int       `json:"-" db:"status"`
+		StatusLabel     string    `json:"status_label"`
+		CreatedAt       time.Time `json:"-" db:"created_at"`
+		CreatedAtString string    `json:"created_at"`
+	}
+
+	LaundryStatusAction struct {
+		Status int    `json:"status"`
+		Label  string `json:"label"`
+	}
+
+	// LaundryWeeklySummary is what the weekly digest tells about the routines of a user
+	LaundryWeeklySummary struct {
+		// Completed is the routines marked as done during the week
+		Completed int `db:"completed"`
+		// Pending is the routines that are planned or still in progress
+		Pending    int                     `db:"pending"`
+		Categories []LaundryCategoryAmount `db:"-"`
+		Upcoming   []LaundryResponse       `db:"-"`
+	}
+
+	LaundryCategoryAmount struct {
+		CategoryName string `json:"category_name" db:"category_name"`
+		Amount       int    `json:"amount" db:"amount"`
 	}
 
 	LaundryQueryParam struct {
@@ -34,6 +115,22 @@ type (
 	}
 )
 
+type (
+	LaundryTemplateRequest struct {
+		Name  string                `json:"name" validate:"required,max=255"`
+		Items []LaundryItemsRequest `json:"items" validate:"required,gt=1,dive"`
+	}
+
+	// LaundryTemplateResponse is a saved item list a routine can be created from
+	LaundryTemplateResponse struct {
+		Id        string       `json:"id" db:"id"`
+		Name      string       `json:"name" db:"name"`
+		Items     LaundryItems `json:"items" db:"items"`
+		CreatedAt time.Time    `json:"created_at" db:"created_at"`
+		UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+	}
+)
+
 type (
 	AddLaundryRequest struct {
 		Title       string                `json:"title" validate:"required"`
@@ -41,9 +138,164 @@ type (
 		Items       []LaundryItemsRequest `json:"items" validate:"required,gt=1"`
 	}
 
+	// LaundryItems is an item list stored as JSON, e.g. the items of a template or a recurring routine
+	LaundryItems []LaundryItemsRequest
+
 	LaundryItemsRequest struct {
 		CategoryId string  `json:"category_id" validate:"required"`
 		Amount     int     `json:"amount" validate:"required,min=1"`
 		Notes      *string `json:"notes"`
 	}
+
+	// CopyLaundryRequest creates a routine from a template or another routine, the title defaults to
+	// the template name or the title of the routine
+	CopyLaundryRequest struct {
+		LaundryDate string  `json:"laundry_date" validate:"required,date_format"`
+		Title       *string `json:"title"`
+	}
+
+	// NewLaundry is a routine to be stored, SeriesId and OccurrenceAt are set on the occurrences of a recurring routine.
+	// LaundryDate is the local date at midnight, LaundryTime and Timezone are set on the routines planned at a time
+	// of the day (see SplitPlannedAt).
+	NewLaundry struct {
+		Title        string
+		LaundryDate  time.Time
+		LaundryTime  *string
+		Timezone     *string
+		Items        []LaundryItemsRequest
+		SeriesId     *string
+		OccurrenceAt *time.Time
+	}
+
+	UpdateLaundryStatusRequest struct {
+		Status *int `json:"status" form:"status" validate:"required"`
+	}
 )
+
+type (
+	// LaundryReturnsRequest records how many of every item of a routine came back
+	LaundryReturnsRequest struct {
+		Items []LaundryReturnedItemRequest `json:"items" validate:"required,gt=0,dive"`
+	}
+
+	LaundryReturnedItemRequest struct {
+		ItemId         string `json:"item_id" validate:"required"`
+		ReturnedAmount *int   `json:"returned_amount" validate:"required,min=0"`
+	}
+
+	LaundryResolutionRequest struct {
+		Note string `json:"note" validate:"required,max=1000"`
+	}
+
+	// MissingItemResponse is an item that came back with less than what was sent
+	MissingItemResponse struct {
+		LaundryId         string     `json:"laundry_id" db:"laundry_id"`
+		Title             string     `json:"title" db:"title"`
+		LaundryDate       time.Time  `json:"-" db:"laundry_date"`
+		LaundryDateString string     `json:"laundry_date"`
+		ItemId            string     `json:"item_id" db:"item_id"`
+		CategoryId        string     `json:"category_id" db:"category_id"`
+		CategoryName      string     `json:"category_name" db:"category_name"`
+		Amount            int        `json:"amount" db:"amount"`
+		ReturnedAmount    int        `json:"returned_amount" db:"returned_amount"`
+		Missing           int        `json:"missing" db:"missing"`
+		ResolutionNote    *string    `json:"resolution_note" db:"resolution_note"`
+		ResolvedAt        *time.Time `json:"resolved_at" db:"resolved_at"`
+	}
+
+	// MissingItemsReport is the missing items across the routines with their totals per category
+	MissingItemsReport struct {
+		Items        []MissingItemResponse   `json:"items"`
+		Categories   []LaundryCategoryAmount `json:"categories"`
+		TotalMissing int                     `json:"total_missing"`
+	}
+)
+
+// FillDisplayFields sets the label fields that are derived from the stored values, in the given locale
+func (l *LaundryResponse) FillDisplayFields(locale string) {
+	l.LaundryDateString = i18n.FormatDate(locale, l.LaundryDate)
+	l.StatusLabel = LaundryStatusLabel(locale, constants.LaundryStatus(l.Status))
+}
+
+func (l *LaundryDetailResponse) FillDisplayFields(locale string) {
+	l.LaundryResponse.FillDisplayFields(locale)
+
+	for i := range l.StatusLogs {
+		l.StatusLogs[i].StatusLabel = LaundryStatusLabel(locale, constants.LaundryStatus(l.StatusLogs[i].Status))
+		l.StatusLogs[i].CreatedAtString = i18n.FormatDateTime(locale, l.StatusLogs[i].CreatedAt)
+	}
+
+	if l.Vendor != nil {
+		l.Vendor.FillDisplayFields(locale)
+	}
+
+	for i := range l.Assignments {
+		l.Assignments[i].StepLabel = ChoreStepLabel(locale, l.Assignments[i].Step)
+	}
+
+	l.NextActions = []LaundryStatusAction{}
+	if !l.CanEdit {
+		return
+	}
+
+	for _, next := range constants.LaundryStatus(l.Status).NextStatuses() {
+		l.NextActions = append(l.NextActions, LaundryStatusAction{Status: int(next), Label: LaundryStatusLabel(locale, next)})
+	}
+}
+
+// SplitPlannedAt stores a routine planned at the given time: the laundry date is its local date in the location
+func SplitPlannedAt(plannedAt time.Time, location *time.Location) (laundryDate time.Time, laundryTime, timezone *string) {
+	local := plannedAt.In(location)
+	clock := local.Format(constants.FORMAT_TIME_DEFAULT)
+	name := location.String()
+
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC), &clock, &name
+}
+
+// LaundryPlannedAt is the time a routine is planned at, false when it's planned by its date only
+func LaundryPlannedAt(laundryDate time.Time, laundryTime, timezone *string) (time.Time, bool) {
+	if laundryTime == nil || timezone == nil {
+		return time.Time{}, false
+	}
+
+	location, err := utils.LoadTimezone(*timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	clock, err := time.Parse(constants.FORMAT_TIME_DEFAULT, *laundryTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Date(laundryDate.Year(), laundryDate.Month(), laundryDate.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, location), true
+}
+
+// HasDiscrepancy tells whether the item came back with a different amount than what was sent
+func (l LaundryItemResponse) HasDiscrepancy() bool {
+	return l.ReturnedAmount != nil && *l.ReturnedAmount != l.Amount
+}
+
+func LaundryStatusLabel(locale string, status constants.LaundryStatus) string {
+	return i18n.T(locale, "laundry_status."+status.Key())
+}
+
+func (l LaundryItems) Value() (driver.Value, error) {
+	if l == nil {
+		l = LaundryItems{}
+	}
+	return json.Marshal(l)
+}
+
+func (l *LaundryItems) Scan(src any) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, l)
+	case string:
+		return json.Unmarshal([]byte(value), l)
+	case nil:
+		*l = LaundryItems{}
+		return nil
+	}
+	return errors.New("unsupported type of laundry items")
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	// LaundryImportRequest is the mapping of an import, sent as form fields along with the file
+	LaundryImportRequest struct {
+		// TemplateId gives the items of the created routines, they're created without items when it's empty
+		TemplateId string `form:"template_id"`
+		// IncludeDuplicates also creates the rows matching a routine that already exists
+		IncludeDuplicates bool `form:"include_duplicates"`
+	}
+
+	// LaundryImportRow is a routine read from an imported file. Line is its position in the file, starting at 1.
+	LaundryImportRow struct {
+		Line        int        `json:"line"`
+		Title       string     `json:"title"`
+		LaundryDate *time.Time `json:"laundry_date"`
+		// Recurring is set on events with a recurrence rule, only their first occurrence is imported
+		Recurring bool `json:"recurring"`
+		// Duplicate means a routine with the same title exists on the same date, in the user's data or earlier in the file
+		Duplicate bool `json:"duplicate"`
+		// Error tells why the row can't be imported
+		Error *string `json:"error"`
+	}
+
+	LaundryImportPreview struct {
+		Rows       []LaundryImportRow `json:"rows"`
+		Total      int                `json:"total"`
+		Valid      int                `json:"valid"`
+		Duplicates int                `json:"duplicates"`
+		Invalid    int                `json:"invalid"`
+	}
+
+	LaundryImportResult struct {
+		Ids               []string `json:"ids"`
+		Created           int      `json:"created"`
+		SkippedDuplicates int      `json:"skipped_duplicates"`
+		SkippedInvalid    int      `json:"skipped_invalid"`
+	}
+)
+
+type (
+	// LaundryCSVImportRequest maps the columns of a CSV file, by their header, to the routine fields.
+	// Every line is an item, the lines with the same title and date make up one routine.
+	LaundryCSVImportRequest struct {
+		TitleColumn    string `form:"title_column"`
+		DateColumn     string `form:"date_column"`
+		CategoryColumn string `form:"category_column"`
+		AmountColumn   string `form:"amount_column"`
+		NotesColumn    string `form:"notes_column"`
+		// DateFormat is one of YYYY-MM-DD, DD/MM/YYYY, MM/DD/YYYY or DD-MM-YYYY
+		DateFormat string `form:"date_format"`
+		// Delimiter is a comma, a semicolon or "tab"
+		Delimiter string `form:"delimiter"`
+		// DryRun validates the file and reports what would be imported without storing anything
+		DryRun bool `form:"dry_run"`
+	}
+
+	// LaundryCSVImportRow is a line of the file, Line counts the header as line 1
+	LaundryCSVImportRow struct {
+		Line         int        `json:"line"`
+		Title        string     `json:"title"`
+		LaundryDate  *time.Time `json:"laundry_date"`
+		CategoryName string     `json:"category_name"`
+		Amount       int        `json:"amount"`
+		Notes        *string    `json:"notes"`
+		// Result is created, skipped when its routine already exists, or failed
+		Result string  `json:"result"`
+		Error  *string `json:"error"`
+	}
+
+	LaundryCSVImportResult struct {
+		DryRun bool                  `json:"dry_run"`
+		Rows   []LaundryCSVImportRow `json:"rows"`
+		// Created, Skipped and Failed count the rows
+		Created         int `json:"created"`
+		Skipped         int `json:"skipped"`
+		Failed          int `json:"failed"`
+		RoutinesCreated int `json:"routines_created"`
+		// CategoriesCreated are the category names that didn't exist yet
+		CategoriesCreated []string `json:"categories_created"`
+		Ids               []string `json:"ids"`
+	}
+)
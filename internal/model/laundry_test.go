@@ -0,0 +1,98 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitPlannedAt(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name         string
+		plannedAt    time.Time
+		location     *time.Location
+		wantDate     time.Time
+		wantTime     string
+		wantTimezone string
+	}{
+		{
+			name:         "same day",
+			plannedAt:    time.Date(2024, 3, 2, 2, 0, 0, 0, time.UTC),
+			location:     jakarta,
+			wantDate:     time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			wantTime:     "09:00:00",
+			wantTimezone: "Asia/Jakarta",
+		},
+		{
+			name:         "next local day",
+			plannedAt:    time.Date(2024, 3, 2, 20, 30, 0, 0, time.UTC),
+			location:     jakarta,
+			wantDate:     time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+			wantTime:     "03:30:00",
+			wantTimezone: "Asia/Jakarta",
+		},
+		{
+			name:         "previous local day",
+			plannedAt:    time.Date(2024, 3, 2, 3, 0, 0, 0, time.UTC),
+			location:     newYork,
+			wantDate:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantTime:     "22:00:00",
+			wantTimezone: "America/New_York",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			laundryDate, laundryTime, timezone := SplitPlannedAt(tt.plannedAt, tt.location)
+			if !laundryDate.Equal(tt.wantDate) || laundryDate.Location() != time.UTC {
+				t.Errorf("date = %s, want %s", laundryDate, tt.wantDate)
+			}
+			if *laundryTime != tt.wantTime || *timezone != tt.wantTimezone {
+				t.Errorf("time = %s %s, want %s %s", *laundryTime, *timezone, tt.wantTime, tt.wantTimezone)
+			}
+
+			plannedAt, ok := LaundryPlannedAt(laundryDate, laundryTime, timezone)
+			if !ok || !plannedAt.Equal(tt.plannedAt) {
+				t.Errorf("LaundryPlannedAt = %s %t, want %s", plannedAt, ok, tt.plannedAt)
+			}
+		})
+	}
+}
+
+func TestLaundryPlannedAt(t *testing.T) {
+	laundryDate := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	text := func(value string) *string {
+		return &value
+	}
+
+	tests := []struct {
+		name        string
+		laundryTime *string
+		timezone    *string
+		want        time.Time
+		wantOk      bool
+	}{
+		{
+			name:        "planned at a time",
+			laundryTime: text("09:00:00"),
+			timezone:    text("Asia/Jakarta"),
+			want:        time.Date(2024, 3, 2, 2, 0, 0, 0, time.UTC),
+			wantOk:      true,
+		},
+		{name: "date only"},
+		{name: "no timezone", laundryTime: text("09:00:00")},
+		{name: "unknown timezone", laundryTime: text("09:00:00"), timezone: text("Mars/Olympus")},
+		{name: "malformed time", laundryTime: text("9am"), timezone: text("Asia/Jakarta")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LaundryPlannedAt(laundryDate, tt.laundryTime, tt.timezone)
+			if ok != tt.wantOk || !got.Equal(tt.want) {
+				t.Errorf("got %s %t, want %s %t", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
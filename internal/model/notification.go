@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	// Notification is an in-app notification to be created, SourceKey identifies the event it's created for
+	Notification struct {
+		UserId    string
+		Type      string
+		Title     string
+		Body      string
+		LaundryId *string
+		SourceKey string
+	}
+
+	NotificationResponse struct {
+		Id        string     `json:"id" db:"id"`
+		Type      string     `json:"type" db:"type"`
+		Title     string     `json:"title" db:"title"`
+		Body      string     `json:"body" db:"body"`
+		LaundryId *string    `json:"laundry_id" db:"laundry_id"`
+		ReadAt    *time.Time `json:"read_at" db:"read_at"`
+		CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	}
+
+	UnreadNotificationsResponse struct {
+		Unread int `json:"unread"`
+	}
+)
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	OutboxEmail struct {
+		Id             int64      `db:"id"`
+		IdempotencyKey string     `db:"idempotency_key"`
+		EmailType      string     `db:"email_type"`
+		Payload        []byte     `db:"payload"`
+		Status         string     `db:"status"`
+		Attempts       int        `db:"attempts"`
+		MaxAttempts    int        `db:"max_attempts"`
+		NextAttemptAt  time.Time  `db:"next_attempt_at"`
+		LastError      *string    `db:"last_error"`
+		CreatedAt      time.Time  `db:"created_at"`
+		SentAt         *time.Time `db:"sent_at"`
+	}
+)
@@ -0,0 +1,25 @@
+package model
+
+type (
+	// UpdateEmailPreferencesRequest maps the email categories to whether the user wants to receive them
+	UpdateEmailPreferencesRequest struct {
+		Preferences map[string]bool `json:"preferences" validate:"required"`
+	}
+
+	// UpdateTimezoneRequest sets the IANA timezone the emails of the user are scheduled in, e.g. Asia/Jakarta
+	UpdateTimezoneRequest struct {
+		Timezone string `json:"timezone" validate:"required"`
+	}
+)
+
+type (
+	EmailPreferenceResponse struct {
+		Category   string `json:"category"`
+		Label      string `json:"label"`
+		Subscribed bool   `json:"subscribed"`
+	}
+
+	TimezoneResponse struct {
+		Timezone string `json:"timezone"`
+	}
+)
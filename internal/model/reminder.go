@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	// ReminderRequest schedules a reminder either at remind_at or remind_in_minutes from now, exactly one of them is required
+	ReminderRequest struct {
+		Message         string     `json:"message" validate:"required,max=255"`
+		RemindAt        *time.Time `json:"remind_at"`
+		RemindInMinutes *int       `json:"remind_in_minutes" validate:"omitempty,min=1"`
+	}
+)
+
+type (
+	ReminderResponse struct {
+		Id        string     `json:"id" db:"id"`
+		LaundryId string     `json:"laundry_id" db:"laundry_id"`
+		Message   string     `json:"message" db:"message"`
+		RemindAt  time.Time  `json:"remind_at" db:"remind_at"`
+		Status    string     `json:"status" db:"status"`
+		FiredAt   *time.Time `json:"fired_at" db:"fired_at"`
+		CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	}
+
+	// DueReminder is a reminder being fired with what its notification and email are built from
+	DueReminder struct {
+		Id           string    `db:"id"`
+		LaundryId    string    `db:"laundry_id"`
+		LaundryTitle string    `db:"laundry_title"`
+		Message      string    `db:"message"`
+		RemindAt     time.Time `db:"remind_at"`
+		UserId       string    `db:"user_id"`
+		Email        string    `db:"email"`
+		FullName     string    `db:"full_name"`
+		Locale       string    `db:"locale"`
+		Timezone     string    `db:"timezone"`
+		// EmailSubscribed is false when the user opted out of reminder emails, only the notification is created
+		EmailSubscribed bool `db:"email_subscribed"`
+	}
+)
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	// SeriesRequest defines a recurring routine. RRule is an RFC 5545 recurrence rule, e.g. FREQ=WEEKLY;BYDAY=SA,
+	// starting at starts_at (YYYY-MM-DD hh:mm:ss) in timezone. Items is the item template of every occurrence.
+	SeriesRequest struct {
+		Title    string                `json:"title" validate:"required"`
+		RRule    string                `json:"rrule" validate:"required"`
+		StartsAt string                `json:"starts_at" validate:"required,datetime_format"`
+		Timezone string                `json:"timezone" validate:"required"`
+		Items    []LaundryItemsRequest `json:"items" validate:"required,gt=0,dive"`
+	}
+
+	// OccurrenceRequest refers to an occurrence by its original time, as listed by the occurrence list
+	OccurrenceRequest struct {
+		OccurrenceAt time.Time `json:"occurrence_at" validate:"required"`
+	}
+
+	// UpdateOccurrenceRequest edits a single occurrence, the fields that are left out keep the series values
+	UpdateOccurrenceRequest struct {
+		OccurrenceAt time.Time             `json:"occurrence_at" validate:"required"`
+		Title        *string               `json:"title"`
+		LaundryDate  *time.Time            `json:"laundry_date"`
+		Items        []LaundryItemsRequest `json:"items" validate:"omitempty,gt=0,dive"`
+	}
+)
+
+type (
+	SeriesResponse struct {
+		Id     string `json:"id" db:"id"`
+		UserId string `json:"-" db:"user_id"`
+		Title  string `json:"title" db:"title"`
+		RRule  string `json:"rrule" db:"rrule"`
+		// StartsAt is the wall-clock time of the first occurrence in Timezone
+		StartsAt       time.Time    `json:"-" db:"starts_at"`
+		StartsAtString string       `json:"starts_at"`
+		Timezone       string       `json:"timezone" db:"timezone"`
+		Items          LaundryItems `json:"items" db:"items"`
+		IsActive       bool         `json:"is_active" db:"is_active"`
+		GeneratedUntil *time.Time   `json:"generated_until" db:"generated_until"`
+		CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+		UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
+	}
+
+	// SeriesData is a validated series to be stored
+	SeriesData struct {
+		Title    string
+		RRule    string
+		StartsAt time.Time
+		Timezone string
+		Items    LaundryItems
+	}
+
+	SeriesException struct {
+		OccurrenceAt time.Time `db:"occurrence_at"`
+		Kind         string    `db:"kind"`
+	}
+
+	// SeriesLaundry is a routine generated for an occurrence of a series
+	SeriesLaundry struct {
+		OccurrenceAt time.Time `db:"occurrence_at"`
+		Id           string    `db:"id"`
+		LaundryDate  time.Time `db:"laundry_date"`
+		LaundryTime  *string   `db:"laundry_time"`
+		Timezone     *string   `db:"timezone"`
+		Status       int       `db:"status"`
+	}
+
+	// SeriesOccurrenceResponse is an occurrence of the series, LaundryDate is the time its generated routine is
+	// planned at which may have been moved from the occurrence
+	SeriesOccurrenceResponse struct {
+		OccurrenceAt time.Time  `json:"occurrence_at"`
+		LaundryId    *string    `json:"laundry_id"`
+		LaundryDate  *time.Time `json:"laundry_date"`
+		StatusLabel  *string    `json:"status_label"`
+		Skipped      bool       `json:"skipped"`
+		Modified     bool       `json:"modified"`
+	}
+)
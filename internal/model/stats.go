@@ -0,0 +1,75 @@
+package model
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"time"
+)
+
+type (
+	// StatsQueryParam is the range of the stats, From and To are dates (YYYY-MM-DD) in the user's timezone, both inclusive
+	StatsQueryParam struct {
+		From     string
+		To       string
+		Interval string
+	}
+
+	// StatsFilter selects the routines of the user and their household planned between the From and To dates,
+	// Timezone is the one of the user that the times the routines were done are read in
+	StatsFilter struct {
+		UserId   string
+		Timezone string
+		From     time.Time
+		To       time.Time
+	}
+
+	StatsBucket struct {
+		Bucket    time.Time `db:"bucket"`
+		Total     int       `db:"total"`
+		Done      int       `db:"done"`
+		Cancelled int       `db:"cancelled"`
+	}
+
+	StatsStatus struct {
+		Status constants.LaundryStatus `db:"status"`
+		Total  int                     `db:"total"`
+	}
+
+	StatsWeekday struct {
+		// Weekday is the ISO day of the week, 1 is Monday
+		Weekday int `db:"weekday"`
+		Total   int `db:"total"`
+	}
+)
+
+type (
+	StatsResponse struct {
+		From     string `json:"from"`
+		To       string `json:"to"`
+		Interval string `json:"interval"`
+		Timezone string `json:"timezone"`
+		// Routines counts the routines per week or month, the labels are the first day of each bucket
+		Routines         ChartSeries `json:"routines"`
+		ItemsPerCategory ChartSeries `json:"items_per_category"`
+		// Statuses counts the routines per current status
+		Statuses ChartSeries `json:"statuses"`
+		Weekdays ChartSeries `json:"weekdays"`
+		// BusiestWeekday is the localized name of the weekday with the most routines
+		BusiestWeekday *string `json:"busiest_weekday"`
+		// AverageTurnaroundHours is the average time from planned to done of the routines done in the range
+		AverageTurnaroundHours *float64 `json:"average_turnaround_hours"`
+		// LongestOnTimeStreak is the most due routines in a row that were done by their planned date
+		LongestOnTimeStreak int `json:"longest_on_time_streak"`
+	}
+
+	// ChartSeries has a value per label in every dataset, as chart libraries take them
+	ChartSeries struct {
+		Labels   []string       `json:"labels"`
+		Datasets []ChartDataset `json:"datasets"`
+	}
+
+	ChartDataset struct {
+		Key   string    `json:"key"`
+		Label string    `json:"label"`
+		Data  []float64 `json:"data"`
+	}
+)
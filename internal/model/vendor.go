@@ -0,0 +1,95 @@
+package model
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"strconv"
+	"time"
+)
+
+type (
+	VendorRequest struct {
+		Name    string               `json:"name" validate:"required,max=255"`
+		Address *string              `json:"address"`
+		Contact *string              `json:"contact" validate:"omitempty,max=255"`
+		Prices  []VendorPriceRequest `json:"prices" validate:"dive"`
+	}
+
+	// VendorPriceRequest is the price of a category, charged per kg of the routine weight or per item
+	VendorPriceRequest struct {
+		CategoryId string  `json:"category_id" validate:"required"`
+		Unit       string  `json:"unit" validate:"required,oneof=kg item"`
+		Price      float64 `json:"price" validate:"min=0"`
+	}
+
+	VendorResponse struct {
+		Id        string                `json:"id" db:"id"`
+		Name      string                `json:"name" db:"name"`
+		Address   *string               `json:"address" db:"address"`
+		Contact   *string               `json:"contact" db:"contact"`
+		Prices    []VendorPriceResponse `json:"prices" db:"-"`
+		CreatedAt time.Time             `json:"created_at" db:"created_at"`
+		UpdatedAt time.Time             `json:"updated_at" db:"updated_at"`
+	}
+
+	VendorPriceResponse struct {
+		VendorId     string  `json:"-" db:"vendor_id"`
+		CategoryId   string  `json:"category_id" db:"category_id"`
+		CategoryName string  `json:"category_name" db:"category_name"`
+		Unit         string  `json:"unit" db:"unit"`
+		Price        float64 `json:"price" db:"price"`
+	}
+)
+
+type (
+	// LaundryVendorRequest sends a routine to a vendor, the weight is required when the vendor charges its items per kg
+	LaundryVendorRequest struct {
+		VendorId    string   `json:"vendor_id" validate:"required"`
+		DropOffDate string   `json:"drop_off_date" validate:"required,date_format"`
+		PickupDate  string   `json:"pickup_date" validate:"required,date_format"`
+		WeightKg    *float64 `json:"weight_kg" validate:"omitempty,gt=0"`
+	}
+
+	// LaundryVendor is what is stored on a routine sent to a vendor
+	LaundryVendor struct {
+		VendorId    string
+		DropOffDate time.Time
+		PickupDate  time.Time
+		WeightKg    *float64
+		Cost        float64
+	}
+
+	// LaundryVendorResponse is the vendor a routine was sent to. The pickup is overdue when the promised date has passed
+	// in the user's timezone and the routine is neither done nor cancelled.
+	LaundryVendorResponse struct {
+		LaundryId         string    `json:"laundry_id" db:"laundry_id"`
+		Title             string    `json:"title" db:"title"`
+		VendorId          string    `json:"vendor_id" db:"vendor_id"`
+		VendorName        string    `json:"vendor_name" db:"vendor_name"`
+		VendorContact     *string   `json:"vendor_contact" db:"vendor_contact"`
+		DropOffDate       time.Time `json:"-" db:"drop_off_date"`
+		DropOffDateString string    `json:"drop_off_date"`
+		PickupDate        time.Time `json:"-" db:"pickup_date"`
+		PickupDateString  string    `json:"pickup_date"`
+		WeightKg          *float64  `json:"weight_kg" db:"weight_kg"`
+		WeightKgString    string    `json:"-"`
+		Cost              float64   `json:"cost" db:"cost"`
+		IsOverdue         bool      `json:"is_overdue" db:"is_overdue"`
+	}
+
+	// VendorSpendResponse is what was spent at a vendor on the routines dropped off in a range, cancelled ones excluded
+	VendorSpendResponse struct {
+		VendorId   string  `json:"vendor_id" db:"vendor_id"`
+		VendorName string  `json:"vendor_name" db:"vendor_name"`
+		Routines   int     `json:"routines" db:"routines"`
+		WeightKg   float64 `json:"weight_kg" db:"weight_kg"`
+		Total      float64 `json:"total" db:"total"`
+	}
+)
+
+func (l *LaundryVendorResponse) FillDisplayFields(locale string) {
+	l.DropOffDateString = i18n.FormatDate(locale, l.DropOffDate)
+	l.PickupDateString = i18n.FormatDate(locale, l.PickupDate)
+	if l.WeightKg != nil {
+		l.WeightKgString = strconv.FormatFloat(*l.WeightKg, 'f', -1, 64)
+	}
+}
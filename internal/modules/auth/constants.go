@@ -7,3 +7,7 @@ type (
 const (
 	SIGNUP_ACTION OTPAction = "signup"
 )
+
+const (
+	OTP_EXPIRATION_MINUTES = 5
+)
@@ -4,21 +4,26 @@ import (
 	"fmt"
 	"github.com/audricimanuel/errorutils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/service"
-	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
 	"github.com/gin-gonic/gin"
 	jwt2 "github.com/golang-jwt/jwt/v5"
 	"net/http"
-	"time"
+	"strings"
 )
 
 type (
 	AuthController interface {
 		GetLoginPage(ctx *gin.Context)
+		SubmitLoginForm(ctx *gin.Context)
+		GetVerifyEmailPage(ctx *gin.Context)
+		SubmitVerifyEmailForm(ctx *gin.Context)
 		Login(ctx *gin.Context)
+		UpdateLocale(ctx *gin.Context)
 		SignUp(ctx *gin.Context)
 		ForgotPassword(ctx *gin.Context)
 		VerifyEmail(ctx *gin.Context)
@@ -27,18 +32,72 @@ type (
 	AuthControllerImpl struct {
 		cfg         config.Config
 		authService service.AuthService
+		flashStore  tools.FlashStore
 	}
 )
 
-func NewAuthController(cfg config.Config, a service.AuthService) AuthController {
+func NewAuthController(cfg config.Config, a service.AuthService, f tools.FlashStore) AuthController {
 	return &AuthControllerImpl{
 		cfg:         cfg,
 		authService: a,
+		flashStore:  f,
 	}
 }
 
 func (a *AuthControllerImpl) GetLoginPage(ctx *gin.Context) {
-	ctx.HTML(http.StatusOK, "login.html", gin.H{})
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "login.html", gin.H{})
+}
+
+// SubmitLoginForm handles the login form of the web UI and redirects back with a flash message
+func (a *AuthControllerImpl) SubmitLoginForm(ctx *gin.Context) {
+	request := model.UserLoginRequest{
+		Email:    strings.TrimSpace(ctx.PostForm("email")),
+		Password: ctx.PostForm("password"),
+	}
+
+	if err := errorutils.ValidateStruct(&request); err != nil {
+		a.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, err.Error())
+		ctx.Redirect(http.StatusSeeOther, "/login")
+		return
+	}
+
+	jwt, err := a.authService.LoginUser(ctx, request)
+	if err != nil {
+		a.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, i18n.T(i18n.LocaleFromContext(ctx), "flash.login_failed"))
+		ctx.Redirect(http.StatusSeeOther, "/login")
+		return
+	}
+
+	setAuthCookie(ctx, *jwt)
+
+	a.flashStore.Add(ctx, tools.FLASH_LEVEL_SUCCESS, i18n.T(i18n.LocaleFromContext(ctx), "flash.welcome_back"))
+	ctx.Redirect(http.StatusSeeOther, "/")
+}
+
+func (a *AuthControllerImpl) GetVerifyEmailPage(ctx *gin.Context) {
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "verify_email.html", gin.H{})
+}
+
+// SubmitVerifyEmailForm verifies the OTP submitted from the web UI and redirects with the result as a flash message
+func (a *AuthControllerImpl) SubmitVerifyEmailForm(ctx *gin.Context) {
+	userClaims, _ := ctx.Get(constants.USER_DATA)
+	userData := userClaims.(model.UserClaims)
+
+	token := strings.TrimSpace(ctx.PostForm("token"))
+	if token == "" {
+		a.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, i18n.T(i18n.LocaleFromContext(ctx), "flash.verification_code_required"))
+		ctx.Redirect(http.StatusSeeOther, "/verify-email")
+		return
+	}
+
+	if err := a.authService.VerifyEmail(ctx, userData.UserId, token); err != nil {
+		a.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, i18n.T(i18n.LocaleFromContext(ctx), "flash.verification_failed"))
+		ctx.Redirect(http.StatusSeeOther, "/verify-email")
+		return
+	}
+
+	a.flashStore.Add(ctx, tools.FLASH_LEVEL_SUCCESS, i18n.T(i18n.LocaleFromContext(ctx), "flash.verification_success"))
+	ctx.Redirect(http.StatusSeeOther, "/")
 }
 
 func (a *AuthControllerImpl) Login(ctx *gin.Context) {
@@ -84,15 +143,7 @@ func (a *AuthControllerImpl) Login(ctx *gin.Context) {
 		}
 	}
 
-	http.SetCookie(ctx.Writer, &http.Cookie{
-		Name:     constants.COOKIE_AUTH_TOKEN,
-		Value:    *jwt,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-		Expires:  utils.TimeNow().Add(2 * time.Hour),
-	})
+	setAuthCookie(ctx, *jwt)
 
 	httputils.SetHttpResponse(ctx, map[string]interface{}{"token": jwt}, nil, nil)
 }
@@ -112,6 +163,11 @@ func (a *AuthControllerImpl) SignUp(ctx *gin.Context) {
 		return
 	}
 
+	// default to the locale negotiated for this request
+	if !i18n.IsSupported(request.Locale) {
+		request.Locale = i18n.LocaleFromContext(ctx)
+	}
+
 	jwt, err := a.authService.SignUpUser(ctx, request)
 	if err != nil {
 		httputils.SetHttpResponse(ctx, nil, err, nil)
@@ -142,5 +198,47 @@ func (a *AuthControllerImpl) VerifyEmail(ctx *gin.Context) {
 		return
 	}
 
-	httputils.SetHttpResponse(ctx, "Verification success. Please login using your email.", nil, nil)
+	httputils.SetHttpResponse(ctx, i18n.T(i18n.LocaleFromContext(ctx), "api.verification_success"), nil, nil)
+}
+
+// UpdateLocale saves the preferred locale of the user and returns a new token carrying it
+func (a *AuthControllerImpl) UpdateLocale(ctx *gin.Context) {
+	userClaims, _ := ctx.Get(constants.USER_DATA)
+	userData := userClaims.(model.UserClaims)
+
+	var request model.UserUpdateLocaleRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	if !i18n.IsSupported(request.Locale) {
+		httputils.SetHttpResponse(ctx, nil, errorutils.ErrorBadRequest.CustomMessage("invalid locale"), nil)
+		return
+	}
+
+	jwt, err := a.authService.UpdateLocale(ctx, userData.UserId, request.Locale)
+	if err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	if _, err := ctx.Cookie(constants.COOKIE_AUTH_TOKEN); err == nil {
+		setAuthCookie(ctx, *jwt)
+	}
+
+	httputils.SetHttpResponse(ctx, map[string]interface{}{"token": jwt}, nil, nil)
+}
+
+// setAuthCookie stores the token in a session cookie. The token carries its own expiry, the cookie is kept
+// past it so an expired session can be told apart from a missing one.
+func setAuthCookie(ctx *gin.Context, jwt string) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     constants.COOKIE_AUTH_TOKEN,
+		Value:    jwt,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
 }
@@ -3,62 +3,80 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/audricimanuel/errorutils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth"
+	outboxRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/jmoiron/sqlx"
 	"time"
 )
 
 type (
 	AuthRepository interface {
-		AddUser(ctx context.Context, email, fullName, password string) (*model.UserInfoResponse, error)
+		// AddUser inserts the user with its signup OTP, queueing the verification email in the same transaction.
+		// The email is built by the callback since it needs the inserted user.
+		AddUser(ctx context.Context, email, fullName, password, locale, otp string, verificationEmail func(user model.UserInfoResponse) mail.Message) (*model.UserInfoResponse, error)
 		LoginUser(ctx context.Context, email, password string) (*model.UserInfoResponse, error)
-		SaveOTP(ctx context.Context, userId, otp string, action auth.OTPAction) error
+		// SaveOTP stores the OTP, queueing the email that carries it in the same transaction when one is given
+		SaveOTP(ctx context.Context, userId, otp string, action auth.OTPAction, email *mail.Message) error
 		IsValidOTP(ctx context.Context, userId, otp string, action auth.OTPAction) bool
+		UpdateLocale(ctx context.Context, userId, locale string) (*model.UserInfoResponse, error)
 	}
 
 	AuthRepositoryImpl struct {
-		cfg config.Config
-		db  database.DBCollection
+		cfg              config.Config
+		db               database.DBCollection
+		outboxRepository outboxRepository.OutboxRepository
 	}
 )
 
-func NewAuthRepository(cfg config.Config, db database.DBCollection) AuthRepository {
+func NewAuthRepository(cfg config.Config, db database.DBCollection, o outboxRepository.OutboxRepository) AuthRepository {
 	return &AuthRepositoryImpl{
-		cfg: cfg,
-		db:  db,
+		cfg:              cfg,
+		db:               db,
+		outboxRepository: o,
 	}
 }
 
-func (a *AuthRepositoryImpl) AddUser(ctx context.Context, email, fullName, password string) (*model.UserInfoResponse, error) {
+func (a *AuthRepositoryImpl) AddUser(ctx context.Context, email, fullName, password, locale, otp string, verificationEmail func(user model.UserInfoResponse) mail.Message) (*model.UserInfoResponse, error) {
 	log := logging.WithContext(ctx)
 
 	hashedPassword, _ := utils.HashPassword(password)
 
 	query, args := squirrel.Insert("users").
 		Columns(
-			"id", "full_name", "email", "password", "role",
+			"id", "full_name", "email", "password", "role", "locale",
 		).
 		Values(
-			utils.GenerateCleanUUID(), fullName, email, hashedPassword, constants.ROLE_USER,
+			utils.GenerateCleanUUID(), fullName, email, hashedPassword, constants.ROLE_USER, locale,
 		).
-		Suffix("RETURNING id, full_name, email, password, role, is_verified, is_active, created_at, updated_at, deleted_at, last_login").
+		Suffix("RETURNING id, full_name, email, password, role, is_verified, locale, is_active, created_at, updated_at, deleted_at, last_login").
 		PlaceholderFormat(squirrel.Dollar).MustSql()
 
 	var result model.UserInfoResponse
-	if err := a.db.PostgresDBSqlx.QueryRowxContext(ctx, query, args...).StructScan(&result); err != nil {
-		log.Error("error when add user:", err.Error())
-		errDb := errorutils.DefineSQLError(err)
-		if errors.Is(errDb, errorutils.ErrorDuplicateData) {
-			return nil, errorutils.ErrorDuplicateData.CustomMessage("this email has been used, please login using your email")
+	err := database.WithTransaction(ctx, a.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowxContext(ctx, query, args...).StructScan(&result); err != nil {
+			log.Error("error when add user:", err.Error())
+			errDb := errorutils.DefineSQLError(err)
+			if errors.Is(errDb, errorutils.ErrorDuplicateData) {
+				return errorutils.ErrorDuplicateData.CustomMessage("this email has been used, please login using your email")
+			}
+			return errDb
 		}
-		return nil, errDb
+
+		email := verificationEmail(result)
+		return a.saveOTP(ctx, tx, result.Id, otp, auth.SIGNUP_ACTION, &email)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &result, nil
@@ -67,7 +85,7 @@ func (a *AuthRepositoryImpl) AddUser(ctx context.Context, email, fullName, passw
 func (a *AuthRepositoryImpl) LoginUser(ctx context.Context, email, password string) (*model.UserInfoResponse, error) {
 	log := logging.WithContext(ctx)
 
-	query, args := squirrel.Select("id", "full_name", "email", "password", "role", "is_verified",
+	query, args := squirrel.Select("id", "full_name", "email", "password", "role", "is_verified", "locale",
 		"is_active", "created_at", "updated_at", "deleted_at", "last_login").
 		From("users").
 		Where(squirrel.Eq{"email": email}).
@@ -94,20 +112,34 @@ func (a *AuthRepositoryImpl) LoginUser(ctx context.Context, email, password stri
 	return &user, nil
 }
 
-func (a *AuthRepositoryImpl) SaveOTP(ctx context.Context, userId, otp string, action auth.OTPAction) error {
+func (a *AuthRepositoryImpl) SaveOTP(ctx context.Context, userId, otp string, action auth.OTPAction, email *mail.Message) error {
+	return database.WithTransaction(ctx, a.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		return a.saveOTP(ctx, tx, userId, otp, action, email)
+	})
+}
+
+func (a *AuthRepositoryImpl) saveOTP(ctx context.Context, tx sqlx.ExtContext, userId, otp string, action auth.OTPAction, email *mail.Message) error {
 	currentTime := utils.TimeNow()
 	query, args := squirrel.Insert("otps").
 		Columns("user_id", "otp_code", "created_at", "expired_at", "action").
-		Values(userId, otp, currentTime, currentTime.Add(5*time.Minute), action).
+		Values(userId, otp, currentTime, currentTime.Add(auth.OTP_EXPIRATION_MINUTES*time.Minute), action).
+		Suffix("RETURNING id").
 		PlaceholderFormat(squirrel.Dollar).
 		MustSql()
 
-	if _, err := a.db.PostgresDBSqlx.ExecContext(ctx, query, args...); err != nil {
+	var otpId int
+	if err := tx.QueryRowxContext(ctx, query, args...).Scan(&otpId); err != nil {
 		logging.WithContext(ctx).Error("error when add otp:", err.Error())
 		return errorutils.DefineSQLError(err)
 	}
 
-	return nil
+	if email == nil {
+		return nil
+	}
+
+	// the key refers to the OTP row, the code itself is never stored outside the otps table
+	idempotencyKey := fmt.Sprintf("otp:%s:%d", action, otpId)
+	return a.outboxRepository.Enqueue(ctx, tx, idempotencyKey, *email)
 }
 
 func (a *AuthRepositoryImpl) IsValidOTP(ctx context.Context, userId, otp string, action auth.OTPAction) bool {
@@ -147,3 +179,20 @@ func (a *AuthRepositoryImpl) IsValidOTP(ctx context.Context, userId, otp string,
 
 	return id != 0
 }
+
+func (a *AuthRepositoryImpl) UpdateLocale(ctx context.Context, userId, locale string) (*model.UserInfoResponse, error) {
+	query, args := squirrel.Update("users").
+		Set("locale", locale).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": userId}).
+		Suffix("RETURNING id, full_name, email, password, role, is_verified, locale, is_active, created_at, updated_at, deleted_at, last_login").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.UserInfoResponse
+	if err := a.db.PostgresDBSqlx.QueryRowxContext(ctx, query, args...).StructScan(&result); err != nil {
+		logging.WithContext(ctx).Error("error when update user locale:", err.Error())
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"github.com/audricimanuel/errorutils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/repository"
-	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
-	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
 )
 
 type (
@@ -17,31 +17,37 @@ type (
 		SignUpUser(ctx context.Context, request model.UserSignUpRequest) (*string, error)
 		LoginUser(ctx context.Context, request model.UserLoginRequest) (*string, error)
 		VerifyEmail(ctx context.Context, userId, token string) error
+		UpdateLocale(ctx context.Context, userId, locale string) (*string, error)
 	}
 
 	AuthServiceImpl struct {
 		cfg            config.Config
-		smtpClient     tools.SMTPClient
 		authRepository repository.AuthRepository
 	}
 )
 
-func NewAuthService(cfg config.Config, smtp tools.SMTPClient, a repository.AuthRepository) AuthService {
+func NewAuthService(cfg config.Config, a repository.AuthRepository) AuthService {
 	return &AuthServiceImpl{
 		cfg:            cfg,
-		smtpClient:     smtp,
 		authRepository: a,
 	}
 }
 
 func (a *AuthServiceImpl) SignUpUser(ctx context.Context, request model.UserSignUpRequest) (*string, error) {
-	userData, err := a.authRepository.AddUser(ctx, request.Email, request.FullName, request.Password)
+	otpCode, err := utils.GenerateOTP(6)
 	if err != nil {
-		return nil, err
+		return nil, errorutils.ErrorInternalServer.CustomMessage(err.Error())
 	}
 
-	// send verification email
-	a.SendVerificationEmail(ctx, *userData)
+	// the verification email is queued together with the user, and delivered by the outbox worker
+	userData, err := a.authRepository.AddUser(ctx, request.Email, request.FullName, request.Password, request.Locale, otpCode,
+		func(user model.UserInfoResponse) mail.Message {
+			return a.verificationEmail(user, otpCode)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
 
 	jwt, err := a.generateJWT(*userData)
 	if err != nil {
@@ -76,19 +82,29 @@ func (a *AuthServiceImpl) generateJWT(userData model.UserInfoResponse) (string,
 	return token.SignedString(jwtSecret)
 }
 
+// SendVerificationEmail issues a new signup OTP and queues the email carrying it
 func (a *AuthServiceImpl) SendVerificationEmail(ctx context.Context, userData model.UserInfoResponse) error {
-	otpCode, _ := utils.GenerateOTP(6)
-	message := "Here's your verification code:\n" + otpCode
-	message += "\nDon't share this code to anyone else, including me lol."
-
-	err := a.smtpClient.SendEmail("", tools.EMAIL_TYPE_OTP, constants.SUBJECT_OTP_SIGNUP, message, []string{userData.Email}, nil)
+	otpCode, err := utils.GenerateOTP(6)
 	if err != nil {
-		return err
+		return errorutils.ErrorInternalServer.CustomMessage(err.Error())
 	}
 
-	a.authRepository.SaveOTP(ctx, userData.Id, otpCode, auth.SIGNUP_ACTION)
+	message := a.verificationEmail(userData, otpCode)
+	return a.authRepository.SaveOTP(ctx, userData.Id, otpCode, auth.SIGNUP_ACTION, &message)
+}
 
-	return nil
+func (a *AuthServiceImpl) verificationEmail(userData model.UserInfoResponse, otpCode string) mail.Message {
+	return mail.Message{
+		Type:     mail.EMAIL_TYPE_OTP_SIGNUP,
+		Category: mail.CATEGORY_TRANSACTIONAL,
+		Locale:   i18n.Normalize(userData.Locale),
+		To:       []string{userData.Email},
+		Data: mail.Data{
+			"FullName":         userData.FullName,
+			"OTP":              otpCode,
+			"ExpiresInMinutes": auth.OTP_EXPIRATION_MINUTES,
+		},
+	}
 }
 
 func (a *AuthServiceImpl) VerifyEmail(ctx context.Context, userId, token string) error {
@@ -99,3 +115,17 @@ func (a *AuthServiceImpl) VerifyEmail(ctx context.Context, userId, token string)
 
 	return nil
 }
+
+func (a *AuthServiceImpl) UpdateLocale(ctx context.Context, userId, locale string) (*string, error) {
+	userData, err := a.authRepository.UpdateLocale(ctx, userId, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt, err := a.generateJWT(*userData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jwt, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/repository"
+	outboxRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	outboxService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"io"
+	"mime"
+	netMail "net/mail"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// signupRepository adds the user and queues its verification email as the outbox stores it, as JSON
+type signupRepository struct {
+	repository.AuthRepository
+	otp     string
+	payload []byte
+}
+
+func (s *signupRepository) AddUser(ctx context.Context, email, fullName, password, locale, otp string, verificationEmail func(user model.UserInfoResponse) mail.Message) (*model.UserInfoResponse, error) {
+	user := model.UserInfoResponse{Id: "user-1", FullName: fullName, Email: email, Locale: locale, IsActive: true}
+
+	payload, err := json.Marshal(verificationEmail(user))
+	if err != nil {
+		return nil, err
+	}
+
+	s.otp, s.payload = otp, payload
+	return &user, nil
+}
+
+// queuedOutbox hands the queued emails to the outbox worker once
+type queuedOutbox struct {
+	outboxRepository.OutboxRepository
+	emails []model.OutboxEmail
+	sent   []int64
+}
+
+func (q *queuedOutbox) Claim(ctx context.Context, limit int) ([]model.OutboxEmail, error) {
+	emails := q.emails
+	q.emails = nil
+	return emails, nil
+}
+
+func (q *queuedOutbox) MarkSent(ctx context.Context, id int64) error {
+	q.sent = append(q.sent, id)
+	return nil
+}
+
+func TestSignUpUserDelivery(t *testing.T) {
+	cfg := config.Config{JWTSecret: "jwt-secret", JWTExpirationDuration: 2}
+	cfg.SMTPConfig.CSEmailAddress = "cs@example.com"
+	cfg.SMTPConfig.FromName = "Laundry Tracker"
+
+	authRepository := &signupRepository{}
+	token, err := NewAuthService(cfg, authRepository).SignUpUser(context.Background(), model.UserSignUpRequest{
+		FullName: "Jane Doe",
+		Email:    "jane@example.com",
+		Password: "secret",
+		Locale:   "id",
+	})
+	if err != nil || token == nil {
+		t.Fatalf("SignUpUser = %v, %v", token, err)
+	}
+
+	transport := &tools.MemoryTransport{}
+	smtpClient := &tools.SMTPClientImpl{CSEmailAddress: cfg.SMTPConfig.CSEmailAddress, FromName: cfg.SMTPConfig.FromName, Transport: transport}
+	outbox := &queuedOutbox{emails: []model.OutboxEmail{{Id: 1, Payload: authRepository.payload, Attempts: 1, MaxAttempts: 3}}}
+
+	if err := outboxService.NewOutboxService(smtpClient, outbox, 10).DeliverPending(context.Background()); err != nil {
+		t.Fatalf("DeliverPending: %v", err)
+	}
+
+	if !reflect.DeepEqual(outbox.sent, []int64{1}) {
+		t.Errorf("marked as sent %v, want the verification email", outbox.sent)
+	}
+
+	messages := transport.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("got %d delivered email(s), want 1", len(messages))
+	}
+
+	sent := messages[0]
+	if sent.From != "cs@example.com" || !reflect.DeepEqual(sent.Recipients, []string{"jane@example.com"}) {
+		t.Errorf("envelope = %s -> %v, want cs@example.com -> [jane@example.com]", sent.From, sent.Recipients)
+	}
+
+	parsed, err := netMail.ReadMessage(strings.NewReader(string(sent.Raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("DecodeHeader: %v", err)
+	}
+	if want := i18n.T("id", "email.otp_signup.subject"); subject != want {
+		t.Errorf("subject = %q, want %q", subject, want)
+	}
+	if to, err := parsed.Header.AddressList("To"); err != nil || len(to) != 1 || to[0].Address != "jane@example.com" {
+		t.Errorf("To = %v (%v), want jane@example.com", to, err)
+	}
+
+	body, err := io.ReadAll(parsed.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if authRepository.otp == "" || !strings.Contains(string(body), authRepository.otp) {
+		t.Errorf("the email doesn't carry the signup OTP %q", authRepository.otp)
+	}
+
+	transport.Reset()
+	if len(transport.Messages()) != 0 {
+		t.Error("Reset kept the delivered emails")
+	}
+}
@@ -0,0 +1,28 @@
+package calendar
+
+const (
+	// FEED_PATH is where the iCal subscription URLs are served, followed by the token and FEED_EXTENSION
+	FEED_PATH      = "/calendar/"
+	FEED_EXTENSION = ".ics"
+
+	// TOKEN_BYTES is the length of the random feed token before it's hex encoded
+	TOKEN_BYTES = 24
+
+	// FEED_PAST_DAYS is how far back the routines are kept in the feed
+	FEED_PAST_DAYS = 90
+
+	// EVENT_DURATION_MINUTES is the length of the events of routines planned at a time of day
+	EVENT_DURATION_MINUTES = 60
+
+	PROD_ID = "-//Laundry Routine Tracking//Laundry Routines//EN"
+)
+
+// LaundryUID is the event UID of a single routine
+func LaundryUID(laundryId string) string {
+	return "laundry-" + laundryId
+}
+
+// SeriesUID is the event UID of a recurring routine, its generated routines override its occurrences
+func SeriesUID(seriesId string) string {
+	return "series-" + seriesId
+}
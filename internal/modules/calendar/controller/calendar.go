@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"errors"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type (
+	CalendarController interface {
+		GetFeed(ctx *gin.Context)
+		RegenerateFeed(ctx *gin.Context)
+		ExportFeed(ctx *gin.Context)
+
+		// FE
+		GetSettingsPage(ctx *gin.Context)
+		SubmitRegenerateFeedForm(ctx *gin.Context)
+	}
+
+	CalendarControllerImpl struct {
+		calendarService service.CalendarService
+		flashStore      tools.FlashStore
+	}
+)
+
+func NewCalendarController(calendarService service.CalendarService, flashStore tools.FlashStore) CalendarController {
+	return &CalendarControllerImpl{
+		calendarService: calendarService,
+		flashStore:      flashStore,
+	}
+}
+
+func (c *CalendarControllerImpl) GetFeed(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.calendarService.GetFeed(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *CalendarControllerImpl) RegenerateFeed(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.calendarService.RegenerateFeed(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// ExportFeed serves the iCal subscription, calendar apps poll it with the secret token in the path
+func (c *CalendarControllerImpl) ExportFeed(ctx *gin.Context) {
+	token, ok := strings.CutSuffix(ctx.Param("file"), calendar.FEED_EXTENSION)
+	if !ok || token == "" {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	result, err := c.calendarService.ExportFeed(ctx, token)
+	if err != nil {
+		if errors.Is(err, errorutils.ErrorNotFound) {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Header("Content-Disposition", `inline; filename="laundry`+calendar.FEED_EXTENSION+`"`)
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", result)
+}
+
+// GetSettingsPage shows the settings of the user, the calendar subscription URL for now
+func (c *CalendarControllerImpl) GetSettingsPage(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		httputils.InvalidateCookie(ctx, constants.COOKIE_AUTH_TOKEN)
+		ctx.Redirect(http.StatusTemporaryRedirect, "/login")
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+	locale := i18n.LocaleFromContext(ctx)
+
+	dataHtml := gin.H{}
+
+	feed, err := c.calendarService.GetFeed(ctx, userData.UserId)
+	if err != nil {
+		_, message := errorutils.GetStatusCode(err)
+		dataHtml["error"] = i18n.TranslateError(locale, message)
+	}
+	dataHtml["feed"] = feed
+
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "settings.html", dataHtml)
+}
+
+// SubmitRegenerateFeedForm replaces the calendar subscription URL from the settings page, the previous one stops working
+func (c *CalendarControllerImpl) SubmitRegenerateFeedForm(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+	locale := i18n.LocaleFromContext(ctx)
+
+	if _, err := c.calendarService.RegenerateFeed(ctx, userData.UserId); err != nil {
+		_, message := errorutils.GetStatusCode(err)
+		c.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, i18n.T(locale, "flash.calendar_regenerate_failed", i18n.TranslateError(locale, message)))
+	} else {
+		c.flashStore.Add(ctx, tools.FLASH_LEVEL_SUCCESS, i18n.T(locale, "flash.calendar_regenerated"))
+	}
+
+	ctx.Redirect(http.StatusSeeOther, "/settings")
+}
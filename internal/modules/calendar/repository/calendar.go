@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"time"
+)
+
+type (
+	CalendarRepository interface {
+		GetFeed(ctx context.Context, userId string) (*model.CalendarFeed, error)
+		// AddFeed stores the token of the user unless the user already has one
+		AddFeed(ctx context.Context, userId, token string) error
+		// ReplaceFeed stores the token of the user in place of the current one, revoking the old URL
+		ReplaceFeed(ctx context.Context, userId, token string) (*model.CalendarFeed, error)
+		// GetFeedByToken returns the feed of an active user with the owner's locale
+		GetFeedByToken(ctx context.Context, token string) (*model.CalendarFeed, error)
+		// GetFeedLaundries returns the routines of the user and their household planned from the given time onwards with their items
+		GetFeedLaundries(ctx context.Context, userId string, from time.Time) ([]model.CalendarLaundry, error)
+		// GetSkippedOccurrences returns the skipped occurrences of the user's active series
+		GetSkippedOccurrences(ctx context.Context, userId string) ([]model.CalendarSkippedOccurrence, error)
+	}
+
+	CalendarRepositoryImpl struct {
+		db database.DBCollection
+	}
+)
+
+func NewCalendarRepository(db database.DBCollection) CalendarRepository {
+	return &CalendarRepositoryImpl{
+		db: db,
+	}
+}
+
+func (c *CalendarRepositoryImpl) GetFeed(ctx context.Context, userId string) (*model.CalendarFeed, error) {
+	query, args := squirrel.Select("user_id, token, created_at").
+		From("calendar_feeds").
+		Where(squirrel.Eq{"user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CalendarFeed
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting calendar feed:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CalendarRepositoryImpl) AddFeed(ctx context.Context, userId, token string) error {
+	query, args := squirrel.Insert("calendar_feeds").
+		Columns("user_id", "token", "created_at").
+		Values(userId, token, utils.TimeNow()).
+		Suffix("ON CONFLICT (user_id) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := c.db.PostgresDBSqlx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when adding calendar feed:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (c *CalendarRepositoryImpl) ReplaceFeed(ctx context.Context, userId, token string) (*model.CalendarFeed, error) {
+	query, args := squirrel.Insert("calendar_feeds").
+		Columns("user_id", "token", "created_at").
+		Values(userId, token, utils.TimeNow()).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at RETURNING user_id, token, created_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CalendarFeed
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when replacing calendar feed:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CalendarRepositoryImpl) GetFeedByToken(ctx context.Context, token string) (*model.CalendarFeed, error) {
+	query, args := squirrel.Select("f.user_id, f.token, u.locale, f.created_at").
+		From("calendar_feeds f").
+		Join("users u ON u.id = f.user_id").
+		Where(squirrel.Eq{"f.token": token, "u.is_active": true, "u.deleted_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CalendarFeed
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting calendar feed by token:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CalendarRepositoryImpl) GetFeedLaundries(ctx context.Context, userId string, from time.Time) ([]model.CalendarLaundry, error) {
+	log := logging.WithContext(ctx)
+	result := []model.CalendarLaundry{}
+
+	query, args := squirrel.Select("id, title, laundry_date, laundry_time, timezone, status, series_id, occurrence_at").
+		From("laundries").
+		Where(database.AccessibleBy("", userId, false)).
+		Where(squirrel.GtOrEq{"laundry_date": from}).
+		OrderBy("laundry_date", "id").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		log.Error("error when getting calendar laundries:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	queryItems, args := squirrel.Select("li.laundry_id, li.id, li.category_id, c.name AS category_name, li.amount, li.notes").
+		From("laundry_items li").
+		Join("laundries l ON l.id = li.laundry_id").
+		Join("categories c ON c.id = li.category_id").
+		Where(database.AccessibleBy("l.", userId, false)).
+		Where(squirrel.GtOrEq{"l.laundry_date": from}).
+		OrderBy("c.name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	items := []model.CalendarLaundryItem{}
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &items, queryItems, args...); err != nil {
+		log.Error("error when getting calendar laundry items:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	index := make(map[string]int, len(result))
+	for i := range result {
+		index[result[i].Id] = i
+	}
+
+	for _, item := range items {
+		if i, ok := index[item.LaundryId]; ok {
+			result[i].Items = append(result[i].Items, item.LaundryItemResponse)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *CalendarRepositoryImpl) GetSkippedOccurrences(ctx context.Context, userId string) ([]model.CalendarSkippedOccurrence, error) {
+	result := []model.CalendarSkippedOccurrence{}
+
+	query, args := squirrel.Select("e.series_id, e.occurrence_at").
+		From("laundry_series_exceptions e").
+		Join("laundry_series s ON s.id = e.series_id").
+		Where(squirrel.Eq{"s.user_id": userId, "s.is_active": true, "e.kind": series.EXCEPTION_SKIPPED}).
+		OrderBy("e.series_id", "e.occurrence_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting skipped occurrences:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/ical"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar/repository"
+	laundryRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	seriesRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/teambition/rrule-go"
+	"strings"
+	"time"
+)
+
+type (
+	CalendarService interface {
+		// GetFeed returns the subscription URL of the user, its token is created on the first call
+		GetFeed(ctx context.Context, userId string) (*model.CalendarFeedResponse, error)
+		// RegenerateFeed replaces the token of the user, the previous URL stops working
+		RegenerateFeed(ctx context.Context, userId string) (*model.CalendarFeedResponse, error)
+		// ExportFeed renders the routines of the owner of the token as an iCalendar
+		ExportFeed(ctx context.Context, token string) ([]byte, error)
+	}
+
+	CalendarServiceImpl struct {
+		cfg                config.Config
+		calendarRepository repository.CalendarRepository
+		laundryRepository  laundryRepository.LaundryRepository
+		seriesRepository   seriesRepository.SeriesRepository
+	}
+)
+
+func NewCalendarService(cfg config.Config, c repository.CalendarRepository, l laundryRepository.LaundryRepository, s seriesRepository.SeriesRepository) CalendarService {
+	return &CalendarServiceImpl{
+		cfg:                cfg,
+		calendarRepository: c,
+		laundryRepository:  l,
+		seriesRepository:   s,
+	}
+}
+
+func (c *CalendarServiceImpl) GetFeed(ctx context.Context, userId string) (*model.CalendarFeedResponse, error) {
+	feed, err := c.calendarRepository.GetFeed(ctx, userId)
+	if errors.Is(err, errorutils.ErrorNotFound) {
+		token, errToken := utils.GenerateToken(calendar.TOKEN_BYTES)
+		if errToken != nil {
+			logging.WithContext(ctx).Error("error when generating calendar token:", errToken)
+			return nil, errorutils.ErrorInternalServer.CustomMessage(errToken.Error())
+		}
+
+		// a concurrent call may have created the token first, both return the stored one
+		if err := c.calendarRepository.AddFeed(ctx, userId, token); err != nil {
+			return nil, err
+		}
+
+		feed, err = c.calendarRepository.GetFeed(ctx, userId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c.feedResponse(*feed), nil
+}
+
+func (c *CalendarServiceImpl) RegenerateFeed(ctx context.Context, userId string) (*model.CalendarFeedResponse, error) {
+	token, err := utils.GenerateToken(calendar.TOKEN_BYTES)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when generating calendar token:", err)
+		return nil, errorutils.ErrorInternalServer.CustomMessage(err.Error())
+	}
+
+	feed, err := c.calendarRepository.ReplaceFeed(ctx, userId, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.feedResponse(*feed), nil
+}
+
+func (c *CalendarServiceImpl) ExportFeed(ctx context.Context, token string) ([]byte, error) {
+	log := logging.WithContext(ctx)
+
+	feed, err := c.calendarRepository.GetFeedByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := utils.TimeNow()
+
+	laundries, err := c.calendarRepository.GetFeedLaundries(ctx, feed.UserId, currentTime.AddDate(0, 0, -calendar.FEED_PAST_DAYS))
+	if err != nil {
+		return nil, err
+	}
+
+	seriesList, err := c.seriesRepository.GetSeriesList(ctx, feed.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	skippedList, err := c.calendarRepository.GetSkippedOccurrences(ctx, feed.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := c.laundryRepository.GetCategoryList(ctx, feed.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryNames := map[string]string{}
+	for _, category := range categories {
+		categoryNames[category.Id] = category.Name
+	}
+
+	skipped := map[string][]time.Time{}
+	for _, occurrence := range skippedList {
+		skipped[occurrence.SeriesId] = append(skipped[occurrence.SeriesId], occurrence.OccurrenceAt)
+	}
+
+	locale := feed.Locale
+	events := []ical.Event{}
+
+	// the active series are exported with their rule, their generated routines override single occurrences
+	activeSeries := map[string]model.SeriesResponse{}
+	for _, s := range seriesList {
+		if !s.IsActive {
+			continue
+		}
+
+		event, err := seriesEvent(s, skipped[s.Id], categoryNames, locale, currentTime)
+		if err != nil {
+			// a series that can't be expressed is left out instead of failing the whole feed
+			log.Error("error when exporting series "+s.Id+":", err)
+			continue
+		}
+
+		activeSeries[s.Id] = s
+		events = append(events, *event)
+	}
+
+	for _, laundry := range laundries {
+		event := laundryEvent(laundry, locale, currentTime)
+
+		if laundry.SeriesId != nil && laundry.OccurrenceAt != nil {
+			if s, ok := activeSeries[*laundry.SeriesId]; ok {
+				if isSkipped(skipped[s.Id], *laundry.OccurrenceAt) {
+					continue
+				}
+
+				occurrenceAt := *laundry.OccurrenceAt
+				event.UID = calendar.SeriesUID(s.Id)
+				event.RecurrenceId = &occurrenceAt
+				event.TimeZone = s.Timezone
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	return ical.Calendar{
+		ProdId: calendar.PROD_ID,
+		Name:   i18n.T(locale, "app.name"),
+		Events: events,
+	}.Encode(), nil
+}
+
+func (c *CalendarServiceImpl) feedResponse(feed model.CalendarFeed) *model.CalendarFeedResponse {
+	return &model.CalendarFeedResponse{
+		Url:       strings.TrimRight(c.cfg.Host.BaseUrl, "/") + calendar.FEED_PATH + feed.Token + calendar.FEED_EXTENSION,
+		CreatedAt: feed.CreatedAt,
+	}
+}
+
+// laundryEvent exports a single routine, routines planned by their date only become all-day events
+func laundryEvent(laundry model.CalendarLaundry, locale string, stamp time.Time) ical.Event {
+	status := constants.LaundryStatus(laundry.Status)
+
+	lines := make([]string, 0, len(laundry.Items))
+	for _, item := range laundry.Items {
+		lines = append(lines, itemLine(item.CategoryName, item.Amount, item.Notes))
+	}
+
+	event := ical.Event{
+		UID:         calendar.LaundryUID(laundry.Id),
+		Stamp:       stamp,
+		Start:       laundry.LaundryDate,
+		Summary:     laundry.Title,
+		Description: strings.Join(lines, "\n"),
+		Categories:  []string{model.LaundryStatusLabel(locale, status)},
+		Status:      eventStatus(status),
+	}
+
+	if plannedAt, ok := model.LaundryPlannedAt(laundry.LaundryDate, laundry.LaundryTime, laundry.Timezone); ok {
+		event.Start = plannedAt
+		event.TimeZone = *laundry.Timezone
+		event.Duration = calendar.EVENT_DURATION_MINUTES * time.Minute
+	} else {
+		event.AllDay = true
+	}
+
+	return event
+}
+
+// seriesEvent exports the series as a recurring event starting at its first occurrence in its timezone
+func seriesEvent(s model.SeriesResponse, skipped []time.Time, categoryNames map[string]string, locale string, stamp time.Time) (*ical.Event, error) {
+	location, err := utils.LoadTimezone(s.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	option, err := rrule.StrToROption(s.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(s.Items))
+	for _, item := range s.Items {
+		lines = append(lines, itemLine(categoryNames[item.CategoryId], item.Amount, item.Notes))
+	}
+
+	startsAt := s.StartsAt
+
+	return &ical.Event{
+		UID:         calendar.SeriesUID(s.Id),
+		Stamp:       stamp,
+		Start:       time.Date(startsAt.Year(), startsAt.Month(), startsAt.Day(), startsAt.Hour(), startsAt.Minute(), startsAt.Second(), 0, location),
+		Duration:    calendar.EVENT_DURATION_MINUTES * time.Minute,
+		TimeZone:    s.Timezone,
+		Summary:     s.Title,
+		Description: strings.Join(lines, "\n"),
+		Categories:  []string{model.LaundryStatusLabel(locale, constants.LAUNDRY_STATUS_PLANNED)},
+		Status:      eventStatus(constants.LAUNDRY_STATUS_PLANNED),
+		RRule:       option.RRuleString(),
+		ExDates:     skipped,
+	}, nil
+}
+
+func itemLine(categoryName string, amount int, notes *string) string {
+	line := fmt.Sprintf("%s: %d", categoryName, amount)
+	if notes != nil && *notes != "" {
+		line += " (" + *notes + ")"
+	}
+	return line
+}
+
+func eventStatus(status constants.LaundryStatus) string {
+	if status == constants.LAUNDRY_STATUS_CANCELLED {
+		return ical.STATUS_CANCELLED
+	}
+	return ical.STATUS_CONFIRMED
+}
+
+func isSkipped(skipped []time.Time, occurrenceAt time.Time) bool {
+	for _, t := range skipped {
+		if t.Equal(occurrenceAt) {
+			return true
+		}
+	}
+	return false
+}
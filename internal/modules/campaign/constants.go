@@ -0,0 +1,64 @@
+package campaign
+
+import "slices"
+
+type (
+	Status    string
+	Audience  string
+	EventType string
+)
+
+const (
+	STATUS_DRAFT     Status = "draft"
+	STATUS_SCHEDULED Status = "scheduled"
+	// STATUS_SENT means every recipient has been queued in the email outbox
+	STATUS_SENT      Status = "sent"
+	STATUS_CANCELLED Status = "cancelled"
+	// STATUS_FAILED means the emails couldn't be built and nothing was queued, the reason is kept on the campaign
+	STATUS_FAILED Status = "failed"
+)
+
+const (
+	AUDIENCE_ALL_VERIFIED Audience = "all_verified"
+	// AUDIENCE_INACTIVE is the verified users who haven't logged in for the campaign's inactive days
+	AUDIENCE_INACTIVE Audience = "inactive"
+	// AUDIENCE_NO_ROUTINES_THIS_MONTH is the verified users without any laundry routine in the current month
+	AUDIENCE_NO_ROUTINES_THIS_MONTH Audience = "no_routines_this_month"
+)
+
+const (
+	EVENT_OPEN  EventType = "open"
+	EVENT_CLICK EventType = "click"
+)
+
+const (
+	CAMPAIGN_LIST_LIMIT  = 20
+	RECIPIENT_LIST_LIMIT = 50
+
+	// DISPATCH_INTERVAL_SECONDS is how often the scheduler looks for campaigns that are due
+	DISPATCH_INTERVAL_SECONDS = 60
+)
+
+func (a Audience) IsValid() bool {
+	switch a {
+	case AUDIENCE_ALL_VERIFIED, AUDIENCE_INACTIVE, AUDIENCE_NO_ROUTINES_THIS_MONTH:
+		return true
+	}
+	return false
+}
+
+// EditableStatuses are the statuses of the campaigns whose content and audience can still be changed,
+// which can also be scheduled or cancelled
+func EditableStatuses() []Status {
+	return []Status{STATUS_DRAFT, STATUS_SCHEDULED, STATUS_FAILED}
+}
+
+// IsEditable reports whether the campaign content and audience can still be changed
+func (s Status) IsEditable() bool {
+	return slices.Contains(EditableStatuses(), s)
+}
+
+// OutboxKey is the idempotency key of the campaign email of a user in the email outbox
+func OutboxKey(campaignId, userId string) string {
+	return "campaign:" + campaignId + ":" + userId
+}
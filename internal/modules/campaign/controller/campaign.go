@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// trackingPixel is a transparent 1x1 GIF
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+type (
+	CampaignController interface {
+		GetCampaignList(ctx *gin.Context)
+		GetCampaign(ctx *gin.Context)
+		AddCampaign(ctx *gin.Context)
+		UpdateCampaign(ctx *gin.Context)
+		PreviewCampaign(ctx *gin.Context)
+		ScheduleCampaign(ctx *gin.Context)
+		CancelCampaign(ctx *gin.Context)
+		GetRecipientList(ctx *gin.Context)
+		GetCampaignStats(ctx *gin.Context)
+		TrackOpen(ctx *gin.Context)
+		TrackClick(ctx *gin.Context)
+	}
+
+	CampaignControllerImpl struct {
+		campaignService service.CampaignService
+	}
+)
+
+func NewCampaignController(campaignService service.CampaignService) CampaignController {
+	return &CampaignControllerImpl{
+		campaignService: campaignService,
+	}
+}
+
+func (c *CampaignControllerImpl) GetCampaignList(ctx *gin.Context) {
+	page := utils.ConvertStrToInt(strings.TrimSpace(ctx.Query("page")), 1)
+
+	result, total, err := c.campaignService.GetCampaignList(ctx, page)
+	if err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	meta := httputils.SetBaseMeta(page, campaign.CAMPAIGN_LIST_LIMIT, total)
+	httputils.SetHttpResponse(ctx, result, nil, &meta)
+}
+
+func (c *CampaignControllerImpl) GetCampaign(ctx *gin.Context) {
+	result, err := c.campaignService.GetCampaign(ctx, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *CampaignControllerImpl) AddCampaign(ctx *gin.Context) {
+	var request model.CampaignRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.campaignService.AddCampaign(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *CampaignControllerImpl) UpdateCampaign(ctx *gin.Context) {
+	var request model.CampaignRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	result, err := c.campaignService.UpdateCampaign(ctx, ctx.Param("id"), request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *CampaignControllerImpl) PreviewCampaign(ctx *gin.Context) {
+	var request model.PreviewCampaignRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+			httputils.SetHttpResponse(ctx, nil, err, nil)
+			return
+		}
+	}
+
+	result, err := c.campaignService.PreviewCampaign(ctx, ctx.Param("id"), request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *CampaignControllerImpl) ScheduleCampaign(ctx *gin.Context) {
+	var request model.ScheduleCampaignRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	result, err := c.campaignService.ScheduleCampaign(ctx, ctx.Param("id"), request.ScheduledAt)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *CampaignControllerImpl) CancelCampaign(ctx *gin.Context) {
+	result, err := c.campaignService.CancelCampaign(ctx, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *CampaignControllerImpl) GetRecipientList(ctx *gin.Context) {
+	page := utils.ConvertStrToInt(strings.TrimSpace(ctx.Query("page")), 1)
+
+	result, total, err := c.campaignService.GetRecipientList(ctx, ctx.Param("id"), page)
+	if err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	meta := httputils.SetBaseMeta(page, campaign.RECIPIENT_LIST_LIMIT, total)
+	httputils.SetHttpResponse(ctx, result, nil, &meta)
+}
+
+func (c *CampaignControllerImpl) GetCampaignStats(ctx *gin.Context) {
+	result, err := c.campaignService.GetCampaignStats(ctx, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// TrackOpen serves the tracking pixel, the pixel is served even when the token is invalid so the email still renders
+func (c *CampaignControllerImpl) TrackOpen(ctx *gin.Context) {
+	c.campaignService.TrackOpen(ctx, ctx.Param("token"))
+
+	ctx.Header("Cache-Control", "no-store, max-age=0")
+	ctx.Data(http.StatusOK, "image/gif", trackingPixel)
+}
+
+func (c *CampaignControllerImpl) TrackClick(ctx *gin.Context) {
+	target, err := c.campaignService.TrackClick(ctx, ctx.Param("token"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store, max-age=0")
+	ctx.Redirect(http.StatusFound, target)
+}
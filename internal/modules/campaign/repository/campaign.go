@@ -0,0 +1,469 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox"
+	outboxRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+const (
+	campaignColumns = `id, "name", subject, html_body, text_body, audience, inactive_days, status, failure_reason, scheduled_at, sent_at, created_by, created_at, updated_at`
+)
+
+type (
+	CampaignRepository interface {
+		GetCampaignList(ctx context.Context, page int) ([]model.CampaignResponse, int, error)
+		GetCampaign(ctx context.Context, id string) (*model.CampaignResponse, error)
+		AddCampaign(ctx context.Context, userId string, request model.CampaignRequest) (*model.CampaignResponse, error)
+		// UpdateCampaign replaces the content and audience of a campaign that hasn't been sent yet
+		UpdateCampaign(ctx context.Context, id string, request model.CampaignRequest) error
+		// UpdateCampaignStatus moves the campaign to the status when it currently has one of the from statuses
+		UpdateCampaignStatus(ctx context.Context, id string, from []campaign.Status, to campaign.Status, scheduledAt *time.Time) error
+		GetDeliveryStats(ctx context.Context, id string) (*model.CampaignDeliveryStats, error)
+		GetRecipientList(ctx context.Context, id string, page int) ([]model.CampaignRecipientResponse, int, error)
+		GetStats(ctx context.Context, id string) (*model.CampaignStatsResponse, error)
+		// AddEvent records an open or click of a recipient, events of users who didn't receive the campaign are ignored
+		AddEvent(ctx context.Context, campaignId, userId string, eventType campaign.EventType, url *string) error
+		GetAudience(ctx context.Context, c model.CampaignResponse, limit int) ([]model.CampaignAudienceUser, error)
+		CountAudience(ctx context.Context, c model.CampaignResponse) (int, error)
+		GetAudienceUserById(ctx context.Context, userId string) (*model.CampaignAudienceUser, error)
+		// DispatchDueCampaign sends the next scheduled campaign that is due: its recipients are resolved from the
+		// audience and their emails queued in the outbox, all in one transaction. Returns nil when nothing is due.
+		// A campaign whose emails can't be built is marked failed instead, with nothing queued.
+		DispatchDueCampaign(ctx context.Context, build func(c model.CampaignResponse, user model.CampaignAudienceUser) (mail.Message, error)) (*model.CampaignResponse, int, error)
+	}
+
+	CampaignRepositoryImpl struct {
+		db               database.DBCollection
+		outboxRepository outboxRepository.OutboxRepository
+	}
+)
+
+func NewCampaignRepository(db database.DBCollection, o outboxRepository.OutboxRepository) CampaignRepository {
+	return &CampaignRepositoryImpl{
+		db:               db,
+		outboxRepository: o,
+	}
+}
+
+func (c *CampaignRepositoryImpl) GetCampaignList(ctx context.Context, page int) ([]model.CampaignResponse, int, error) {
+	result := []model.CampaignResponse{}
+	log := logging.WithContext(ctx)
+
+	limit := campaign.CAMPAIGN_LIST_LIMIT
+	offset := 0
+	if page > 1 {
+		offset = (page - 1) * limit
+	}
+
+	query, args := squirrel.Select(campaignColumns).
+		From("campaigns").
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		log.Error("error when getting campaign list:", err)
+		return result, 0, errorutils.DefineSQLError(err)
+	}
+
+	var total int
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &total, "SELECT COUNT(*) FROM campaigns"); err != nil {
+		log.Error("error when counting campaigns:", err)
+		return result, 0, errorutils.DefineSQLError(err)
+	}
+
+	return result, total, nil
+}
+
+func (c *CampaignRepositoryImpl) GetCampaign(ctx context.Context, id string) (*model.CampaignResponse, error) {
+	query, args := squirrel.Select(campaignColumns).
+		From("campaigns").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CampaignResponse
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting campaign:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CampaignRepositoryImpl) AddCampaign(ctx context.Context, userId string, request model.CampaignRequest) (*model.CampaignResponse, error) {
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Insert("campaigns").
+		Columns("id", `"name"`, "subject", "html_body", "text_body", "audience", "inactive_days", "status", "created_by", "created_at", "updated_at").
+		Values(
+			utils.GenerateCleanUUID(), request.Name, request.Subject, request.HTMLBody, request.TextBody, request.Audience, request.InactiveDays,
+			campaign.STATUS_DRAFT, userId, currentTime, currentTime,
+		).
+		Suffix("RETURNING " + campaignColumns).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CampaignResponse
+	if err := c.db.PostgresDBSqlx.QueryRowxContext(ctx, query, args...).StructScan(&result); err != nil {
+		logging.WithContext(ctx).Error("error when add campaign:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CampaignRepositoryImpl) UpdateCampaign(ctx context.Context, id string, request model.CampaignRequest) error {
+	query, args := squirrel.Update("campaigns").
+		Set(`"name"`, request.Name).
+		Set("subject", request.Subject).
+		Set("html_body", request.HTMLBody).
+		Set("text_body", request.TextBody).
+		Set("audience", request.Audience).
+		Set("inactive_days", request.InactiveDays).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id, "status": campaign.EditableStatuses()}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := c.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when updating campaign:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorBadRequest.CustomMessage("campaign can no longer be changed")
+	}
+
+	return nil
+}
+
+func (c *CampaignRepositoryImpl) UpdateCampaignStatus(ctx context.Context, id string, from []campaign.Status, to campaign.Status, scheduledAt *time.Time) error {
+	query, args := squirrel.Update("campaigns").
+		Set("status", to).
+		Set("scheduled_at", scheduledAt).
+		Set("failure_reason", nil).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id, "status": from}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := c.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when updating campaign status:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorBadRequest.CustomMessage("invalid campaign status transition")
+	}
+
+	return nil
+}
+
+func (c *CampaignRepositoryImpl) GetDeliveryStats(ctx context.Context, id string) (*model.CampaignDeliveryStats, error) {
+	countStatus := func(status outbox.Status, alias string) squirrel.Sqlizer {
+		return squirrel.Alias(squirrel.Expr("COUNT(o.id) FILTER (WHERE o.status = ?)", status), alias)
+	}
+
+	query, args := squirrel.Select("COUNT(r.id) AS total_recipients").
+		Column(countStatus(outbox.STATUS_PENDING, "pending")).
+		Column(countStatus(outbox.STATUS_SENDING, "sending")).
+		Column(countStatus(outbox.STATUS_SENT, "sent")).
+		Column(countStatus(outbox.STATUS_DEAD, "failed")).
+		From("campaign_recipients r").
+		LeftJoin("email_outbox o ON o.idempotency_key = r.outbox_key").
+		Where(squirrel.Eq{"r.campaign_id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CampaignDeliveryStats
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting campaign delivery stats:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CampaignRepositoryImpl) GetRecipientList(ctx context.Context, id string, page int) ([]model.CampaignRecipientResponse, int, error) {
+	result := []model.CampaignRecipientResponse{}
+	log := logging.WithContext(ctx)
+
+	limit := campaign.RECIPIENT_LIST_LIMIT
+	offset := 0
+	if page > 1 {
+		offset = (page - 1) * limit
+	}
+
+	query, args := squirrel.Select("r.user_id, r.email, r.created_at, o.last_error, o.sent_at").
+		Column(squirrel.Alias(squirrel.Expr("COALESCE(o.status, ?)", outbox.STATUS_PENDING), "status")).
+		Column("COALESCE(o.attempts, 0) AS attempts").
+		From("campaign_recipients r").
+		LeftJoin("email_outbox o ON o.idempotency_key = r.outbox_key").
+		Where(squirrel.Eq{"r.campaign_id": id}).
+		OrderBy("r.id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		log.Error("error when getting campaign recipients:", err)
+		return result, 0, errorutils.DefineSQLError(err)
+	}
+
+	countQuery, countArgs := squirrel.Select("COUNT(*)").
+		From("campaign_recipients").
+		Where(squirrel.Eq{"campaign_id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var total int
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &total, countQuery, countArgs...); err != nil {
+		log.Error("error when counting campaign recipients:", err)
+		return result, 0, errorutils.DefineSQLError(err)
+	}
+
+	return result, total, nil
+}
+
+func (c *CampaignRepositoryImpl) GetStats(ctx context.Context, id string) (*model.CampaignStatsResponse, error) {
+	hasEvent := "EXISTS (SELECT 1 FROM campaign_events e WHERE e.campaign_id = r.campaign_id AND e.user_id = r.user_id%s)"
+
+	query, args := squirrel.Select("COUNT(r.id) AS recipients").
+		Column(squirrel.Alias(squirrel.Expr("COUNT(o.id) FILTER (WHERE o.status = ?)", outbox.STATUS_SENT), "delivered")).
+		Column(squirrel.Alias(squirrel.Expr("COUNT(o.id) FILTER (WHERE o.status = ?)", outbox.STATUS_DEAD), "failed")).
+		Column(squirrel.Alias(squirrel.Expr("COUNT(r.id) FILTER (WHERE "+fmt.Sprintf(hasEvent, "")+")"), "opened")).
+		Column(squirrel.Alias(squirrel.Expr("COUNT(r.id) FILTER (WHERE "+fmt.Sprintf(hasEvent, " AND e.event_type = ?")+")", campaign.EVENT_CLICK), "clicked")).
+		Column(squirrel.Alias(squirrel.Expr(
+			"COUNT(r.id) FILTER (WHERE EXISTS (SELECT 1 FROM email_preferences p WHERE p.user_id = r.user_id AND p.category = ? AND NOT p.subscribed AND p.updated_at >= r.created_at))",
+			mail.CATEGORY_MARKETING,
+		), "unsubscribed")).
+		From("campaign_recipients r").
+		LeftJoin("email_outbox o ON o.idempotency_key = r.outbox_key").
+		Where(squirrel.Eq{"r.campaign_id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CampaignStatsResponse
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting campaign stats:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CampaignRepositoryImpl) AddEvent(ctx context.Context, campaignId, userId string, eventType campaign.EventType, url *string) error {
+	recipient := squirrel.Select("campaign_id, user_id").
+		Column("?::VARCHAR", eventType).
+		Column("?::TEXT", url).
+		Column("?::TIMESTAMP", utils.TimeNow()).
+		From("campaign_recipients").
+		Where(squirrel.Eq{"campaign_id": campaignId, "user_id": userId})
+
+	query, args := squirrel.Insert("campaign_events").
+		Columns("campaign_id", "user_id", "event_type", "url", "created_at").
+		Select(recipient).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := c.db.PostgresDBSqlx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when adding campaign event:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (c *CampaignRepositoryImpl) GetAudience(ctx context.Context, cmp model.CampaignResponse, limit int) ([]model.CampaignAudienceUser, error) {
+	result := []model.CampaignAudienceUser{}
+
+	query := audienceQuery(cmp, utils.TimeNow())
+	if limit > 0 {
+		query = query.Limit(uint64(limit))
+	}
+
+	sql, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, sql, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting campaign audience:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (c *CampaignRepositoryImpl) CountAudience(ctx context.Context, cmp model.CampaignResponse) (int, error) {
+	query, args := squirrel.Select("COUNT(*)").
+		FromSelect(audienceQuery(cmp, utils.TimeNow()), "audience").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result int
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when counting campaign audience:", err)
+		return 0, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (c *CampaignRepositoryImpl) GetAudienceUserById(ctx context.Context, userId string) (*model.CampaignAudienceUser, error) {
+	query, args := squirrel.Select("id, email, full_name, locale").
+		From("users").
+		Where(squirrel.Eq{"id": userId, "deleted_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.CampaignAudienceUser
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting user:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *CampaignRepositoryImpl) DispatchDueCampaign(ctx context.Context, build func(c model.CampaignResponse, user model.CampaignAudienceUser) (mail.Message, error)) (*model.CampaignResponse, int, error) {
+	log := logging.WithContext(ctx)
+	currentTime := utils.TimeNow()
+
+	var (
+		result     *model.CampaignResponse
+		recipients int
+	)
+
+	err := database.WithTransaction(ctx, c.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		// the campaign stays locked until it's sent, other instances of the scheduler skip it
+		query, args := squirrel.Select(campaignColumns).
+			From("campaigns").
+			Where(squirrel.Eq{"status": campaign.STATUS_SCHEDULED}).
+			Where(squirrel.LtOrEq{"scheduled_at": currentTime}).
+			OrderBy("scheduled_at").
+			Limit(1).
+			Suffix("FOR UPDATE SKIP LOCKED").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		var due model.CampaignResponse
+		if err := tx.GetContext(ctx, &due, query, args...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		audienceSql, audienceArgs := audienceQuery(due, currentTime).PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		var users []model.CampaignAudienceUser
+		if err := tx.SelectContext(ctx, &users, audienceSql, audienceArgs...); err != nil {
+			return err
+		}
+
+		// every email is built before any is queued, a campaign that can't be built would otherwise be
+		// retried on every run and hold back the campaigns due after it
+		messages := make([]mail.Message, 0, len(users))
+		for _, user := range users {
+			message, err := build(due, user)
+			if err != nil {
+				reason := fmt.Sprintf("the email of user %s can't be built: %s", user.Id, err)
+
+				queryFailed, args := squirrel.Update("campaigns").
+					Set("status", campaign.STATUS_FAILED).
+					Set("failure_reason", reason).
+					Set("updated_at", currentTime).
+					Where(squirrel.Eq{"id": due.Id}).
+					PlaceholderFormat(squirrel.Dollar).MustSql()
+
+				if _, err := tx.ExecContext(ctx, queryFailed, args...); err != nil {
+					return err
+				}
+
+				due.Status = string(campaign.STATUS_FAILED)
+				due.FailureReason = &reason
+				result = &due
+
+				return nil
+			}
+
+			messages = append(messages, message)
+		}
+
+		for i, user := range users {
+			message := messages[i]
+			outboxKey := campaign.OutboxKey(due.Id, user.Id)
+
+			queryRecipient, args := squirrel.Insert("campaign_recipients").
+				Columns("campaign_id", "user_id", "email", "outbox_key", "created_at").
+				Values(due.Id, user.Id, user.Email, outboxKey, currentTime).
+				Suffix("ON CONFLICT (campaign_id, user_id) DO NOTHING").
+				PlaceholderFormat(squirrel.Dollar).MustSql()
+
+			if _, err := tx.ExecContext(ctx, queryRecipient, args...); err != nil {
+				return err
+			}
+
+			if err := c.outboxRepository.Enqueue(ctx, tx, outboxKey, message); err != nil {
+				return err
+			}
+		}
+
+		queryUpdate, args := squirrel.Update("campaigns").
+			Set("status", campaign.STATUS_SENT).
+			Set("sent_at", currentTime).
+			Set("updated_at", currentTime).
+			Where(squirrel.Eq{"id": due.Id}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, queryUpdate, args...); err != nil {
+			return err
+		}
+
+		due.Status = string(campaign.STATUS_SENT)
+		due.SentAt = &currentTime
+		result = &due
+		recipients = len(users)
+
+		return nil
+	})
+	if err != nil {
+		log.Error("error when dispatching campaign:", err)
+		return nil, 0, errorutils.DefineSQLError(err)
+	}
+
+	return result, recipients, nil
+}
+
+// audienceQuery selects the users the campaign is sent to, evaluated at the given time.
+// Users who unsubscribed from marketing emails are never part of it.
+func audienceQuery(cmp model.CampaignResponse, now time.Time) squirrel.SelectBuilder {
+	query := squirrel.Select("u.id, u.email, u.full_name, u.locale").
+		From("users u").
+		Where(squirrel.Eq{"u.is_verified": true, "u.is_active": true, "u.deleted_at": nil}).
+		Where("NOT EXISTS (SELECT 1 FROM email_preferences p WHERE p.user_id = u.id AND p.category = ? AND NOT p.subscribed)", mail.CATEGORY_MARKETING)
+
+	switch campaign.Audience(cmp.Audience) {
+	case campaign.AUDIENCE_INACTIVE:
+		inactiveDays := 0
+		if cmp.InactiveDays != nil {
+			inactiveDays = *cmp.InactiveDays
+		}
+		query = query.Where(squirrel.Lt{"COALESCE(u.last_login, u.created_at)": now.AddDate(0, 0, -inactiveDays)})
+	case campaign.AUDIENCE_NO_ROUTINES_THIS_MONTH:
+		// laundry_date is a local date, the month is the current one in the timezone of each user
+		laundries := squirrel.Select("1").
+			From("laundries l").
+			Where(database.AccessibleByColumn("l.", "u.id", false)).
+			Where("l.laundry_date >= DATE_TRUNC('month', ?::TIMESTAMPTZ AT TIME ZONE u.timezone)", now).
+			Where("l.laundry_date < DATE_TRUNC('month', ?::TIMESTAMPTZ AT TIME ZONE u.timezone) + INTERVAL '1 month'", now)
+		query = query.Where(squirrel.Expr("NOT EXISTS (?)", laundries))
+	}
+
+	return query.OrderBy("u.created_at", "u.id")
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"html"
+	htmlTemplate "html/template"
+	"regexp"
+	"strings"
+	textTemplate "text/template"
+	"time"
+)
+
+type (
+	CampaignService interface {
+		GetCampaignList(ctx context.Context, page int) ([]model.CampaignResponse, int, error)
+		GetCampaign(ctx context.Context, id string) (*model.CampaignResponse, error)
+		AddCampaign(ctx context.Context, userId string, request model.CampaignRequest) (*model.CampaignResponse, error)
+		UpdateCampaign(ctx context.Context, id string, request model.CampaignRequest) (*model.CampaignResponse, error)
+		PreviewCampaign(ctx context.Context, id string, request model.PreviewCampaignRequest) (*model.CampaignPreviewResponse, error)
+		ScheduleCampaign(ctx context.Context, id string, scheduledAt time.Time) (*model.CampaignResponse, error)
+		CancelCampaign(ctx context.Context, id string) (*model.CampaignResponse, error)
+		GetRecipientList(ctx context.Context, id string, page int) ([]model.CampaignRecipientResponse, int, error)
+		GetCampaignStats(ctx context.Context, id string) (*model.CampaignStatsResponse, error)
+		TrackOpen(ctx context.Context, token string) error
+		// TrackClick returns the link to redirect to, it's returned even when tracking is disabled
+		TrackClick(ctx context.Context, token string) (string, error)
+		// DispatchDueCampaigns queues the emails of every scheduled campaign that is due, run by the scheduler worker
+		DispatchDueCampaigns(ctx context.Context) error
+	}
+
+	CampaignServiceImpl struct {
+		campaignRepository repository.CampaignRepository
+		trackingTokens     tools.TrackingTokens
+	}
+
+	// recipientData is what the campaign subject and bodies are executed with
+	recipientData struct {
+		FullName  string
+		FirstName string
+		Email     string
+		AppName   string
+	}
+)
+
+var (
+	// trackedLinks are the absolute links of a rendered body, rewritten to go through the click redirect
+	trackedLinks   = regexp.MustCompile(`href="(https?://[^"]+)"`)
+	htmlLineBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+	htmlTags       = regexp.MustCompile(`<[^>]*>`)
+	blankLines     = regexp.MustCompile(`\n{3,}`)
+)
+
+func NewCampaignService(c repository.CampaignRepository, t tools.TrackingTokens) CampaignService {
+	return &CampaignServiceImpl{
+		campaignRepository: c,
+		trackingTokens:     t,
+	}
+}
+
+func (c *CampaignServiceImpl) GetCampaignList(ctx context.Context, page int) ([]model.CampaignResponse, int, error) {
+	return c.campaignRepository.GetCampaignList(ctx, page)
+}
+
+func (c *CampaignServiceImpl) GetCampaign(ctx context.Context, id string) (*model.CampaignResponse, error) {
+	result, err := c.campaignRepository.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := c.campaignRepository.GetDeliveryStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result.Delivery = stats
+
+	return result, nil
+}
+
+func (c *CampaignServiceImpl) AddCampaign(ctx context.Context, userId string, request model.CampaignRequest) (*model.CampaignResponse, error) {
+	if err := validateCampaign(request); err != nil {
+		return nil, err
+	}
+
+	return c.campaignRepository.AddCampaign(ctx, userId, request)
+}
+
+func (c *CampaignServiceImpl) UpdateCampaign(ctx context.Context, id string, request model.CampaignRequest) (*model.CampaignResponse, error) {
+	if _, err := c.campaignRepository.GetCampaign(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := validateCampaign(request); err != nil {
+		return nil, err
+	}
+
+	if err := c.campaignRepository.UpdateCampaign(ctx, id, request); err != nil {
+		return nil, err
+	}
+
+	return c.GetCampaign(ctx, id)
+}
+
+func (c *CampaignServiceImpl) PreviewCampaign(ctx context.Context, id string, request model.PreviewCampaignRequest) (*model.CampaignPreviewResponse, error) {
+	campaignData, err := c.campaignRepository.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	audienceSize, err := c.campaignRepository.CountAudience(ctx, *campaignData)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipient *model.CampaignAudienceUser
+	if request.UserId != "" {
+		if recipient, err = c.campaignRepository.GetAudienceUserById(ctx, request.UserId); err != nil {
+			return nil, err
+		}
+	} else {
+		users, err := c.campaignRepository.GetAudience(ctx, *campaignData, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("campaign audience is empty")
+		}
+		recipient = &users[0]
+	}
+
+	// previews aren't tracked, opening one must not count as a read
+	message, err := c.buildMessage(*campaignData, *recipient, false)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
+
+	message, err = mail.Render(message)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when rendering campaign preview:", err)
+		return nil, errorutils.ErrorInternalServer.CustomMessage(err.Error())
+	}
+
+	return &model.CampaignPreviewResponse{
+		Recipient:    *recipient,
+		AudienceSize: audienceSize,
+		Subject:      message.Subject,
+		HTMLBody:     message.HTMLBody,
+		TextBody:     message.TextBody,
+	}, nil
+}
+
+func (c *CampaignServiceImpl) ScheduleCampaign(ctx context.Context, id string, scheduledAt time.Time) (*model.CampaignResponse, error) {
+	if !scheduledAt.After(utils.TimeNow()) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("scheduled time must be in the future")
+	}
+
+	if err := c.campaignRepository.UpdateCampaignStatus(ctx, id, campaign.EditableStatuses(), campaign.STATUS_SCHEDULED, &scheduledAt); err != nil {
+		return nil, err
+	}
+
+	return c.GetCampaign(ctx, id)
+}
+
+func (c *CampaignServiceImpl) CancelCampaign(ctx context.Context, id string) (*model.CampaignResponse, error) {
+	if err := c.campaignRepository.UpdateCampaignStatus(ctx, id, campaign.EditableStatuses(), campaign.STATUS_CANCELLED, nil); err != nil {
+		return nil, err
+	}
+
+	return c.GetCampaign(ctx, id)
+}
+
+func (c *CampaignServiceImpl) GetRecipientList(ctx context.Context, id string, page int) ([]model.CampaignRecipientResponse, int, error) {
+	if _, err := c.campaignRepository.GetCampaign(ctx, id); err != nil {
+		return nil, 0, err
+	}
+
+	return c.campaignRepository.GetRecipientList(ctx, id, page)
+}
+
+func (c *CampaignServiceImpl) DispatchDueCampaigns(ctx context.Context) error {
+	log := logging.WithContext(ctx)
+
+	for ctx.Err() == nil {
+		dispatched, recipients, err := c.campaignRepository.DispatchDueCampaign(ctx, func(cmp model.CampaignResponse, user model.CampaignAudienceUser) (mail.Message, error) {
+			return c.buildMessage(cmp, user, c.trackingTokens.Enabled())
+		})
+		if err != nil {
+			return err
+		}
+
+		if dispatched == nil {
+			return nil
+		}
+
+		if dispatched.FailureReason != nil {
+			log.Errorf("campaign %s failed: %s", dispatched.Id, *dispatched.FailureReason)
+			continue
+		}
+
+		log.Infof("campaign %s queued for %d recipient(s)", dispatched.Id, recipients)
+	}
+
+	return nil
+}
+
+func (c *CampaignServiceImpl) GetCampaignStats(ctx context.Context, id string) (*model.CampaignStatsResponse, error) {
+	if _, err := c.campaignRepository.GetCampaign(ctx, id); err != nil {
+		return nil, err
+	}
+
+	result, err := c.campaignRepository.GetStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Delivered > 0 {
+		result.OpenRate = float64(result.Opened) / float64(result.Delivered)
+		result.ClickRate = float64(result.Clicked) / float64(result.Delivered)
+	}
+	result.TrackingEnabled = c.trackingTokens.Enabled()
+
+	return result, nil
+}
+
+func (c *CampaignServiceImpl) TrackOpen(ctx context.Context, token string) error {
+	claims, err := c.trackingTokens.Verify(token)
+	if err != nil {
+		return errorutils.ErrorNotFound
+	}
+
+	if !c.trackingTokens.Enabled() {
+		return nil
+	}
+
+	return c.campaignRepository.AddEvent(ctx, claims.CampaignId, claims.UserId, campaign.EVENT_OPEN, nil)
+}
+
+func (c *CampaignServiceImpl) TrackClick(ctx context.Context, token string) (string, error) {
+	claims, err := c.trackingTokens.Verify(token)
+	if err != nil || claims.Target == "" {
+		return "", errorutils.ErrorNotFound
+	}
+
+	if c.trackingTokens.Enabled() {
+		// the recipient is redirected anyway, a failed record must not break the link
+		c.campaignRepository.AddEvent(ctx, claims.CampaignId, claims.UserId, campaign.EVENT_CLICK, &claims.Target)
+	}
+
+	return claims.Target, nil
+}
+
+func validateCampaign(request model.CampaignRequest) error {
+	audience := campaign.Audience(request.Audience)
+	if !audience.IsValid() {
+		return errorutils.ErrorBadRequest.CustomMessage("invalid audience")
+	}
+
+	if audience == campaign.AUDIENCE_INACTIVE && (request.InactiveDays == nil || *request.InactiveDays <= 0) {
+		return errorutils.ErrorBadRequest.CustomMessage("inactive_days must be greater than 0")
+	}
+
+	// render once with placeholder data so broken templates are rejected before anything is sent
+	sample := model.CampaignResponse{
+		Subject:  request.Subject,
+		HTMLBody: request.HTMLBody,
+		TextBody: request.TextBody,
+	}
+	if _, err := renderContent(sample, model.CampaignAudienceUser{FullName: "Jane Doe", Email: "jane@example.com"}); err != nil {
+		return errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
+
+	return nil
+}
+
+// buildMessage renders the campaign for the recipient, with the open pixel and click redirects when tracked
+func (c *CampaignServiceImpl) buildMessage(cmp model.CampaignResponse, user model.CampaignAudienceUser, track bool) (mail.Message, error) {
+	message, err := renderContent(cmp, user)
+	if err != nil {
+		return message, err
+	}
+
+	if track {
+		htmlBody := trackedLinks.ReplaceAllStringFunc(message.Data["HTMLBody"].(string), func(link string) string {
+			target := html.UnescapeString(trackedLinks.FindStringSubmatch(link)[1])
+			return `href="` + html.EscapeString(c.trackingTokens.ClickURL(cmp.Id, user.Id, target)) + `"`
+		})
+		htmlBody += `<img src="` + html.EscapeString(c.trackingTokens.OpenURL(cmp.Id, user.Id)) + `" width="1" height="1" alt="" style="display:block;border:0;" />`
+		message.Data["HTMLBody"] = htmlBody
+	}
+
+	return message, nil
+}
+
+// renderContent executes the subject and bodies of the campaign for the recipient
+func renderContent(cmp model.CampaignResponse, user model.CampaignAudienceUser) (mail.Message, error) {
+	locale := i18n.Normalize(user.Locale)
+
+	data := recipientData{
+		FullName:  user.FullName,
+		FirstName: strings.SplitN(strings.TrimSpace(user.FullName), " ", 2)[0],
+		Email:     user.Email,
+		AppName:   i18n.T(locale, "app.name"),
+	}
+
+	subject, err := executeText("subject", cmp.Subject, data)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	htmlBody, err := executeHTML(cmp.HTMLBody, data)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	textBody := htmlToText(htmlBody)
+	if cmp.TextBody != nil && strings.TrimSpace(*cmp.TextBody) != "" {
+		if textBody, err = executeText("text_body", *cmp.TextBody, data); err != nil {
+			return mail.Message{}, err
+		}
+	}
+
+	return mail.Message{
+		Type:     mail.EMAIL_TYPE_CAMPAIGN,
+		Category: mail.CATEGORY_MARKETING,
+		UserId:   user.Id,
+		Locale:   locale,
+		To:       []string{user.Email},
+		Subject:  strings.TrimSpace(subject),
+		Data: mail.Data{
+			"HTMLBody": htmlBody,
+			"TextBody": textBody,
+		},
+	}, nil
+}
+
+func executeText(name, content string, data recipientData) (string, error) {
+	tmpl, err := textTemplate.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+func executeHTML(content string, data recipientData) (string, error) {
+	tmpl, err := htmlTemplate.New("html_body").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid html_body template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid html_body template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// htmlToText is the plain-text alternative of campaigns that don't have their own text body
+func htmlToText(content string) string {
+	text := htmlLineBreaks.ReplaceAllString(content, "$0\n")
+	text = htmlTags.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -1,9 +0,0 @@
-package service
-
-type (
-	EmailService interface {
-	}
-
-	EmailServiceImpl struct {
-	}
-)
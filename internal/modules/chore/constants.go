@@ -0,0 +1,52 @@
+package chore
+
+type Step string
+
+const (
+	// STEP_ROUTINE is the whole routine, the other steps are the parts of it that can be done by someone else
+	STEP_ROUTINE Step = "routine"
+	STEP_WASHING Step = "washing"
+	STEP_DRYING  Step = "drying"
+	STEP_FOLDING Step = "folding"
+)
+
+type SwapStatus string
+
+const (
+	SWAP_STATUS_PENDING   SwapStatus = "pending"
+	SWAP_STATUS_ACCEPTED  SwapStatus = "accepted"
+	SWAP_STATUS_DECLINED  SwapStatus = "declined"
+	SWAP_STATUS_CANCELLED SwapStatus = "cancelled"
+)
+
+const (
+	// DEFAULT_WORKLOAD_DAYS is how far back and ahead of today the workload summary goes by default
+	DEFAULT_WORKLOAD_DAYS = 30
+
+	// MAX_WORKLOAD_DAYS bounds the range of the workload summary
+	MAX_WORKLOAD_DAYS = 366
+)
+
+func Steps() []Step {
+	return []Step{STEP_ROUTINE, STEP_WASHING, STEP_DRYING, STEP_FOLDING}
+}
+
+func (s Step) IsValid() bool {
+	for _, step := range Steps() {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignmentKey identifies the notification of an assignment, assigning the same turn to the same member again
+// doesn't notify them twice
+func AssignmentKey(laundryId string, step Step, userId string) string {
+	return "chore-assignment:" + laundryId + ":" + string(step) + ":" + userId
+}
+
+// SwapKey identifies the notification of a swap request, or of its answer when status isn't pending
+func SwapKey(swapId string, status SwapStatus) string {
+	return "chore-swap:" + swapId + ":" + string(status)
+}
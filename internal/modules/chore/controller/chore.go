@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	ChoreController interface {
+		Assign(ctx *gin.Context)
+		Unassign(ctx *gin.Context)
+		GetRotation(ctx *gin.Context)
+		SetRotation(ctx *gin.Context)
+		DeleteRotation(ctx *gin.Context)
+		GetWorkload(ctx *gin.Context)
+		GetSwapList(ctx *gin.Context)
+		RequestSwap(ctx *gin.Context)
+		AcceptSwap(ctx *gin.Context)
+		DeclineSwap(ctx *gin.Context)
+		CancelSwap(ctx *gin.Context)
+	}
+
+	ChoreControllerImpl struct {
+		choreService service.ChoreService
+	}
+)
+
+func NewChoreController(choreService service.ChoreService) ChoreController {
+	return &ChoreControllerImpl{
+		choreService: choreService,
+	}
+}
+
+func (c *ChoreControllerImpl) Assign(ctx *gin.Context) {
+	var request model.ChoreAssignmentRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.Assign(ctx, userData.UserId, ctx.Param("id"), request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) Unassign(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.Unassign(ctx, userData.UserId, ctx.Param("id"), ctx.Param("step"))
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) GetRotation(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.GetRotation(ctx, userData.UserId, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) SetRotation(ctx *gin.Context) {
+	var request model.ChoreRotationRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.SetRotation(ctx, userData.UserId, ctx.Param("id"), request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) DeleteRotation(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := c.choreService.DeleteRotation(ctx, userData.UserId, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+// GetWorkload summarizes the turns of the household members, from and to are optional dates (YYYY-MM-DD)
+func (c *ChoreControllerImpl) GetWorkload(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	queryParam := model.ChoreWorkloadQueryParam{
+		From: ctx.Query("from"),
+		To:   ctx.Query("to"),
+	}
+
+	result, err := c.choreService.GetWorkload(ctx, userData.UserId, queryParam)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) GetSwapList(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.GetSwapList(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) RequestSwap(ctx *gin.Context) {
+	var request model.ChoreSwapRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.RequestSwap(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) AcceptSwap(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.AcceptSwap(ctx, userData.UserId, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (c *ChoreControllerImpl) DeclineSwap(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := c.choreService.DeclineSwap(ctx, userData.UserId, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// CancelSwap withdraws a pending swap request of the user
+func (c *ChoreControllerImpl) CancelSwap(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := c.choreService.CancelSwap(ctx, userData.UserId, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
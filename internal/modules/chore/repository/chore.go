@@ -0,0 +1,492 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore"
+	notificationRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// errTurnChanged is returned in a transaction when a turn of a swap isn't held by the expected member anymore
+var errTurnChanged = errors.New("turn changed")
+
+type (
+	ChoreRepository interface {
+		GetAssignments(ctx context.Context, laundryId string) ([]model.ChoreAssignmentResponse, error)
+		// Assign gives the turn to the member in place of its previous assignee and creates the notification built by
+		// notify in the same transaction. Members assigning themselves aren't notified.
+		Assign(ctx context.Context, data model.NewChoreAssignment, notify func(a model.ChoreAssignment) model.Notification) error
+		Unassign(ctx context.Context, laundryId, step string) error
+		// AssignNextTurn gives the routine generated for the series to the next member of its rotation on the given
+		// transaction, the members who left the household or only view it are passed over
+		AssignNextTurn(ctx context.Context, tx sqlx.ExtContext, seriesId, laundryId string, notify func(a model.ChoreAssignment) model.Notification) error
+		GetRotation(ctx context.Context, seriesId string) (*model.ChoreRotationResponse, error)
+		// SetRotation replaces the members of the rotation of the series, the first one gets the next turn
+		SetRotation(ctx context.Context, seriesId, step string, memberIds []string) error
+		DeleteRotation(ctx context.Context, seriesId string) error
+		// GetWorkload counts the turns of the members of the household on the routines planned between from and to,
+		// to excluded
+		GetWorkload(ctx context.Context, householdId string, from, to time.Time) ([]model.ChoreWorkloadRow, error)
+		// AddSwap stores the swap request and creates the notification of the member it's sent to in the same transaction
+		AddSwap(ctx context.Context, data model.NewChoreSwap, notify func(s model.ChoreSwapResponse) model.Notification) (string, error)
+		GetSwap(ctx context.Context, id string) (*model.ChoreSwapResponse, error)
+		// GetSwapList returns the pending swap requests sent by or to the user
+		GetSwapList(ctx context.Context, userId string) ([]model.ChoreSwapResponse, error)
+		// RespondSwap answers a pending swap request sent to the user. Accepting it hands the turn to the user, and the
+		// turn given in exchange to the requester. The requester is notified of the answer.
+		RespondSwap(ctx context.Context, id, userId string, status chore.SwapStatus, notify func(s model.ChoreSwapResponse) model.Notification) error
+		CancelSwap(ctx context.Context, id, userId string) error
+	}
+
+	ChoreRepositoryImpl struct {
+		db                     database.DBCollection
+		notificationRepository notificationRepository.NotificationRepository
+	}
+)
+
+func NewChoreRepository(db database.DBCollection, n notificationRepository.NotificationRepository) ChoreRepository {
+	return &ChoreRepositoryImpl{
+		db:                     db,
+		notificationRepository: n,
+	}
+}
+
+func (c *ChoreRepositoryImpl) GetAssignments(ctx context.Context, laundryId string) ([]model.ChoreAssignmentResponse, error) {
+	query, args := SelectAssignments().
+		Where(squirrel.Eq{"la.laundry_id": laundryId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result := []model.ChoreAssignmentResponse{}
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting chore assignments:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (c *ChoreRepositoryImpl) Assign(ctx context.Context, data model.NewChoreAssignment, notify func(a model.ChoreAssignment) model.Notification) error {
+	err := database.WithTransaction(ctx, c.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		return c.assign(ctx, tx, data, notify)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when assigning chore:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (c *ChoreRepositoryImpl) Unassign(ctx context.Context, laundryId, step string) error {
+	query, args := squirrel.Delete("laundry_assignments").
+		Where(squirrel.Eq{"laundry_id": laundryId, "step": step}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := c.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when unassigning chore:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (c *ChoreRepositoryImpl) AssignNextTurn(ctx context.Context, tx sqlx.ExtContext, seriesId, laundryId string, notify func(a model.ChoreAssignment) model.Notification) error {
+	query, args := squirrel.Select("series_id, step, next_turn").
+		From("laundry_series_rotations").
+		Where(squirrel.Eq{"series_id": seriesId}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var rotation model.ChoreRotationResponse
+	if err := sqlx.GetContext(ctx, tx, &rotation, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	// a member can take the turn while they can change the routines of the household the routine is shared with
+	canTakeTurn := squirrel.Select("1").
+		From("household_members hm").
+		Join("laundries l ON l.household_id = hm.household_id").
+		Where(squirrel.Eq{"l.id": laundryId, "hm.role": constants.HouseholdEditorRoles()}).
+		Where("hm.user_id = rm.user_id")
+
+	queryMembers, args := squirrel.Select("rm.user_id").
+		Column(squirrel.Alias(squirrel.Expr("EXISTS (?)", canTakeTurn), "eligible")).
+		From("laundry_series_rotation_members rm").
+		Where(squirrel.Eq{"rm.series_id": seriesId}).
+		OrderBy("rm.position").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var members []struct {
+		UserId   string `db:"user_id"`
+		Eligible bool   `db:"eligible"`
+	}
+	if err := sqlx.SelectContext(ctx, tx, &members, queryMembers, args...); err != nil {
+		return err
+	}
+
+	for i := range members {
+		turn := (rotation.NextTurn + i) % len(members)
+		if !members[turn].Eligible {
+			continue
+		}
+
+		queryUpdate, args := squirrel.Update("laundry_series_rotations").
+			Set("next_turn", (turn+1)%len(members)).
+			Set("updated_at", utils.TimeNow()).
+			Where(squirrel.Eq{"series_id": seriesId}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, queryUpdate, args...); err != nil {
+			return err
+		}
+
+		return c.assign(ctx, tx, model.NewChoreAssignment{
+			LaundryId: laundryId,
+			Step:      rotation.Step,
+			UserId:    members[turn].UserId,
+		}, notify)
+	}
+
+	return nil
+}
+
+func (c *ChoreRepositoryImpl) GetRotation(ctx context.Context, seriesId string) (*model.ChoreRotationResponse, error) {
+	log := logging.WithContext(ctx)
+
+	query, args := squirrel.Select("series_id, step, next_turn").
+		From("laundry_series_rotations").
+		Where(squirrel.Eq{"series_id": seriesId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.ChoreRotationResponse
+	if err := c.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Error("error when getting chore rotation:", err)
+		}
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryMembers, args := squirrel.Select("rm.user_id, u.full_name, rm.position").
+		From("laundry_series_rotation_members rm").
+		Join("users u ON u.id = rm.user_id").
+		Where(squirrel.Eq{"rm.series_id": seriesId}).
+		OrderBy("rm.position").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result.Members = []model.ChoreRotationMember{}
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result.Members, queryMembers, args...); err != nil {
+		log.Error("error when getting chore rotation members:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (c *ChoreRepositoryImpl) SetRotation(ctx context.Context, seriesId, step string, memberIds []string) error {
+	err := database.WithTransaction(ctx, c.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		currentTime := utils.TimeNow()
+
+		query, args := squirrel.Insert("laundry_series_rotations").
+			Columns("series_id", "step", "next_turn", "created_at", "updated_at").
+			Values(seriesId, step, 0, currentTime, currentTime).
+			Suffix("ON CONFLICT (series_id) DO UPDATE SET step = EXCLUDED.step, next_turn = 0, updated_at = EXCLUDED.updated_at").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		queryDelete, args := squirrel.Delete("laundry_series_rotation_members").
+			Where(squirrel.Eq{"series_id": seriesId}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, queryDelete, args...); err != nil {
+			return err
+		}
+
+		insertMembers := squirrel.Insert("laundry_series_rotation_members").
+			Columns("series_id", "user_id", "position")
+		for i, memberId := range memberIds {
+			insertMembers = insertMembers.Values(seriesId, memberId, i)
+		}
+
+		queryMembers, args := insertMembers.PlaceholderFormat(squirrel.Dollar).MustSql()
+		_, err := tx.ExecContext(ctx, queryMembers, args...)
+		return err
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when setting chore rotation:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (c *ChoreRepositoryImpl) DeleteRotation(ctx context.Context, seriesId string) error {
+	query, args := squirrel.Delete("laundry_series_rotations").
+		Where(squirrel.Eq{"series_id": seriesId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := c.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when deleting chore rotation:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (c *ChoreRepositoryImpl) GetWorkload(ctx context.Context, householdId string, from, to time.Time) ([]model.ChoreWorkloadRow, error) {
+	query, args := squirrel.Select("la.user_id, u.full_name, la.step, l.status, COUNT(*) AS total").
+		From("laundry_assignments la").
+		Join("laundries l ON l.id = la.laundry_id").
+		Join("users u ON u.id = la.user_id").
+		Where(squirrel.Eq{"l.household_id": householdId}).
+		Where(squirrel.NotEq{"l.status": constants.LAUNDRY_STATUS_CANCELLED}).
+		Where(squirrel.GtOrEq{"l.laundry_date": from}).
+		Where(squirrel.Lt{"l.laundry_date": to}).
+		GroupBy("la.user_id", "u.full_name", "la.step", "l.status").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result := []model.ChoreWorkloadRow{}
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting chore workload:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (c *ChoreRepositoryImpl) AddSwap(ctx context.Context, data model.NewChoreSwap, notify func(s model.ChoreSwapResponse) model.Notification) (string, error) {
+	id := utils.GenerateCleanUUID()
+
+	err := database.WithTransaction(ctx, c.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Insert("chore_swaps").
+			Columns("id", "laundry_id", "step", "requested_by", "requested_to", "exchange_laundry_id", "exchange_step", "status", "created_at").
+			Values(id, data.LaundryId, data.Step, data.RequestedBy, data.RequestedTo, data.ExchangeLaundryId, data.ExchangeStep, chore.SWAP_STATUS_PENDING, utils.TimeNow()).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		swap, err := getSwap(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		return c.notificationRepository.AddNotification(ctx, tx, notify(*swap))
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when adding chore swap:", err)
+		return "", errorutils.DefineSQLError(err)
+	}
+
+	return id, nil
+}
+
+func (c *ChoreRepositoryImpl) GetSwap(ctx context.Context, id string) (*model.ChoreSwapResponse, error) {
+	result, err := getSwap(ctx, c.db.PostgresDBSqlx, id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logging.WithContext(ctx).Error("error when getting chore swap:", err)
+		}
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (c *ChoreRepositoryImpl) GetSwapList(ctx context.Context, userId string) ([]model.ChoreSwapResponse, error) {
+	query, args := selectSwaps().
+		Where(squirrel.Eq{"s.status": chore.SWAP_STATUS_PENDING}).
+		Where(squirrel.Or{squirrel.Eq{"s.requested_by": userId}, squirrel.Eq{"s.requested_to": userId}}).
+		OrderBy("s.created_at DESC", "s.id").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result := []model.ChoreSwapResponse{}
+	if err := c.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting chore swap list:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (c *ChoreRepositoryImpl) RespondSwap(ctx context.Context, id, userId string, status chore.SwapStatus, notify func(s model.ChoreSwapResponse) model.Notification) error {
+	err := database.WithTransaction(ctx, c.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		currentTime := utils.TimeNow()
+
+		query, args := squirrel.Update("chore_swaps").
+			Set("status", status).
+			Set("responded_at", currentTime).
+			Where(squirrel.Eq{"id": id, "requested_to": userId, "status": chore.SWAP_STATUS_PENDING}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		swap, err := getSwap(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if status == chore.SWAP_STATUS_ACCEPTED {
+			if err := handOver(ctx, tx, swap.LaundryId, swap.Step, swap.RequestedBy, swap.RequestedTo); err != nil {
+				return err
+			}
+
+			if swap.ExchangeLaundryId != nil && swap.ExchangeStep != nil {
+				if err := handOver(ctx, tx, *swap.ExchangeLaundryId, *swap.ExchangeStep, swap.RequestedTo, swap.RequestedBy); err != nil {
+					return err
+				}
+			}
+		}
+
+		return c.notificationRepository.AddNotification(ctx, tx, notify(*swap))
+	})
+	if errors.Is(err, errTurnChanged) {
+		return errorutils.ErrorBadRequest.CustomMessage("the turn was given to someone else in the meantime")
+	}
+	if err != nil {
+		logging.WithContext(ctx).Error("error when responding to chore swap:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (c *ChoreRepositoryImpl) CancelSwap(ctx context.Context, id, userId string) error {
+	query, args := squirrel.Update("chore_swaps").
+		Set("status", chore.SWAP_STATUS_CANCELLED).
+		Set("responded_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id, "requested_by": userId, "status": chore.SWAP_STATUS_PENDING}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := c.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when cancelling chore swap:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+// SelectAssignments selects the assignees of the routines, the assignments are aliased as la
+func SelectAssignments() squirrel.SelectBuilder {
+	return squirrel.Select("la.laundry_id, la.step, la.user_id, u.full_name, la.updated_at").
+		From("laundry_assignments la").
+		Join("users u ON u.id = la.user_id").
+		OrderBy("la.created_at", "la.step")
+}
+
+// assign stores the assignment on the given transaction and notifies the assignee, unless they assigned themselves
+func (c *ChoreRepositoryImpl) assign(ctx context.Context, tx sqlx.ExtContext, data model.NewChoreAssignment, notify func(a model.ChoreAssignment) model.Notification) error {
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Insert("laundry_assignments").
+		Columns("laundry_id", "step", "user_id", "assigned_by", "created_at", "updated_at").
+		Values(data.LaundryId, data.Step, data.UserId, data.AssignedBy, currentTime, currentTime).
+		Suffix("ON CONFLICT (laundry_id, step) DO UPDATE SET user_id = EXCLUDED.user_id, assigned_by = EXCLUDED.assigned_by, updated_at = EXCLUDED.updated_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	if data.AssignedBy != nil && *data.AssignedBy == data.UserId {
+		return nil
+	}
+
+	queryAssignment, args := squirrel.Select("la.laundry_id, l.title AS laundry_title, l.laundry_date, la.step, la.user_id, u.locale").
+		From("laundry_assignments la").
+		Join("laundries l ON l.id = la.laundry_id").
+		Join("users u ON u.id = la.user_id").
+		Where(squirrel.Eq{"la.laundry_id": data.LaundryId, "la.step": data.Step}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var assignment model.ChoreAssignment
+	if err := sqlx.GetContext(ctx, tx, &assignment, queryAssignment, args...); err != nil {
+		return err
+	}
+
+	return c.notificationRepository.AddNotification(ctx, tx, notify(assignment))
+}
+
+// handOver moves the turn from a member to another, errTurnChanged when the turn isn't the first member's anymore
+func handOver(ctx context.Context, tx sqlx.ExtContext, laundryId, step, from, to string) error {
+	query, args := squirrel.Update("laundry_assignments").
+		Set("user_id", to).
+		Set("assigned_by", from).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"laundry_id": laundryId, "step": step, "user_id": from}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errTurnChanged
+	}
+
+	return nil
+}
+
+func getSwap(ctx context.Context, db sqlx.QueryerContext, id string) (*model.ChoreSwapResponse, error) {
+	query, args := selectSwaps().
+		Where(squirrel.Eq{"s.id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.ChoreSwapResponse
+	if err := sqlx.GetContext(ctx, db, &result, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func selectSwaps() squirrel.SelectBuilder {
+	return squirrel.Select("s.id, s.laundry_id, l.title AS laundry_title, l.laundry_date, s.step",
+		"s.requested_by, rb.full_name AS requested_by_name, rb.locale AS requested_by_locale",
+		"s.requested_to, rt.full_name AS requested_to_name, rt.locale AS requested_to_locale",
+		"s.exchange_laundry_id, el.title AS exchange_laundry_title, s.exchange_step, s.status, s.created_at, s.responded_at").
+		From("chore_swaps s").
+		Join("laundries l ON l.id = s.laundry_id").
+		Join("users rb ON rb.id = s.requested_by").
+		Join("users rt ON rt.id = s.requested_to").
+		LeftJoin("laundries el ON el.id = s.exchange_laundry_id")
+}
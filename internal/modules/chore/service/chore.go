@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/repository"
+	householdRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household/repository"
+	laundryService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification"
+	seriesRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"strings"
+	"time"
+)
+
+type (
+	ChoreService interface {
+		// Assign gives a turn of a routine of the household to one of its members, the assignee is notified
+		Assign(ctx context.Context, userId, laundryId string, request model.ChoreAssignmentRequest) (*model.LaundryDetailResponse, error)
+		Unassign(ctx context.Context, userId, laundryId, step string) (*model.LaundryDetailResponse, error)
+		GetRotation(ctx context.Context, userId, seriesId string) (*model.ChoreRotationResponse, error)
+		// SetRotation makes the routines generated for the series of the user go to the given members in turn
+		SetRotation(ctx context.Context, userId, seriesId string, request model.ChoreRotationRequest) (*model.ChoreRotationResponse, error)
+		DeleteRotation(ctx context.Context, userId, seriesId string) error
+		// GetWorkload summarizes the turns of every member of the household of the user over a date range
+		GetWorkload(ctx context.Context, userId string, queryParam model.ChoreWorkloadQueryParam) (*model.ChoreWorkloadResponse, error)
+		// GetSwapList returns the pending swap requests the user sent or has to answer
+		GetSwapList(ctx context.Context, userId string) ([]model.ChoreSwapResponse, error)
+		// RequestSwap asks another member to take a turn of the user, the turn only changes hands once they accept
+		RequestSwap(ctx context.Context, userId string, request model.ChoreSwapRequest) (*model.ChoreSwapResponse, error)
+		AcceptSwap(ctx context.Context, userId, id string) (*model.ChoreSwapResponse, error)
+		DeclineSwap(ctx context.Context, userId, id string) (*model.ChoreSwapResponse, error)
+		CancelSwap(ctx context.Context, userId, id string) error
+		// AssignmentNotification tells the assignee of a turn that it's theirs, in their locale
+		AssignmentNotification(a model.ChoreAssignment) model.Notification
+	}
+
+	ChoreServiceImpl struct {
+		choreRepository     repository.ChoreRepository
+		householdRepository householdRepository.HouseholdRepository
+		seriesRepository    seriesRepository.SeriesRepository
+		laundryService      laundryService.LaundryService
+	}
+)
+
+func NewChoreService(c repository.ChoreRepository, h householdRepository.HouseholdRepository, s seriesRepository.SeriesRepository, l laundryService.LaundryService) ChoreService {
+	return &ChoreServiceImpl{
+		choreRepository:     c,
+		householdRepository: h,
+		seriesRepository:    s,
+		laundryService:      l,
+	}
+}
+
+func (c *ChoreServiceImpl) Assign(ctx context.Context, userId, laundryId string, request model.ChoreAssignmentRequest) (*model.LaundryDetailResponse, error) {
+	laundry, err := c.editableLaundry(ctx, userId, laundryId)
+	if err != nil {
+		return nil, err
+	}
+
+	household, err := c.household(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateShared(laundry, household.Id); err != nil {
+		return nil, err
+	}
+
+	if err := validateAssignees(household.Members, request.UserId); err != nil {
+		return nil, err
+	}
+
+	err = c.choreRepository.Assign(ctx, model.NewChoreAssignment{
+		LaundryId:  laundryId,
+		Step:       request.Step,
+		UserId:     request.UserId,
+		AssignedBy: &userId,
+	}, c.AssignmentNotification)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.laundryService.GetLaundryDetail(ctx, laundryId, userId)
+}
+
+func (c *ChoreServiceImpl) Unassign(ctx context.Context, userId, laundryId, step string) (*model.LaundryDetailResponse, error) {
+	if !chore.Step(step).IsValid() {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid step")
+	}
+
+	if _, err := c.editableLaundry(ctx, userId, laundryId); err != nil {
+		return nil, err
+	}
+
+	if err := c.choreRepository.Unassign(ctx, laundryId, step); err != nil {
+		return nil, err
+	}
+
+	return c.laundryService.GetLaundryDetail(ctx, laundryId, userId)
+}
+
+func (c *ChoreServiceImpl) GetRotation(ctx context.Context, userId, seriesId string) (*model.ChoreRotationResponse, error) {
+	if _, err := c.seriesRepository.GetSeries(ctx, seriesId, userId); err != nil {
+		return nil, err
+	}
+
+	result, err := c.choreRepository.GetRotation(ctx, seriesId)
+	if err != nil {
+		return nil, err
+	}
+
+	result.StepLabel = model.ChoreStepLabel(i18n.LocaleFromContext(ctx), result.Step)
+	if len(result.Members) > 0 {
+		result.NextUserId = &result.Members[result.NextTurn%len(result.Members)].UserId
+	}
+
+	return result, nil
+}
+
+func (c *ChoreServiceImpl) SetRotation(ctx context.Context, userId, seriesId string, request model.ChoreRotationRequest) (*model.ChoreRotationResponse, error) {
+	if _, err := c.seriesRepository.GetSeries(ctx, seriesId, userId); err != nil {
+		return nil, err
+	}
+
+	household, err := c.household(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAssignees(household.Members, request.MemberIds...); err != nil {
+		return nil, err
+	}
+
+	if err := c.choreRepository.SetRotation(ctx, seriesId, request.Step, request.MemberIds); err != nil {
+		return nil, err
+	}
+
+	return c.GetRotation(ctx, userId, seriesId)
+}
+
+func (c *ChoreServiceImpl) DeleteRotation(ctx context.Context, userId, seriesId string) error {
+	if _, err := c.seriesRepository.GetSeries(ctx, seriesId, userId); err != nil {
+		return err
+	}
+
+	return c.choreRepository.DeleteRotation(ctx, seriesId)
+}
+
+func (c *ChoreServiceImpl) GetWorkload(ctx context.Context, userId string, queryParam model.ChoreWorkloadQueryParam) (*model.ChoreWorkloadResponse, error) {
+	membership, err := c.householdRepository.GetMembership(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.TimeNow()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := today.AddDate(0, 0, -chore.DEFAULT_WORKLOAD_DAYS)
+	to := today.AddDate(0, 0, chore.DEFAULT_WORKLOAD_DAYS)
+
+	if value := strings.TrimSpace(queryParam.From); value != "" {
+		if from, err = time.Parse(constants.FORMAT_DATE_DEFAULT, value); err != nil {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+		}
+	}
+
+	if value := strings.TrimSpace(queryParam.To); value != "" {
+		if to, err = time.Parse(constants.FORMAT_DATE_DEFAULT, value); err != nil {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+		}
+	}
+
+	if from.After(to) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+	}
+	if to.Sub(from) >= chore.MAX_WORKLOAD_DAYS*24*time.Hour {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the date range is too long")
+	}
+
+	householdData, err := c.householdRepository.GetHousehold(ctx, membership.HouseholdId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.choreRepository.GetWorkload(ctx, membership.HouseholdId, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ChoreWorkloadResponse{
+		From:    from.Format(constants.FORMAT_DATE_DEFAULT),
+		To:      to.Format(constants.FORMAT_DATE_DEFAULT),
+		Members: []model.ChoreMemberWorkload{},
+	}
+
+	// every member is listed, even without turns, followed by the former members who still have some
+	indexes := map[string]int{}
+	addMember := func(memberId, fullName string) int {
+		if i, ok := indexes[memberId]; ok {
+			return i
+		}
+
+		steps := map[string]int{}
+		for _, step := range chore.Steps() {
+			steps[string(step)] = 0
+		}
+
+		result.Members = append(result.Members, model.ChoreMemberWorkload{UserId: memberId, FullName: fullName, Steps: steps})
+		indexes[memberId] = len(result.Members) - 1
+		return indexes[memberId]
+	}
+
+	for _, member := range householdData.Members {
+		addMember(member.UserId, member.FullName)
+	}
+
+	for _, row := range rows {
+		workload := &result.Members[addMember(row.UserId, row.FullName)]
+		workload.Total += row.Total
+		workload.Steps[row.Step] += row.Total
+
+		if constants.LaundryStatus(row.Status) == constants.LAUNDRY_STATUS_DONE {
+			workload.Done += row.Total
+		} else {
+			workload.Pending += row.Total
+		}
+	}
+
+	return result, nil
+}
+
+func (c *ChoreServiceImpl) GetSwapList(ctx context.Context, userId string) ([]model.ChoreSwapResponse, error) {
+	result, err := c.choreRepository.GetSwapList(ctx, userId)
+	if err != nil {
+		return result, err
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+	for i := range result {
+		result[i].FillDisplayFields(locale)
+	}
+
+	return result, nil
+}
+
+func (c *ChoreServiceImpl) RequestSwap(ctx context.Context, userId string, request model.ChoreSwapRequest) (*model.ChoreSwapResponse, error) {
+	if (request.ExchangeLaundryId == nil) != (request.ExchangeStep == nil) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the turn given in exchange needs both a routine and a step")
+	}
+
+	if request.UserId == userId {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("you can't swap a turn with yourself")
+	}
+
+	household, err := c.household(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAssignees(household.Members, request.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTurn(ctx, userId, household.Id, request.LaundryId, request.Step, userId); err != nil {
+		return nil, err
+	}
+
+	if request.ExchangeLaundryId != nil {
+		if err := c.validateTurn(ctx, userId, household.Id, *request.ExchangeLaundryId, *request.ExchangeStep, request.UserId); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := c.choreRepository.AddSwap(ctx, model.NewChoreSwap{
+		LaundryId:         request.LaundryId,
+		Step:              request.Step,
+		RequestedBy:       userId,
+		RequestedTo:       request.UserId,
+		ExchangeLaundryId: request.ExchangeLaundryId,
+		ExchangeStep:      request.ExchangeStep,
+	}, swapNotification)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getSwap(ctx, id)
+}
+
+func (c *ChoreServiceImpl) AcceptSwap(ctx context.Context, userId, id string) (*model.ChoreSwapResponse, error) {
+	swap, err := c.choreRepository.GetSwap(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.RequestedTo != userId {
+		return nil, errorutils.ErrorNotFound
+	}
+
+	household, err := c.household(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the routines may have been moved out of the household since the swap was requested
+	if _, err := c.sharedLaundry(ctx, userId, household.Id, swap.LaundryId); err != nil {
+		return nil, err
+	}
+
+	if swap.ExchangeLaundryId != nil {
+		if _, err := c.sharedLaundry(ctx, userId, household.Id, *swap.ExchangeLaundryId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.choreRepository.RespondSwap(ctx, id, userId, chore.SWAP_STATUS_ACCEPTED, swapNotification); err != nil {
+		return nil, err
+	}
+
+	return c.getSwap(ctx, id)
+}
+
+func (c *ChoreServiceImpl) DeclineSwap(ctx context.Context, userId, id string) (*model.ChoreSwapResponse, error) {
+	if err := c.choreRepository.RespondSwap(ctx, id, userId, chore.SWAP_STATUS_DECLINED, swapNotification); err != nil {
+		return nil, err
+	}
+
+	return c.getSwap(ctx, id)
+}
+
+func (c *ChoreServiceImpl) CancelSwap(ctx context.Context, userId, id string) error {
+	return c.choreRepository.CancelSwap(ctx, id, userId)
+}
+
+func (c *ChoreServiceImpl) AssignmentNotification(a model.ChoreAssignment) model.Notification {
+	locale := i18n.Normalize(a.Locale)
+
+	return model.Notification{
+		UserId:    a.UserId,
+		Type:      string(notification.TYPE_ASSIGNMENT),
+		Title:     i18n.T(locale, "notification.assignment.title", a.LaundryTitle),
+		Body:      i18n.T(locale, "notification.assignment.body", model.ChoreStepLabel(locale, a.Step), i18n.FormatDate(locale, a.LaundryDate)),
+		LaundryId: &a.LaundryId,
+		SourceKey: chore.AssignmentKey(a.LaundryId, chore.Step(a.Step), a.UserId),
+	}
+}
+
+// swapNotification tells the member asked to take the turn about the request, and the requester about the answer
+func swapNotification(s model.ChoreSwapResponse) model.Notification {
+	recipient, locale, title := s.RequestedTo, i18n.Normalize(s.RequestedToLocale), ""
+	switch chore.SwapStatus(s.Status) {
+	case chore.SWAP_STATUS_ACCEPTED:
+		recipient, locale = s.RequestedBy, i18n.Normalize(s.RequestedByLocale)
+		title = i18n.T(locale, "notification.swap_accepted.title", s.RequestedToName)
+	case chore.SWAP_STATUS_DECLINED:
+		recipient, locale = s.RequestedBy, i18n.Normalize(s.RequestedByLocale)
+		title = i18n.T(locale, "notification.swap_declined.title", s.RequestedToName)
+	default:
+		title = i18n.T(locale, "notification.swap_request.title", s.RequestedByName)
+	}
+
+	body := i18n.T(locale, "notification.swap.turn", s.LaundryTitle, model.ChoreStepLabel(locale, s.Step), i18n.FormatDate(locale, s.LaundryDate))
+	if s.ExchangeLaundryTitle != nil && s.ExchangeStep != nil {
+		body += " " + i18n.T(locale, "notification.swap.exchange", *s.ExchangeLaundryTitle, model.ChoreStepLabel(locale, *s.ExchangeStep))
+	}
+
+	return model.Notification{
+		UserId:    recipient,
+		Type:      string(notification.TYPE_SWAP),
+		Title:     title,
+		Body:      body,
+		LaundryId: &s.LaundryId,
+		SourceKey: chore.SwapKey(s.Id, chore.SwapStatus(s.Status)),
+	}
+}
+
+func (c *ChoreServiceImpl) getSwap(ctx context.Context, id string) (*model.ChoreSwapResponse, error) {
+	result, err := c.choreRepository.GetSwap(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result.FillDisplayFields(i18n.LocaleFromContext(ctx))
+
+	return result, nil
+}
+
+// editableLaundry returns the routine, which the user must be allowed to change
+func (c *ChoreServiceImpl) editableLaundry(ctx context.Context, userId, laundryId string) (*model.LaundryDetailResponse, error) {
+	result, err := c.laundryService.GetLaundryDetail(ctx, laundryId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.CanEdit {
+		return nil, errorutils.ErrorForbidden.CustomMessage("your household role can't change this routine")
+	}
+
+	return result, nil
+}
+
+// household returns the household of the user with its members, the chores are only shared within a household
+func (c *ChoreServiceImpl) household(ctx context.Context, userId string) (*model.HouseholdResponse, error) {
+	membership, err := c.householdRepository.GetMembership(ctx, userId)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the chores are shared within a household, create or join one first")
+	}
+
+	return c.householdRepository.GetHousehold(ctx, membership.HouseholdId, false)
+}
+
+// sharedLaundry returns the routine, which the user must see and which must be shared with the household
+func (c *ChoreServiceImpl) sharedLaundry(ctx context.Context, userId, householdId, laundryId string) (*model.LaundryDetailResponse, error) {
+	result, err := c.laundryService.GetLaundryDetail(ctx, laundryId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateShared(result, householdId); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// validateTurn checks that the step of a routine of the household is held by the member and still has to be done
+func (c *ChoreServiceImpl) validateTurn(ctx context.Context, userId, householdId, laundryId, step, holderId string) error {
+	laundry, err := c.sharedLaundry(ctx, userId, householdId, laundryId)
+	if err != nil {
+		return err
+	}
+
+	if len(constants.LaundryStatus(laundry.Status).NextStatuses()) == 0 {
+		return errorutils.ErrorBadRequest.CustomMessage("the routine is already over")
+	}
+
+	for _, assignment := range laundry.Assignments {
+		if assignment.Step == step && assignment.UserId == holderId {
+			return nil
+		}
+	}
+
+	return errorutils.ErrorBadRequest.CustomMessage("the turn isn't assigned to this member")
+}
+
+// validateShared checks that the routine is shared with the household, the personal routines have no turns
+func validateShared(laundry *model.LaundryDetailResponse, householdId string) error {
+	if laundry.HouseholdId == nil || *laundry.HouseholdId != householdId {
+		return errorutils.ErrorBadRequest.CustomMessage("the routine isn't shared with your household")
+	}
+
+	return nil
+}
+
+// validateAssignees checks that the users are distinct members of the household who can change its routines
+func validateAssignees(members []model.HouseholdMemberResponse, userIds ...string) error {
+	roles := map[string]constants.HouseholdRole{}
+	for _, member := range members {
+		roles[member.UserId] = member.Role
+	}
+
+	seen := map[string]bool{}
+	for _, userId := range userIds {
+		role, ok := roles[userId]
+		if !ok {
+			return errorutils.ErrorBadRequest.CustomMessage("member not found")
+		}
+
+		if !role.CanEdit() {
+			return errorutils.ErrorBadRequest.CustomMessage("viewers can't take turns")
+		}
+
+		if seen[userId] {
+			return errorutils.ErrorBadRequest.CustomMessage("a member can only appear once in the rotation")
+		}
+		seen[userId] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAssignees(t *testing.T) {
+	members := []model.HouseholdMemberResponse{
+		{UserId: "alice", Role: constants.HOUSEHOLD_ROLE_OWNER},
+		{UserId: "bob", Role: constants.HOUSEHOLD_ROLE_MEMBER},
+		{UserId: "carol", Role: constants.HOUSEHOLD_ROLE_VIEWER},
+	}
+
+	tests := []struct {
+		name       string
+		userIds    []string
+		wantStatus int
+	}{
+		{name: "owner", userIds: []string{"alice"}, wantStatus: http.StatusOK},
+		{name: "member", userIds: []string{"bob"}, wantStatus: http.StatusOK},
+		{name: "rotation", userIds: []string{"bob", "alice"}, wantStatus: http.StatusOK},
+		{name: "viewer", userIds: []string{"carol"}, wantStatus: http.StatusBadRequest},
+		{name: "viewer in rotation", userIds: []string{"alice", "carol"}, wantStatus: http.StatusBadRequest},
+		{name: "not a member", userIds: []string{"dave"}, wantStatus: http.StatusBadRequest},
+		{name: "repeated member", userIds: []string{"alice", "bob", "alice"}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAssignees(members, tt.userIds...)
+			if status, message := errorutils.GetStatusCode(err); status != tt.wantStatus {
+				t.Errorf("status = %d (%s), want %d", status, message, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestValidateShared(t *testing.T) {
+	household, otherHousehold := "household-1", "household-2"
+
+	tests := []struct {
+		name        string
+		householdId *string
+		wantStatus  int
+	}{
+		{name: "shared with the household", householdId: &household, wantStatus: http.StatusOK},
+		{name: "personal routine", wantStatus: http.StatusBadRequest},
+		{name: "shared with another household", householdId: &otherHousehold, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateShared(&model.LaundryDetailResponse{HouseholdId: tt.householdId}, household)
+			if status, message := errorutils.GetStatusCode(err); status != tt.wantStatus {
+				t.Errorf("status = %d (%s), want %d", status, message, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSwapNotification(t *testing.T) {
+	exchangeTitle, exchangeStep := "Towels", string(chore.STEP_FOLDING)
+
+	tests := []struct {
+		name          string
+		status        chore.SwapStatus
+		exchange      bool
+		wantRecipient string
+		wantTitle     string
+		wantBody      string
+	}{
+		{
+			name:          "requested",
+			status:        chore.SWAP_STATUS_PENDING,
+			wantRecipient: "bob",
+			wantTitle:     "Alice asks you to take their turn",
+			wantBody:      "Weekly (Washing) on ",
+		},
+		{
+			name:          "requested with an exchange",
+			status:        chore.SWAP_STATUS_PENDING,
+			exchange:      true,
+			wantRecipient: "bob",
+			wantTitle:     "Alice asks you to take their turn",
+			wantBody:      "In exchange: Towels (Folding).",
+		},
+		{
+			name:          "accepted",
+			status:        chore.SWAP_STATUS_ACCEPTED,
+			wantRecipient: "alice",
+			wantTitle:     "Bob accepted to swap your turn",
+		},
+		{
+			name:          "declined",
+			status:        chore.SWAP_STATUS_DECLINED,
+			wantRecipient: "alice",
+			wantTitle:     "Bob declined to swap your turn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swap := model.ChoreSwapResponse{
+				Id:                "swap-1",
+				LaundryId:         "laundry-1",
+				LaundryTitle:      "Weekly",
+				LaundryDate:       time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+				Step:              string(chore.STEP_WASHING),
+				RequestedBy:       "alice",
+				RequestedByName:   "Alice",
+				RequestedByLocale: "en",
+				RequestedTo:       "bob",
+				RequestedToName:   "Bob",
+				RequestedToLocale: "en",
+				Status:            string(tt.status),
+			}
+			if tt.exchange {
+				swap.ExchangeLaundryTitle, swap.ExchangeStep = &exchangeTitle, &exchangeStep
+			}
+
+			got := swapNotification(swap)
+			if got.UserId != tt.wantRecipient {
+				t.Errorf("recipient = %q, want %q", got.UserId, tt.wantRecipient)
+			}
+			if got.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got.Title, tt.wantTitle)
+			}
+			if !strings.Contains(got.Body, tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", got.Body, tt.wantBody)
+			}
+			if want := chore.SwapKey(swap.Id, tt.status); got.SourceKey != want {
+				t.Errorf("source key = %q, want %q", got.SourceKey, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package digest
+
+const (
+	// SEND_HOUR is the local hour on Monday from which the weekly digest of a user is sent
+	SEND_HOUR = 8
+
+	// DISPATCH_INTERVAL_MINUTES is how often the scheduler looks for users whose digest is due
+	DISPATCH_INTERVAL_MINUTES = 15
+
+	// BATCH_SIZE is how many users are picked up at a time
+	BATCH_SIZE = 50
+
+	DEFAULT_TIMEZONE = "Asia/Jakarta"
+)
+
+// OutboxKey is the idempotency key of the weekly digest of a user in the email outbox, weekStart is a YYYY-MM-DD date
+func OutboxKey(userId, weekStart string) string {
+	return "weekly_digest:" + userId + ":" + weekStart
+}
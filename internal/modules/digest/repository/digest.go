@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/digest"
+	outboxRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type (
+	DigestRepository interface {
+		// GetDueRecipients returns the users who opted in to the digest, for whom it's Monday morning
+		// at the given time, and whose digest of the current week hasn't been queued yet
+		GetDueRecipients(ctx context.Context, now time.Time, limit int) ([]model.DigestRecipient, error)
+		// QueueDigest records the digest of the week and queues its email in one transaction,
+		// a week that was already recorded is skipped and false is returned
+		QueueDigest(ctx context.Context, userId string, weekStart string, message mail.Message) (bool, error)
+	}
+
+	DigestRepositoryImpl struct {
+		db               database.DBCollection
+		outboxRepository outboxRepository.OutboxRepository
+	}
+)
+
+func NewDigestRepository(db database.DBCollection, o outboxRepository.OutboxRepository) DigestRepository {
+	return &DigestRepositoryImpl{
+		db:               db,
+		outboxRepository: o,
+	}
+}
+
+func (d *DigestRepositoryImpl) GetDueRecipients(ctx context.Context, now time.Time, limit int) ([]model.DigestRecipient, error) {
+	result := []model.DigestRecipient{}
+
+	query, args := squirrel.Select("u.id, u.email, u.full_name, u.locale, u.timezone").
+		From("users u").
+		Where(squirrel.Eq{"u.is_verified": true, "u.is_active": true, "u.deleted_at": nil}).
+		// digests are opt-in, users without a choice don't receive them
+		Where("EXISTS (SELECT 1 FROM email_preferences p WHERE p.user_id = u.id AND p.category = ? AND p.subscribed)", mail.CATEGORY_DIGESTS).
+		Where("EXTRACT(ISODOW FROM ?::TIMESTAMPTZ AT TIME ZONE u.timezone) = 1", now).
+		Where("EXTRACT(HOUR FROM ?::TIMESTAMPTZ AT TIME ZONE u.timezone) >= ?", now, digest.SEND_HOUR).
+		Where("NOT EXISTS (SELECT 1 FROM digest_sends ds WHERE ds.user_id = u.id AND ds.week_start = (?::TIMESTAMPTZ AT TIME ZONE u.timezone)::DATE)", now).
+		OrderBy("u.id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := d.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting weekly digest recipients:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (d *DigestRepositoryImpl) QueueDigest(ctx context.Context, userId string, weekStart string, message mail.Message) (bool, error) {
+	queued := false
+
+	err := database.WithTransaction(ctx, d.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Insert("digest_sends").
+			Columns("user_id", "week_start", "created_at").
+			Values(userId, weekStart, utils.TimeNow()).
+			Suffix("ON CONFLICT (user_id, week_start) DO NOTHING").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return nil
+		}
+
+		if err := d.outboxRepository.Enqueue(ctx, tx, digest.OutboxKey(userId, weekStart), message); err != nil {
+			return err
+		}
+
+		queued = true
+		return nil
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when queueing weekly digest:", err)
+		return false, errorutils.DefineSQLError(err)
+	}
+
+	return queued, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/digest"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/digest/repository"
+	laundryRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"time"
+)
+
+type (
+	DigestService interface {
+		// SendWeeklyDigests queues the weekly digest of every user whose local Monday morning has come
+		SendWeeklyDigests(ctx context.Context) error
+	}
+
+	DigestServiceImpl struct {
+		digestRepository  repository.DigestRepository
+		laundryRepository laundryRepository.LaundryRepository
+	}
+)
+
+func NewDigestService(d repository.DigestRepository, l laundryRepository.LaundryRepository) DigestService {
+	return &DigestServiceImpl{
+		digestRepository:  d,
+		laundryRepository: l,
+	}
+}
+
+func (d *DigestServiceImpl) SendWeeklyDigests(ctx context.Context) error {
+	log := logging.WithContext(ctx)
+	now := utils.TimeNow()
+
+	for ctx.Err() == nil {
+		recipients, err := d.digestRepository.GetDueRecipients(ctx, now, digest.BATCH_SIZE)
+		if err != nil {
+			return err
+		}
+
+		// a failing user would be picked up again right away, the next run retries it
+		failed := false
+		for _, recipient := range recipients {
+			if err := d.sendDigest(ctx, recipient, now); err != nil {
+				log.Errorf("error when sending weekly digest to user %s: %v", recipient.Id, err)
+				failed = true
+			}
+		}
+
+		if failed || len(recipients) < digest.BATCH_SIZE {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (d *DigestServiceImpl) sendDigest(ctx context.Context, recipient model.DigestRecipient, now time.Time) error {
+	location, err := utils.LoadTimezone(recipient.Timezone)
+	if err != nil {
+		location, _ = time.LoadLocation(digest.DEFAULT_TIMEZONE)
+	}
+
+	// the digest covers the week that ended at the start of the user's Monday
+	localNow := now.In(location)
+	weekStart := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, location)
+	previousWeekStart := weekStart.AddDate(0, 0, -7)
+
+	summary, err := d.laundryRepository.GetWeeklySummary(ctx, recipient.Id, previousWeekStart, weekStart)
+	if err != nil {
+		return err
+	}
+
+	message := weeklyDigestEmail(recipient, summary, previousWeekStart, weekStart.AddDate(0, 0, -1))
+
+	queued, err := d.digestRepository.QueueDigest(ctx, recipient.Id, weekStart.Format(constants.FORMAT_DATE_DEFAULT), message)
+	if err != nil {
+		return err
+	}
+
+	if queued {
+		logging.WithContext(ctx).Infof("weekly digest of %s queued for user %s", weekStart.Format(constants.FORMAT_DATE_DEFAULT), recipient.Id)
+	}
+
+	return nil
+}
+
+func weeklyDigestEmail(recipient model.DigestRecipient, summary *model.LaundryWeeklySummary, weekFrom, weekTo time.Time) mail.Message {
+	locale := i18n.Normalize(recipient.Locale)
+
+	categories := []mail.Data{}
+	for _, category := range summary.Categories {
+		categories = append(categories, mail.Data{
+			"Name":   category.CategoryName,
+			"Amount": category.Amount,
+		})
+	}
+
+	upcoming := []mail.Data{}
+	for _, laundry := range summary.Upcoming {
+		upcoming = append(upcoming, mail.Data{
+			"Title":      laundry.Title,
+			"Date":       i18n.FormatDate(locale, laundry.LaundryDate),
+			"TotalItems": laundry.TotalItems,
+		})
+	}
+
+	return mail.Message{
+		Type:     mail.EMAIL_TYPE_WEEKLY_DIGEST,
+		Category: mail.CATEGORY_DIGESTS,
+		UserId:   recipient.Id,
+		Locale:   locale,
+		To:       []string{recipient.Email},
+		Data: mail.Data{
+			"FullName":   recipient.FullName,
+			"WeekFrom":   i18n.FormatDate(locale, weekFrom),
+			"WeekTo":     i18n.FormatDate(locale, weekTo),
+			"Completed":  summary.Completed,
+			"Pending":    summary.Pending,
+			"Categories": categories,
+			"Upcoming":   upcoming,
+		},
+	}
+}
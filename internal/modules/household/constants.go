@@ -0,0 +1,11 @@
+package household
+
+const (
+	// INVITATION_EXPIRATION_DAYS is how long an invitation can be accepted after it was sent
+	INVITATION_EXPIRATION_DAYS = 7
+)
+
+// InvitationKey identifies the invitation email in the outbox
+func InvitationKey(invitationId string) string {
+	return "household-invitation:" + invitationId
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type (
+	HouseholdController interface {
+		GetHousehold(ctx *gin.Context)
+		CreateHousehold(ctx *gin.Context)
+		UpdateHousehold(ctx *gin.Context)
+		InviteMember(ctx *gin.Context)
+		RevokeInvitation(ctx *gin.Context)
+		AcceptInvitation(ctx *gin.Context)
+		UpdateMemberRole(ctx *gin.Context)
+		RemoveMember(ctx *gin.Context)
+		GetInvitationPage(ctx *gin.Context)
+		SubmitInvitationForm(ctx *gin.Context)
+	}
+
+	HouseholdControllerImpl struct {
+		householdService service.HouseholdService
+		flashStore       tools.FlashStore
+	}
+)
+
+func NewHouseholdController(householdService service.HouseholdService, flashStore tools.FlashStore) HouseholdController {
+	return &HouseholdControllerImpl{
+		householdService: householdService,
+		flashStore:       flashStore,
+	}
+}
+
+func (h *HouseholdControllerImpl) GetHousehold(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := h.householdService.GetHousehold(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (h *HouseholdControllerImpl) CreateHousehold(ctx *gin.Context) {
+	var request model.HouseholdRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := h.householdService.CreateHousehold(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (h *HouseholdControllerImpl) UpdateHousehold(ctx *gin.Context) {
+	var request model.HouseholdRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := h.householdService.UpdateHousehold(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (h *HouseholdControllerImpl) InviteMember(ctx *gin.Context) {
+	var request model.HouseholdInvitationRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := h.householdService.InviteMember(ctx, userData, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (h *HouseholdControllerImpl) RevokeInvitation(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := h.householdService.RevokeInvitation(ctx, userData.UserId, ctx.Param("id"))
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+func (h *HouseholdControllerImpl) AcceptInvitation(ctx *gin.Context) {
+	var request model.AcceptHouseholdInvitationRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := h.householdService.AcceptInvitation(ctx, userData, request.Token)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (h *HouseholdControllerImpl) UpdateMemberRole(ctx *gin.Context) {
+	var request model.HouseholdMemberRoleRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := h.householdService.UpdateMemberRole(ctx, userData.UserId, ctx.Param("userId"), request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// RemoveMember removes a member from the household, removing oneself leaves the household
+func (h *HouseholdControllerImpl) RemoveMember(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := h.householdService.RemoveMember(ctx, userData.UserId, ctx.Param("userId"))
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+// GetInvitationPage is where the link of the invitation email lands, it asks the signed in user to accept the invitation
+func (h *HouseholdControllerImpl) GetInvitationPage(ctx *gin.Context) {
+	if _, ok := ctx.Get(constants.USER_DATA); !ok {
+		httputils.InvalidateCookie(ctx, constants.COOKIE_AUTH_TOKEN)
+		ctx.Redirect(http.StatusTemporaryRedirect, "/login")
+		return
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+	token := ctx.Query("token")
+
+	invitation, err := h.householdService.GetInvitation(ctx, token)
+	if err != nil {
+		statusCode, message := errorutils.GetStatusCode(err)
+		httputils.SetHtmlResponse(ctx, statusCode, "household_invitation.html", gin.H{"error": i18n.TranslateError(locale, message)})
+		return
+	}
+
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "household_invitation.html", gin.H{
+		"invitation": invitation,
+		"role":       model.HouseholdRoleLabel(locale, invitation.Role),
+		"token":      token,
+	})
+}
+
+func (h *HouseholdControllerImpl) SubmitInvitationForm(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+	locale := i18n.LocaleFromContext(ctx)
+
+	result, err := h.householdService.AcceptInvitation(ctx, userData, ctx.PostForm("token"))
+	if err != nil {
+		statusCode, message := errorutils.GetStatusCode(err)
+		httputils.SetHtmlResponse(ctx, statusCode, "household_invitation.html", gin.H{"error": i18n.TranslateError(locale, message)})
+		return
+	}
+
+	h.flashStore.Add(ctx, tools.FLASH_LEVEL_SUCCESS, i18n.T(locale, "flash.household_joined", result.Name))
+	ctx.Redirect(http.StatusSeeOther, "/")
+}
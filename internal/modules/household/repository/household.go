@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household"
+	outboxRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/jmoiron/sqlx"
+)
+
+type (
+	HouseholdRepository interface {
+		// GetMembership returns the household of the user and the user's role, ErrorNotFound when the user has none
+		GetMembership(ctx context.Context, userId string) (*model.HouseholdMembership, error)
+		// GetHousehold returns the household with its members, the pending invitations are only loaded when withInvitations
+		GetHousehold(ctx context.Context, id string, withInvitations bool) (*model.HouseholdResponse, error)
+		// CreateHousehold stores the household with the user as its owner and shares the categories and routines
+		// of the user with it
+		CreateHousehold(ctx context.Context, userId, name string) (string, error)
+		UpdateHousehold(ctx context.Context, id, name string) error
+		// AddInvitation stores the invitation and queues the email built by invitationEmail in the same transaction
+		AddInvitation(ctx context.Context, data model.NewHouseholdInvitation, invitationEmail func(id string) mail.Message) (string, error)
+		RevokeInvitation(ctx context.Context, householdId, id string) error
+		GetInvitation(ctx context.Context, id string) (*model.HouseholdInvitation, error)
+		// AcceptInvitation adds the user to the household of a pending invitation with its role and shares the
+		// categories and routines of the user with the household
+		AcceptInvitation(ctx context.Context, id, userId string) error
+		UpdateMemberRole(ctx context.Context, householdId, userId string, role constants.HouseholdRole) error
+		// RemoveMember takes the user out of the household, the categories and routines the user shared stay with it.
+		// The household is deleted with its last member.
+		RemoveMember(ctx context.Context, householdId, userId string) error
+	}
+
+	HouseholdRepositoryImpl struct {
+		db               database.DBCollection
+		outboxRepository outboxRepository.OutboxRepository
+	}
+)
+
+func NewHouseholdRepository(db database.DBCollection, o outboxRepository.OutboxRepository) HouseholdRepository {
+	return &HouseholdRepositoryImpl{
+		db:               db,
+		outboxRepository: o,
+	}
+}
+
+func (h *HouseholdRepositoryImpl) GetMembership(ctx context.Context, userId string) (*model.HouseholdMembership, error) {
+	query, args := squirrel.Select("household_id, role").
+		From("household_members").
+		Where(squirrel.Eq{"user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.HouseholdMembership
+	if err := h.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logging.WithContext(ctx).Error("error when getting household membership:", err)
+		}
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (h *HouseholdRepositoryImpl) GetHousehold(ctx context.Context, id string, withInvitations bool) (*model.HouseholdResponse, error) {
+	log := logging.WithContext(ctx)
+
+	query, args := squirrel.Select("id, name, created_at").
+		From("households").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.HouseholdResponse
+	if err := h.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		log.Error("error when getting household:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryMembers, args := squirrel.Select("hm.user_id, u.full_name, u.email, hm.role, hm.created_at").
+		From("household_members hm").
+		Join("users u ON u.id = hm.user_id").
+		Where(squirrel.Eq{"hm.household_id": id}).
+		OrderBy("hm.created_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result.Members = []model.HouseholdMemberResponse{}
+	if err := h.db.PostgresDBSqlx.SelectContext(ctx, &result.Members, queryMembers, args...); err != nil {
+		log.Error("error when getting household members:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	result.Invitations = []model.HouseholdInvitationResponse{}
+	if !withInvitations {
+		return &result, nil
+	}
+
+	queryInvitations, args := squirrel.Select("id, email, role, expires_at, created_at").
+		From("household_invitations").
+		Where(squirrel.Eq{"household_id": id, "accepted_at": nil, "revoked_at": nil}).
+		Where(squirrel.Gt{"expires_at": utils.TimeNow()}).
+		OrderBy("created_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := h.db.PostgresDBSqlx.SelectContext(ctx, &result.Invitations, queryInvitations, args...); err != nil {
+		log.Error("error when getting household invitations:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (h *HouseholdRepositoryImpl) CreateHousehold(ctx context.Context, userId, name string) (string, error) {
+	id := utils.GenerateCleanUUID()
+
+	err := database.WithTransaction(ctx, h.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		currentTime := utils.TimeNow()
+
+		query, args := squirrel.Insert("households").
+			Columns("id", "name", "created_by", "created_at", "updated_at").
+			Values(id, name, userId, currentTime, currentTime).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		return addMember(ctx, tx, id, userId, constants.HOUSEHOLD_ROLE_OWNER)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when creating household:", err)
+		return "", errorutils.DefineSQLError(err)
+	}
+
+	return id, nil
+}
+
+func (h *HouseholdRepositoryImpl) UpdateHousehold(ctx context.Context, id, name string) error {
+	query, args := squirrel.Update("households").
+		Set("name", name).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := h.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when updating household:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (h *HouseholdRepositoryImpl) AddInvitation(ctx context.Context, data model.NewHouseholdInvitation, invitationEmail func(id string) mail.Message) (string, error) {
+	id := utils.GenerateCleanUUID()
+
+	err := database.WithTransaction(ctx, h.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Insert("household_invitations").
+			Columns("id", "household_id", "email", "role", "invited_by", "expires_at", "created_at").
+			Values(id, data.HouseholdId, data.Email, data.Role, data.InvitedBy, data.ExpiresAt, utils.TimeNow()).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		return h.outboxRepository.Enqueue(ctx, tx, household.InvitationKey(id), invitationEmail(id))
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when adding household invitation:", err)
+		return "", errorutils.DefineSQLError(err)
+	}
+
+	return id, nil
+}
+
+func (h *HouseholdRepositoryImpl) RevokeInvitation(ctx context.Context, householdId, id string) error {
+	query, args := squirrel.Update("household_invitations").
+		Set("revoked_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id, "household_id": householdId, "accepted_at": nil, "revoked_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := h.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when revoking household invitation:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (h *HouseholdRepositoryImpl) GetInvitation(ctx context.Context, id string) (*model.HouseholdInvitation, error) {
+	query, args := squirrel.Select("hi.id, hi.household_id, h.name AS household_name, hi.email, hi.role",
+		"u.full_name AS invited_by_name, hi.expires_at, hi.accepted_at, hi.revoked_at").
+		From("household_invitations hi").
+		Join("households h ON h.id = hi.household_id").
+		Join("users u ON u.id = hi.invited_by").
+		Where(squirrel.Eq{"hi.id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.HouseholdInvitation
+	if err := h.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting household invitation:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (h *HouseholdRepositoryImpl) AcceptInvitation(ctx context.Context, id, userId string) error {
+	err := database.WithTransaction(ctx, h.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		currentTime := utils.TimeNow()
+
+		query, args := squirrel.Update("household_invitations").
+			Set("accepted_at", currentTime).
+			Set("accepted_by", userId).
+			Where(squirrel.Eq{"id": id, "accepted_at": nil, "revoked_at": nil}).
+			Where(squirrel.Gt{"expires_at": currentTime}).
+			Suffix("RETURNING household_id, role").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		var invitation model.HouseholdMembership
+		if err := sqlx.GetContext(ctx, tx, &invitation, query, args...); err != nil {
+			return err
+		}
+
+		return addMember(ctx, tx, invitation.HouseholdId, userId, invitation.Role)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when accepting household invitation:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (h *HouseholdRepositoryImpl) UpdateMemberRole(ctx context.Context, householdId, userId string, role constants.HouseholdRole) error {
+	query, args := squirrel.Update("household_members").
+		Set("role", role).
+		Where(squirrel.Eq{"household_id": householdId, "user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := h.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when updating household member role:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (h *HouseholdRepositoryImpl) RemoveMember(ctx context.Context, householdId, userId string) error {
+	err := database.WithTransaction(ctx, h.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Delete("household_members").
+			Where(squirrel.Eq{"household_id": householdId, "user_id": userId}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		queryHousehold, args := squirrel.Delete("households").
+			Where(squirrel.Eq{"id": householdId}).
+			Where("NOT EXISTS (SELECT 1 FROM household_members hm WHERE hm.household_id = households.id)").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		_, err = tx.ExecContext(ctx, queryHousehold, args...)
+		return err
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when removing household member:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+// addMember adds the user to the household and shares the categories and routines the user keeps with it
+func addMember(ctx context.Context, tx sqlx.ExtContext, householdId, userId string, role constants.HouseholdRole) error {
+	query, args := squirrel.Insert("household_members").
+		Columns("household_id", "user_id", "role", "created_at").
+		Values(householdId, userId, role, utils.TimeNow()).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	// a viewer can't change the shared rows, so its own are not shared
+	if !role.CanEdit() {
+		return nil
+	}
+
+	for _, table := range []string{"categories", "laundries"} {
+		queryShare, args := squirrel.Update(table).
+			Set("household_id", householdId).
+			Where(squirrel.Eq{"user_id": userId, "household_id": nil}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, queryShare, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
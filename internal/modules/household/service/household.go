@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"strings"
+)
+
+type (
+	HouseholdService interface {
+		// GetHousehold returns the household of the user, ErrorNotFound when the user has none
+		GetHousehold(ctx context.Context, userId string) (*model.HouseholdResponse, error)
+		// CreateHousehold creates a household owned by the user, a user belongs to one household at most
+		CreateHousehold(ctx context.Context, userId string, request model.HouseholdRequest) (*model.HouseholdResponse, error)
+		UpdateHousehold(ctx context.Context, userId string, request model.HouseholdRequest) (*model.HouseholdResponse, error)
+		// InviteMember emails an invitation link to join the household of the inviter, who must be its owner
+		InviteMember(ctx context.Context, inviter model.UserClaims, request model.HouseholdInvitationRequest) (*model.HouseholdResponse, error)
+		RevokeInvitation(ctx context.Context, userId, invitationId string) error
+		// GetInvitation returns the pending invitation of a signed invitation link
+		GetInvitation(ctx context.Context, token string) (*model.HouseholdInvitation, error)
+		// AcceptInvitation adds the user to the household of the invitation, it must have been sent to the email of the user
+		AcceptInvitation(ctx context.Context, user model.UserClaims, token string) (*model.HouseholdResponse, error)
+		UpdateMemberRole(ctx context.Context, userId, memberId string, request model.HouseholdMemberRoleRequest) (*model.HouseholdResponse, error)
+		// RemoveMember removes a member from the household of the user, owners remove anyone and everyone can remove
+		// themselves. The last owner can only leave as the last member.
+		RemoveMember(ctx context.Context, userId, memberId string) error
+	}
+
+	HouseholdServiceImpl struct {
+		householdRepository repository.HouseholdRepository
+		invitationTokens    tools.InvitationTokens
+	}
+)
+
+func NewHouseholdService(h repository.HouseholdRepository, invitationTokens tools.InvitationTokens) HouseholdService {
+	return &HouseholdServiceImpl{
+		householdRepository: h,
+		invitationTokens:    invitationTokens,
+	}
+}
+
+func (h *HouseholdServiceImpl) GetHousehold(ctx context.Context, userId string) (*model.HouseholdResponse, error) {
+	membership, err := h.householdRepository.GetMembership(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.getHousehold(ctx, membership)
+}
+
+func (h *HouseholdServiceImpl) CreateHousehold(ctx context.Context, userId string, request model.HouseholdRequest) (*model.HouseholdResponse, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("name is required")
+	}
+
+	if _, err := h.householdRepository.GetMembership(ctx, userId); err == nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("you already belong to a household")
+	}
+
+	if _, err := h.householdRepository.CreateHousehold(ctx, userId, name); err != nil {
+		return nil, err
+	}
+
+	return h.GetHousehold(ctx, userId)
+}
+
+func (h *HouseholdServiceImpl) UpdateHousehold(ctx context.Context, userId string, request model.HouseholdRequest) (*model.HouseholdResponse, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("name is required")
+	}
+
+	membership, err := h.ownerMembership(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.householdRepository.UpdateHousehold(ctx, membership.HouseholdId, name); err != nil {
+		return nil, err
+	}
+
+	return h.getHousehold(ctx, membership)
+}
+
+func (h *HouseholdServiceImpl) InviteMember(ctx context.Context, inviter model.UserClaims, request model.HouseholdInvitationRequest) (*model.HouseholdResponse, error) {
+	membership, err := h.ownerMembership(ctx, inviter.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	householdData, err := h.householdRepository.GetHousehold(ctx, membership.HouseholdId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	for _, member := range householdData.Members {
+		if strings.EqualFold(member.Email, email) {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("the user is already a member of the household")
+		}
+	}
+
+	for _, invitation := range householdData.Invitations {
+		if strings.EqualFold(invitation.Email, email) {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("the email is already invited")
+		}
+	}
+
+	role := constants.HouseholdRole(request.Role)
+	expiresAt := utils.TimeNow().AddDate(0, 0, household.INVITATION_EXPIRATION_DAYS)
+
+	// the invitation email is queued together with the invitation, and delivered by the outbox worker
+	_, err = h.householdRepository.AddInvitation(ctx, model.NewHouseholdInvitation{
+		HouseholdId: membership.HouseholdId,
+		Email:       email,
+		Role:        role,
+		InvitedBy:   inviter.UserId,
+		ExpiresAt:   expiresAt,
+	}, func(id string) mail.Message {
+		// the invitee may not have an account yet, the email is written in the locale of the inviter
+		locale := i18n.Normalize(inviter.Locale)
+		return mail.Message{
+			Type:     mail.EMAIL_TYPE_HOUSEHOLD_INVITATION,
+			Category: mail.CATEGORY_TRANSACTIONAL,
+			Locale:   locale,
+			To:       []string{email},
+			Data: mail.Data{
+				"InviterName":   inviter.FullName,
+				"HouseholdName": householdData.Name,
+				"Role":          model.HouseholdRoleLabel(locale, role),
+				"AcceptURL":     h.invitationTokens.URL(id),
+				"ExpiresAt":     i18n.FormatDate(locale, expiresAt),
+			},
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.getHousehold(ctx, membership)
+}
+
+func (h *HouseholdServiceImpl) RevokeInvitation(ctx context.Context, userId, invitationId string) error {
+	membership, err := h.ownerMembership(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	return h.householdRepository.RevokeInvitation(ctx, membership.HouseholdId, invitationId)
+}
+
+func (h *HouseholdServiceImpl) GetInvitation(ctx context.Context, token string) (*model.HouseholdInvitation, error) {
+	invitationId, err := h.invitationTokens.Verify(token)
+	if err != nil {
+		logging.WithContext(ctx).Error("invalid invitation token:", err)
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid invitation")
+	}
+
+	invitation, err := h.householdRepository.GetInvitation(ctx, invitationId)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid invitation")
+	}
+
+	if !invitation.IsPending(utils.TimeNow()) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the invitation is no longer valid")
+	}
+
+	return invitation, nil
+}
+
+func (h *HouseholdServiceImpl) AcceptInvitation(ctx context.Context, user model.UserClaims, token string) (*model.HouseholdResponse, error) {
+	invitation, err := h.GetInvitation(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, errorutils.ErrorForbidden.CustomMessage("the invitation was sent to another email")
+	}
+
+	if _, err := h.householdRepository.GetMembership(ctx, user.UserId); err == nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("you already belong to a household")
+	}
+
+	if err := h.householdRepository.AcceptInvitation(ctx, invitation.Id, user.UserId); err != nil {
+		return nil, err
+	}
+
+	return h.GetHousehold(ctx, user.UserId)
+}
+
+func (h *HouseholdServiceImpl) UpdateMemberRole(ctx context.Context, userId, memberId string, request model.HouseholdMemberRoleRequest) (*model.HouseholdResponse, error) {
+	membership, err := h.ownerMembership(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	householdData, err := h.householdRepository.GetHousehold(ctx, membership.HouseholdId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	role := constants.HouseholdRole(request.Role)
+	if err := validateOwners(householdData.Members, memberId, &role); err != nil {
+		return nil, err
+	}
+
+	if err := h.householdRepository.UpdateMemberRole(ctx, membership.HouseholdId, memberId, role); err != nil {
+		return nil, err
+	}
+
+	return h.getHousehold(ctx, membership)
+}
+
+func (h *HouseholdServiceImpl) RemoveMember(ctx context.Context, userId, memberId string) error {
+	membership, err := h.householdRepository.GetMembership(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if memberId != userId && membership.Role != constants.HOUSEHOLD_ROLE_OWNER {
+		return errorutils.ErrorForbidden.CustomMessage("only the owners can manage the household")
+	}
+
+	householdData, err := h.householdRepository.GetHousehold(ctx, membership.HouseholdId, false)
+	if err != nil {
+		return err
+	}
+
+	// the last member takes the household with them
+	if len(householdData.Members) > 1 {
+		if err := validateOwners(householdData.Members, memberId, nil); err != nil {
+			return err
+		}
+	}
+
+	return h.householdRepository.RemoveMember(ctx, membership.HouseholdId, memberId)
+}
+
+// getHousehold loads the household of the membership, the pending invitations are only shown to the owners
+func (h *HouseholdServiceImpl) getHousehold(ctx context.Context, membership *model.HouseholdMembership) (*model.HouseholdResponse, error) {
+	result, err := h.householdRepository.GetHousehold(ctx, membership.HouseholdId, membership.Role == constants.HOUSEHOLD_ROLE_OWNER)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Role = membership.Role
+
+	return result, nil
+}
+
+// ownerMembership returns the membership of the user, who must be an owner of the household
+func (h *HouseholdServiceImpl) ownerMembership(ctx context.Context, userId string) (*model.HouseholdMembership, error) {
+	membership, err := h.householdRepository.GetMembership(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if membership.Role != constants.HOUSEHOLD_ROLE_OWNER {
+		return nil, errorutils.ErrorForbidden.CustomMessage("only the owners can manage the household")
+	}
+
+	return membership, nil
+}
+
+// validateOwners checks that the household keeps an owner once the member gets the new role, or leaves when role is nil
+func validateOwners(members []model.HouseholdMemberResponse, memberId string, role *constants.HouseholdRole) error {
+	found, owners := false, 0
+	for _, member := range members {
+		memberRole := member.Role
+		if member.UserId == memberId {
+			found = true
+			if role == nil {
+				continue
+			}
+			memberRole = *role
+		}
+
+		if memberRole == constants.HOUSEHOLD_ROLE_OWNER {
+			owners++
+		}
+	}
+
+	if !found {
+		return errorutils.ErrorNotFound.CustomMessage("member not found")
+	}
+
+	if owners == 0 {
+		return errorutils.ErrorBadRequest.CustomMessage("the household needs at least one owner")
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"net/http"
+	"testing"
+)
+
+func TestValidateOwners(t *testing.T) {
+	owner, member, viewer := constants.HOUSEHOLD_ROLE_OWNER, constants.HOUSEHOLD_ROLE_MEMBER, constants.HOUSEHOLD_ROLE_VIEWER
+
+	oneOwner := []model.HouseholdMemberResponse{
+		{UserId: "alice", Role: owner},
+		{UserId: "bob", Role: member},
+		{UserId: "carol", Role: viewer},
+	}
+	twoOwners := []model.HouseholdMemberResponse{
+		{UserId: "alice", Role: owner},
+		{UserId: "bob", Role: owner},
+	}
+
+	tests := []struct {
+		name       string
+		members    []model.HouseholdMemberResponse
+		memberId   string
+		role       *constants.HouseholdRole
+		wantStatus int
+	}{
+		{name: "member promoted", members: oneOwner, memberId: "bob", role: &owner, wantStatus: http.StatusOK},
+		{name: "viewer becomes member", members: oneOwner, memberId: "carol", role: &member, wantStatus: http.StatusOK},
+		{name: "member leaves", members: oneOwner, memberId: "bob", wantStatus: http.StatusOK},
+		{name: "owner stays owner", members: oneOwner, memberId: "alice", role: &owner, wantStatus: http.StatusOK},
+		{name: "last owner demoted", members: oneOwner, memberId: "alice", role: &viewer, wantStatus: http.StatusBadRequest},
+		{name: "last owner leaves", members: oneOwner, memberId: "alice", wantStatus: http.StatusBadRequest},
+		{name: "one of two owners demoted", members: twoOwners, memberId: "alice", role: &member, wantStatus: http.StatusOK},
+		{name: "one of two owners leaves", members: twoOwners, memberId: "bob", wantStatus: http.StatusOK},
+		{name: "unknown member", members: oneOwner, memberId: "dave", role: &member, wantStatus: http.StatusNotFound},
+		{name: "unknown member leaves", members: oneOwner, memberId: "dave", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOwners(tt.members, tt.memberId, tt.role)
+			if status, message := errorutils.GetStatusCode(err); status != tt.wantStatus {
+				t.Errorf("status = %d (%s), want %d", status, message, tt.wantStatus)
+			}
+		})
+	}
+}
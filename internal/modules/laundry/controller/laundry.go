@@ -2,12 +2,16 @@ package controller
 
 import (
 	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
 	"github.com/gin-gonic/gin"
+	"mime/multipart"
 	"net/http"
 	"strings"
 	"time"
@@ -16,17 +20,35 @@ import (
 type (
 	LaundryController interface {
 		GetLaundryList(ctx *gin.Context)
+		ExportLaundries(ctx *gin.Context)
 		AddLaundry(ctx *gin.Context)
+		AddLaundryFromTemplate(ctx *gin.Context)
+		CloneLaundry(ctx *gin.Context)
+		PreviewICSImport(ctx *gin.Context)
+		ImportICS(ctx *gin.Context)
+		ImportCSV(ctx *gin.Context)
+		GetTemplateList(ctx *gin.Context)
+		GetTemplate(ctx *gin.Context)
+		AddTemplate(ctx *gin.Context)
+		UpdateTemplate(ctx *gin.Context)
+		DeleteTemplate(ctx *gin.Context)
+		GetLaundryDetail(ctx *gin.Context)
+		UpdateLaundryStatus(ctx *gin.Context)
+		RecordReturns(ctx *gin.Context)
+		ResolveDiscrepancy(ctx *gin.Context)
+		GetMissingItems(ctx *gin.Context)
 	}
 
 	LaundryControllerImpl struct {
 		laundryService service.LaundryService
+		flashStore     tools.FlashStore
 	}
 )
 
-func NewLaundryController(laundryService service.LaundryService) LaundryController {
+func NewLaundryController(laundryService service.LaundryService, flashStore tools.FlashStore) LaundryController {
 	return &LaundryControllerImpl{
 		laundryService: laundryService,
+		flashStore:     flashStore,
 	}
 }
 
@@ -40,26 +62,7 @@ func (l *LaundryControllerImpl) GetLaundryList(ctx *gin.Context) {
 
 	userData := userDataCtx.(model.UserClaims)
 
-	queryParam := model.LaundryQueryParam{
-		CategoryName:    ctx.Query("category_name"),
-		LaundryDateFrom: nil,
-		LaundryDateTo:   nil,
-		Page:            utils.ConvertStrToInt(strings.TrimSpace(ctx.Query("page")), 1),
-	}
-
-	if laundryDateFromStr := strings.TrimSpace(ctx.Query("laundry_date_from")); laundryDateFromStr != "" {
-		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, laundryDateFromStr)
-		if err == nil {
-			queryParam.LaundryDateFrom = &timeObj
-		}
-	}
-
-	if laundryDateToStr := strings.TrimSpace(ctx.Query("laundry_date_to")); laundryDateToStr != "" {
-		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, laundryDateToStr)
-		if err == nil {
-			queryParam.LaundryDateTo = &timeObj
-		}
-	}
+	queryParam := laundryQueryParam(ctx)
 
 	dataHtml := gin.H{}
 
@@ -68,8 +71,40 @@ func (l *LaundryControllerImpl) GetLaundryList(ctx *gin.Context) {
 		dataHtml["error"] = err.Error()
 	}
 	dataHtml["data"] = result
+	dataHtml["pagination"] = httputils.NewPagination(ctx, queryParam.Page, len(result) == constants.LAUNDRY_LIST_LIMIT)
+
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "dashboard.html", dataHtml)
+}
+
+// ExportLaundries downloads the routines matching the list filters as a CSV or XLSX file (?format=csv|xlsx)
+func (l *LaundryControllerImpl) ExportLaundries(ctx *gin.Context) {
+	format := strings.ToLower(strings.TrimSpace(ctx.DefaultQuery("format", tools.SPREADSHEET_FORMAT_CSV)))
+
+	contentType, ok := tools.SpreadsheetContentType(format)
+	if !ok {
+		httputils.SetHttpResponse(ctx, nil, errorutils.ErrorBadRequest.CustomMessage("unsupported export format"), nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	fileName := "laundry-history-" + utils.TimeNow().Format(constants.FORMAT_DATE_YYYYMMDD) + "." + format
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+
+	err := l.laundryService.ExportLaundries(ctx, userData.UserId, laundryQueryParam(ctx), format, ctx.Writer)
+	if err != nil {
+		// once the rows are being streamed the status is sent, the download can only be cut short
+		if ctx.Writer.Written() {
+			logging.WithContext(ctx).Error("error when streaming laundry export:", err)
+			ctx.Abort()
+			return
+		}
 
-	ctx.HTML(http.StatusOK, "dashboard.html", dataHtml)
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.Writer.Header().Del("Content-Disposition")
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+	}
 }
 
 func (l *LaundryControllerImpl) AddLaundry(ctx *gin.Context) {
@@ -88,5 +123,283 @@ func (l *LaundryControllerImpl) AddLaundry(ctx *gin.Context) {
 
 	userData := userDataCtx.(model.UserClaims)
 
-	httputils.SetHttpResponse(ctx, nil, l.laundryService.AddLaundry(ctx, userData.UserId, request), nil)
+	result, err := l.laundryService.AddLaundry(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) AddLaundryFromTemplate(ctx *gin.Context) {
+	var request model.CopyLaundryRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.AddLaundryFromTemplate(ctx, userData.UserId, ctx.Param("id"), request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) CloneLaundry(ctx *gin.Context) {
+	var request model.CopyLaundryRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.CloneLaundry(ctx, userData.UserId, ctx.Param("id"), request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) PreviewICSImport(ctx *gin.Context) {
+	file, err := openImportFile(ctx)
+	if err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+	defer file.Close()
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.PreviewICSImport(ctx, userData.UserId, file)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) ImportICS(ctx *gin.Context) {
+	var request model.LaundryImportRequest
+	if err := ctx.ShouldBind(&request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, errorutils.ErrorBadRequest.CustomMessage(err.Error()), nil)
+		return
+	}
+
+	file, err := openImportFile(ctx)
+	if err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+	defer file.Close()
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.ImportICS(ctx, userData.UserId, file, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) ImportCSV(ctx *gin.Context) {
+	var request model.LaundryCSVImportRequest
+	if err := ctx.ShouldBind(&request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, errorutils.ErrorBadRequest.CustomMessage(err.Error()), nil)
+		return
+	}
+
+	file, err := openImportFile(ctx)
+	if err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+	defer file.Close()
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.ImportCSV(ctx, userData.UserId, file, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) GetTemplateList(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.GetTemplateList(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) GetTemplate(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.GetTemplate(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) AddTemplate(ctx *gin.Context) {
+	var request model.LaundryTemplateRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.AddTemplate(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) UpdateTemplate(ctx *gin.Context) {
+	var request model.LaundryTemplateRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.UpdateTemplate(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) DeleteTemplate(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := l.laundryService.DeleteTemplate(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+// GetLaundryDetail renders the routine detail as an HTML fragment to be loaded into the dashboard modal
+func (l *LaundryControllerImpl) GetLaundryDetail(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+
+	result, err := l.laundryService.GetLaundryDetail(ctx, ctx.Param("id"), userData.UserId)
+	if err != nil {
+		statusCode, message := errorutils.GetStatusCode(err)
+		httputils.SetHtmlFragmentResponse(ctx, statusCode, "laundry_detail.html", gin.H{"error": i18n.TranslateError(i18n.LocaleFromContext(ctx), message)})
+		return
+	}
+
+	httputils.SetHtmlFragmentResponse(ctx, http.StatusOK, "laundry_detail.html", gin.H{"data": result})
+}
+
+// UpdateLaundryStatus moves the routine to the submitted status.
+// Requests coming from the dashboard script get the refreshed detail fragment,
+// plain form submissions are redirected back to the dashboard with a flash message.
+func (l *LaundryControllerImpl) UpdateLaundryStatus(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+	isFragment := ctx.GetHeader(constants.HEADER_REQUESTED_WITH) != ""
+
+	var request model.UpdateLaundryStatusRequest
+	if err := ctx.ShouldBind(&request); err != nil || request.Status == nil {
+		logging.WithContext(ctx).Error("invalid status payload:", err)
+		if !isFragment {
+			l.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, i18n.T(i18n.LocaleFromContext(ctx), "flash.status_required"))
+			ctx.Redirect(http.StatusSeeOther, "/")
+			return
+		}
+		httputils.SetHtmlFragmentResponse(ctx, http.StatusBadRequest, "laundry_detail.html", gin.H{"error": i18n.TranslateError(i18n.LocaleFromContext(ctx), "status is required")})
+		return
+	}
+
+	status := constants.LaundryStatus(*request.Status)
+
+	result, err := l.laundryService.UpdateLaundryStatus(ctx, ctx.Param("id"), userData.UserId, status)
+	if !isFragment {
+		locale := i18n.LocaleFromContext(ctx)
+		if err != nil {
+			_, message := errorutils.GetStatusCode(err)
+			l.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, i18n.T(locale, "flash.status_update_failed", i18n.TranslateError(locale, message)))
+		} else {
+			l.flashStore.Add(ctx, tools.FLASH_LEVEL_SUCCESS, i18n.T(locale, "flash.status_updated", result.Title, model.LaundryStatusLabel(locale, status)))
+		}
+		ctx.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+
+	if err != nil {
+		statusCode, message := errorutils.GetStatusCode(err)
+		httputils.SetHtmlFragmentResponse(ctx, statusCode, "laundry_detail.html", gin.H{"error": i18n.TranslateError(i18n.LocaleFromContext(ctx), message)})
+		return
+	}
+
+	httputils.SetHtmlFragmentResponse(ctx, http.StatusOK, "laundry_detail.html", gin.H{"data": result})
+}
+
+func (l *LaundryControllerImpl) RecordReturns(ctx *gin.Context) {
+	var request model.LaundryReturnsRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.RecordReturns(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (l *LaundryControllerImpl) ResolveDiscrepancy(ctx *gin.Context) {
+	var request model.LaundryResolutionRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := l.laundryService.ResolveDiscrepancy(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// GetMissingItems reports the items that came back short, include_resolved=true also lists the resolved routines
+func (l *LaundryControllerImpl) GetMissingItems(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+	includeResolved := strings.TrimSpace(ctx.Query("include_resolved")) == "true"
+
+	result, err := l.laundryService.GetMissingItems(ctx, userData.UserId, includeResolved)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// openImportFile opens the file uploaded in the "file" field of a multipart form
+func openImportFile(ctx *gin.Context) (multipart.File, error) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("file is required")
+	}
+
+	if header.Size > constants.LAUNDRY_IMPORT_MAX_FILE_SIZE {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("file is too large")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		logging.WithContext(ctx).Error("error when opening uploaded file:", err)
+		return nil, errorutils.ErrorBadRequest.CustomMessage("file is required")
+	}
+
+	return file, nil
+}
+
+// laundryQueryParam reads the filters of the laundry list, the dates that can't be parsed are ignored
+func laundryQueryParam(ctx *gin.Context) model.LaundryQueryParam {
+	queryParam := model.LaundryQueryParam{
+		CategoryName:    ctx.Query("category_name"),
+		LaundryDateFrom: nil,
+		LaundryDateTo:   nil,
+		Page:            utils.ConvertStrToInt(strings.TrimSpace(ctx.Query("page")), 1),
+	}
+
+	if laundryDateFromStr := strings.TrimSpace(ctx.Query("laundry_date_from")); laundryDateFromStr != "" {
+		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, laundryDateFromStr)
+		if err == nil {
+			queryParam.LaundryDateFrom = &timeObj
+		}
+	}
+
+	if laundryDateToStr := strings.TrimSpace(ctx.Query("laundry_date_to")); laundryDateToStr != "" {
+		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, laundryDateToStr)
+		if err == nil {
+			queryParam.LaundryDateTo = &timeObj
+		}
+	}
+
+	return queryParam
 }
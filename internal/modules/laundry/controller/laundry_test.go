@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/view"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// detailService serves GetLaundryDetail from a fixed result, the other methods aren't used by the fragment
+type detailService struct {
+	service.LaundryService
+	result *model.LaundryDetailResponse
+	err    error
+	userId string
+}
+
+func (d *detailService) GetLaundryDetail(ctx context.Context, id, userId string) (*model.LaundryDetailResponse, error) {
+	d.userId = userId
+	return d.result, d.err
+}
+
+func newDetailRouter(t *testing.T, laundryService service.LaundryService, userData *model.UserClaims, locale string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	assets, err := view.NewAssets(false)
+	if err != nil {
+		t.Fatalf("NewAssets: %v", err)
+	}
+	renderer, err := view.NewRenderer(false, assets)
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+
+	r := gin.New()
+	r.HTMLRender = renderer
+	r.Use(func(ctx *gin.Context) {
+		if userData != nil {
+			ctx.Set(constants.USER_DATA, *userData)
+		}
+		if locale != "" {
+			ctx.Set(constants.LOCALE, locale)
+		}
+	})
+
+	controller := NewLaundryController(laundryService, nil)
+	r.GET("/laundry/:id/details", controller.GetLaundryDetail)
+	return r
+}
+
+func TestGetLaundryDetail(t *testing.T) {
+	detail := &model.LaundryDetailResponse{
+		LaundryResponse: model.LaundryResponse{
+			Id:                "laundry-1",
+			Title:             "Weekly wash",
+			LaundryDateString: "2 Mar 2024",
+			TotalItems:        3,
+			StatusLabel:       "Washing",
+		},
+		Items: []model.LaundryItemResponse{{Id: "item-1", CategoryName: "Shirts", Amount: 3}},
+	}
+	user := &model.UserClaims{UserId: "user-1"}
+
+	tests := []struct {
+		name       string
+		service    *detailService
+		userData   *model.UserClaims
+		locale     string
+		wantStatus int
+		contains   []string
+		excludes   []string
+	}{
+		{
+			name:       "detail",
+			service:    &detailService{result: detail},
+			userData:   user,
+			wantStatus: http.StatusOK,
+			contains:   []string{`data-laundry-id="laundry-1"`, "Weekly wash", "Washing", "Shirts"},
+			excludes:   []string{"<html", "text-red-600 bg-red-50"},
+		},
+		{
+			name:       "not found",
+			service:    &detailService{err: errorutils.ErrorNotFound},
+			userData:   user,
+			locale:     "id",
+			wantStatus: http.StatusNotFound,
+			contains:   []string{"data yang Anda minta tidak ditemukan"},
+			excludes:   []string{"data-laundry-id"},
+		},
+		{
+			name:       "signed out",
+			service:    &detailService{result: detail},
+			wantStatus: http.StatusUnauthorized,
+			excludes:   []string{"data-laundry-id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/laundry/laundry-1/details", nil)
+			newDetailRouter(t, tt.service, tt.userData, tt.locale).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.userData != nil && tt.service.userId != tt.userData.UserId {
+				t.Errorf("detail read for user %q, want %q", tt.service.userId, tt.userData.UserId)
+			}
+
+			body := recorder.Body.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q:\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q:\n%s", unwanted, body)
+				}
+			}
+		})
+	}
+}
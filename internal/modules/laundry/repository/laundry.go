@@ -2,14 +2,20 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
+	"errors"
 	"github.com/Masterminds/squirrel"
 	"github.com/audricimanuel/errorutils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	choreRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/repository"
+	vendorRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/jmoiron/sqlx"
 	"strings"
+	"time"
 )
 
 type (
@@ -19,7 +25,40 @@ type (
 		GetCategoryById(ctx context.Context, userId string, ids ...string) ([]model.CategoryResponse, error)
 		AddCategory(ctx context.Context, name string, userId ...string) error
 		IsExistedCategoryName(ctx context.Context, name, userId string) bool
-		AddLaundryData(ctx context.Context, userId string, request model.AddLaundryRequest) error
+		// AddLaundryData stores the routine and returns its id
+		AddLaundryData(ctx context.Context, userId string, request model.AddLaundryRequest) (string, error)
+		// InsertLaundry stores the routine with its items on the given transaction and returns its id.
+		// An occurrence of a recurring routine that already exists is skipped and an empty id is returned.
+		InsertLaundry(ctx context.Context, tx sqlx.ExtContext, userId string, data model.NewLaundry) (string, error)
+		// UpdateLaundryData replaces the title, date and items of the routine on the given transaction
+		UpdateLaundryData(ctx context.Context, tx sqlx.ExtContext, id string, data model.NewLaundry) error
+		// ImportLaundries stores the routines in one transaction, either all of them are created or none.
+		// The categories named in categoryNames are looked up by their CategoryKey and the missing ones are
+		// created first, build receives their ids by CategoryKey and returns the routines to store.
+		ImportLaundries(ctx context.Context, userId string, categoryNames []string, build func(categoryIds map[string]string) []model.NewLaundry) ([]string, error)
+		// GetLaundriesBetween returns the routines of the user planned between from (inclusive) and to (exclusive)
+		GetLaundriesBetween(ctx context.Context, userId string, from, to time.Time) ([]model.LaundryResponse, error)
+		// StreamLaundryExport calls fn with every item of the routines matching the list filters, one row at a time.
+		// Routines without items get a single row without category.
+		StreamLaundryExport(ctx context.Context, queryParam model.LaundryQueryParam, userId string, fn func(row model.LaundryExportRow) error) error
+		GetLaundryDetail(ctx context.Context, id, userId string) (*model.LaundryDetailResponse, error)
+		UpdateLaundryStatus(ctx context.Context, id, userId string, status constants.LaundryStatus) error
+		// RecordReturns stores the returned amount of the items by item id, then recounts the returned items of the
+		// routine and flags it when any item came back with a different amount
+		RecordReturns(ctx context.Context, id, userId string, returns map[string]int) error
+		// ResolveDiscrepancy stores the note explaining the discrepancy of the routine
+		ResolveDiscrepancy(ctx context.Context, id, userId, note string) error
+		// GetMissingItems returns the items of the user that came back short, the resolved ones only when includeResolved
+		GetMissingItems(ctx context.Context, userId string, includeResolved bool) ([]model.MissingItemResponse, error)
+		// GetWeeklySummary summarizes the routines of the user and their household between from (inclusive) and to
+		// (exclusive), which are the start of days in the user's timezone. The upcoming routines are the planned ones
+		// from to onwards.
+		GetWeeklySummary(ctx context.Context, userId string, from, to time.Time) (*model.LaundryWeeklySummary, error)
+		GetTemplateList(ctx context.Context, userId string) ([]model.LaundryTemplateResponse, error)
+		GetTemplate(ctx context.Context, id, userId string) (*model.LaundryTemplateResponse, error)
+		AddTemplate(ctx context.Context, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error)
+		UpdateTemplate(ctx context.Context, id, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error)
+		DeleteTemplate(ctx context.Context, id, userId string) error
 	}
 
 	LaundryRepositoryImpl struct {
@@ -39,29 +78,22 @@ func (l *LaundryRepositoryImpl) GetLaundryList(ctx context.Context, queryParam m
 	log := logging.WithContext(ctx)
 
 	offset := 0
-	limit := 10
+	limit := constants.LAUNDRY_LIST_LIMIT
 	if queryParam.Page > 1 {
 		offset += (queryParam.Page - 1) * limit
 	}
 
-	query := squirrel.Select("id, title, laundry_date, total_items, status").
-		From("laundries").
+	query := squirrel.Select("l.id, l.title, l.laundry_date, l.total_items, l.total_returned_items, l.has_discrepancy, l.status").
+		From("laundries l").
 		Limit(uint64(limit)).
 		Offset(uint64(offset))
 
-	// filter user id
+	// filter the routines the user can see, their own and the ones of their household
 	if len(userId) > 0 {
-		query = query.Where(squirrel.Eq{"user_id": userId})
+		query = query.Where(database.AccessibleBy("l.", userId[0], false))
 	}
 
-	// filter by laundry date
-	if laundryDateFrom := queryParam.LaundryDateFrom; laundryDateFrom != nil {
-		query = query.Where(squirrel.Expr(fmt.Sprintf(`DATE(laundry_date) >= %s`, laundryDateFrom.Format(constants.FORMAT_DATE_DEFAULT))))
-	}
-
-	if laundryDateTo := queryParam.LaundryDateTo; laundryDateTo != nil {
-		query = query.Where(squirrel.Expr(fmt.Sprintf(`DATE(laundry_date) <= %s`, laundryDateTo.Format(constants.FORMAT_DATE_DEFAULT))))
-	}
+	query = filterLaundries(query, queryParam)
 
 	sql, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
 
@@ -85,6 +117,48 @@ func (l *LaundryRepositoryImpl) GetLaundryList(ctx context.Context, queryParam m
 	return result, nil
 }
 
+func (l *LaundryRepositoryImpl) StreamLaundryExport(ctx context.Context, queryParam model.LaundryQueryParam, userId string, fn func(row model.LaundryExportRow) error) error {
+	log := logging.WithContext(ctx)
+
+	query := squirrel.Select("l.id AS laundry_id, l.title, l.laundry_date, l.status, c.name AS category_name, li.amount, li.notes").
+		From("laundries l").
+		LeftJoin("laundry_items li ON li.laundry_id = l.id").
+		LeftJoin("categories c ON c.id = li.category_id").
+		Where(database.AccessibleBy("l.", userId, false)).
+		OrderBy("l.laundry_date", "l.id", "c.name")
+
+	query = filterLaundries(query, queryParam)
+
+	sql, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	rows, err := l.db.PostgresDBSqlx.QueryxContext(ctx, sql, args...)
+	if err != nil {
+		log.Error("error when getting laundry export:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp model.LaundryExportRow
+		if err := rows.StructScan(&temp); err != nil {
+			log.Error("error when scanning row:", err)
+			return errorutils.DefineSQLError(err)
+		}
+
+		if err := fn(temp); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("error when reading laundry export:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
 func (l *LaundryRepositoryImpl) GetCategoryList(ctx context.Context, userId ...string) ([]model.CategoryResponse, error) {
 	var result []model.CategoryResponse
 	log := logging.WithContext(ctx)
@@ -94,7 +168,7 @@ func (l *LaundryRepositoryImpl) GetCategoryList(ctx context.Context, userId ...s
 		OrderBy(`"name"`)
 
 	if len(userId) > 0 {
-		baseQuery = baseQuery.Where(squirrel.Eq{"user_id": userId})
+		baseQuery = baseQuery.Where(database.AccessibleBy("", userId[0], false))
 	}
 
 	query, args := baseQuery.PlaceholderFormat(squirrel.Dollar).MustSql()
@@ -128,7 +202,7 @@ func (l *LaundryRepositoryImpl) GetCategoryById(ctx context.Context, userId stri
 		OrderBy(`name`)
 
 	if len(userId) > 0 {
-		baseQuery = baseQuery.Where(squirrel.Eq{"user_id": userId})
+		baseQuery = baseQuery.Where(database.AccessibleBy("", userId, false))
 	}
 
 	query, args := baseQuery.PlaceholderFormat(squirrel.Dollar).MustSql()
@@ -155,12 +229,19 @@ func (l *LaundryRepositoryImpl) GetCategoryById(ctx context.Context, userId stri
 }
 
 func (l *LaundryRepositoryImpl) AddCategory(ctx context.Context, name string, userId ...string) error {
-	query, args := squirrel.Insert("categories").
+	query := squirrel.Insert("categories").
 		Columns("name", "user_id").
-		Values(name, userId).
-		PlaceholderFormat(squirrel.Dollar).MustSql()
+		Values(name, userId)
+
+	if len(userId) > 0 {
+		query = squirrel.Insert("categories").
+			Columns("name", "user_id", "household_id").
+			Values(name, userId[0], database.HouseholdOf(userId[0]))
+	}
+
+	queryInsert, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
 
-	_, err := l.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	_, err := l.db.PostgresDBSqlx.ExecContext(ctx, queryInsert, args...)
 	if err != nil {
 		logging.WithContext(ctx).Error("error when adding category:", err)
 		return errorutils.DefineSQLError(err)
@@ -170,28 +251,591 @@ func (l *LaundryRepositoryImpl) AddCategory(ctx context.Context, name string, us
 }
 
 func (l *LaundryRepositoryImpl) IsExistedCategoryName(ctx context.Context, name, userId string) bool {
+	id, err := categoryIdByName(ctx, l.db.PostgresDBSqlx, userId, name)
+	if err != nil {
+		return false
+	}
+
+	return id != ""
+}
+
+func (l *LaundryRepositoryImpl) AddLaundryData(ctx context.Context, userId string, request model.AddLaundryRequest) (string, error) {
+	laundryDate, err := time.Parse(constants.FORMAT_DATE_DEFAULT, request.LaundryDate)
+	if err != nil {
+		return "", errorutils.ErrorBadRequest.CustomMessage("invalid laundry date")
+	}
+
+	var id string
+	err = database.WithTransaction(ctx, l.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		id, err = l.InsertLaundry(ctx, tx, userId, model.NewLaundry{
+			Title:       request.Title,
+			LaundryDate: laundryDate,
+			Items:       request.Items,
+		})
+		return err
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when adding laundry data:", err)
+		return "", errorutils.DefineSQLError(err)
+	}
+
+	return id, nil
+}
+
+func (l *LaundryRepositoryImpl) InsertLaundry(ctx context.Context, tx sqlx.ExtContext, userId string, data model.NewLaundry) (string, error) {
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Insert("laundries").
+		Columns("id", "user_id", "household_id", "title", "laundry_date", "laundry_time", "timezone", "total_items", "status", "series_id", "occurrence_at", "created_at", "updated_at").
+		Values(utils.GenerateCleanUUID(), userId, database.HouseholdOf(userId), data.Title, data.LaundryDate, data.LaundryTime, data.Timezone, totalItems(data.Items), constants.LAUNDRY_STATUS_PLANNED, data.SeriesId, data.OccurrenceAt, currentTime, currentTime).
+		Suffix("ON CONFLICT (series_id, occurrence_at) DO NOTHING RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var id string
+	if err := sqlx.GetContext(ctx, tx, &id, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if err := insertLaundryItems(ctx, tx, id, data.Items); err != nil {
+		return "", err
+	}
+
+	queryLog, args := squirrel.Insert("laundry_status_logs").
+		Columns("laundry_id", "status", "created_by", "created_at").
+		Values(id, constants.LAUNDRY_STATUS_PLANNED, userId, currentTime).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, queryLog, args...); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (l *LaundryRepositoryImpl) ImportLaundries(ctx context.Context, userId string, categoryNames []string, build func(categoryIds map[string]string) []model.NewLaundry) ([]string, error) {
+	ids := []string{}
+
+	err := database.WithTransaction(ctx, l.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		categoryIds := map[string]string{}
+		for _, name := range categoryNames {
+			id, err := ensureCategory(ctx, tx, userId, name)
+			if err != nil {
+				return err
+			}
+			categoryIds[CategoryKey(name)] = id
+		}
+
+		for _, laundry := range build(categoryIds) {
+			id, err := l.InsertLaundry(ctx, tx, userId, laundry)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when importing laundries:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return ids, nil
+}
+
+func (l *LaundryRepositoryImpl) GetLaundriesBetween(ctx context.Context, userId string, from, to time.Time) ([]model.LaundryResponse, error) {
+	result := []model.LaundryResponse{}
+
+	query, args := squirrel.Select("id, title, laundry_date, total_items, total_returned_items, has_discrepancy, status").
+		From("laundries").
+		Where(database.AccessibleBy("", userId, false)).
+		Where(squirrel.GtOrEq{"laundry_date": from}).
+		Where(squirrel.Lt{"laundry_date": to}).
+		OrderBy("laundry_date").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting laundries between dates:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (l *LaundryRepositoryImpl) UpdateLaundryData(ctx context.Context, tx sqlx.ExtContext, id string, data model.NewLaundry) error {
+	query, args := squirrel.Update("laundries").
+		Set("title", data.Title).
+		Set("laundry_date", data.LaundryDate).
+		Set("laundry_time", data.LaundryTime).
+		Set("timezone", data.Timezone).
+		Set("total_items", totalItems(data.Items)).
+		// the items are replaced so the returns recorded for the previous ones no longer apply
+		Set("total_returned_items", nil).
+		Set("has_discrepancy", false).
+		Set("resolution_note", nil).
+		Set("resolved_at", nil).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	queryDelete, args := squirrel.Delete("laundry_items").
+		Where(squirrel.Eq{"laundry_id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, queryDelete, args...); err != nil {
+		return err
+	}
+
+	return insertLaundryItems(ctx, tx, id, data.Items)
+}
+
+// CategoryKey is what category names are matched by: the name trimmed and lowercased
+func CategoryKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// categoryIdByName returns the id of the category the user can see whose name has the same CategoryKey
+func categoryIdByName(ctx context.Context, q sqlx.QueryerContext, userId, name string) (string, error) {
 	query, args := squirrel.Select("id").
 		From("categories").
-		Where(squirrel.And{
-			squirrel.Eq{"user_id": userId},
-			squirrel.Expr(fmt.Sprintf(`LOWER("name") = %s`, strings.ToLower(name))),
-		}).
+		Where(database.AccessibleBy("", userId, false)).
+		Where(`LOWER(TRIM("name")) = ?`, CategoryKey(name)).
 		Limit(1).
 		PlaceholderFormat(squirrel.Dollar).MustSql()
 
 	var id string
-	err := l.db.PostgresDBSqlx.QueryRowxContext(ctx, query, args...).Scan(&id)
+	err := sqlx.GetContext(ctx, q, &id, query, args...)
+	return id, err
+}
+
+// ensureCategory returns the id of the category the user can see with the given name, creating it when there's none
+func ensureCategory(ctx context.Context, tx sqlx.ExtContext, userId, name string) (string, error) {
+	id, err := categoryIdByName(ctx, tx, userId, name)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return id, err
+	}
+
+	queryInsert, args := squirrel.Insert("categories").
+		Columns("name", "user_id", "household_id").
+		Values(strings.TrimSpace(name), userId, database.HouseholdOf(userId)).
+		Suffix("RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	err = sqlx.GetContext(ctx, tx, &id, queryInsert, args...)
+	return id, err
+}
+
+func insertLaundryItems(ctx context.Context, tx sqlx.ExtContext, laundryId string, items []model.LaundryItemsRequest) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	query := squirrel.Insert("laundry_items").
+		Columns("id", "laundry_id", "category_id", "amount", "notes")
+
+	for _, item := range items {
+		query = query.Values(utils.GenerateCleanUUID(), laundryId, item.CategoryId, item.Amount, item.Notes)
+	}
+
+	queryItems, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	_, err := tx.ExecContext(ctx, queryItems, args...)
+	return err
+}
+
+func totalItems(items []model.LaundryItemsRequest) int {
+	total := 0
+	for _, item := range items {
+		total += item.Amount
+	}
+	return total
+}
+
+func (l *LaundryRepositoryImpl) GetLaundryDetail(ctx context.Context, id, userId string) (*model.LaundryDetailResponse, error) {
+	log := logging.WithContext(ctx)
+
+	query, args := squirrel.Select("id, title, laundry_date, laundry_time, timezone, total_items, total_returned_items, has_discrepancy, status, resolution_note, resolved_at, household_id").
+		Column(squirrel.Alias(database.AccessibleBy("", userId, true), "can_edit")).
+		From("laundries").
+		Where(squirrel.Eq{"id": id}).
+		Where(database.AccessibleBy("", userId, false)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.LaundryDetailResponse
+	if err := l.db.PostgresDBSqlx.QueryRowxContext(ctx, query, args...).StructScan(&result); err != nil {
+		log.Error("error when getting laundry detail:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryItems, args := squirrel.Select("li.id, li.category_id, c.name AS category_name, li.amount, li.returned_amount, li.notes").
+		From("laundry_items li").
+		Join("categories c ON c.id = li.category_id").
+		Where(squirrel.Eq{"li.laundry_id": id}).
+		OrderBy("c.name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result.Items = []model.LaundryItemResponse{}
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result.Items, queryItems, args...); err != nil {
+		log.Error("error when getting laundry items:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryLogs, args := squirrel.Select("status, created_at").
+		From("laundry_status_logs").
+		Where(squirrel.Eq{"laundry_id": id}).
+		OrderBy("created_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result.StatusLogs = []model.LaundryStatusLogResponse{}
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result.StatusLogs, queryLogs, args...); err != nil {
+		log.Error("error when getting laundry status logs:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryVendor, args := vendorRepository.SelectLaundryVendors().
+		Where(squirrel.Eq{"l.id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var vendor model.LaundryVendorResponse
+	if err := l.db.PostgresDBSqlx.GetContext(ctx, &vendor, queryVendor, args...); err == nil {
+		result.Vendor = &vendor
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Error("error when getting laundry vendor:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryAssignments, args := choreRepository.SelectAssignments().
+		Where(squirrel.Eq{"la.laundry_id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result.Assignments = []model.ChoreAssignmentResponse{}
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result.Assignments, queryAssignments, args...); err != nil {
+		log.Error("error when getting laundry assignments:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (l *LaundryRepositoryImpl) UpdateLaundryStatus(ctx context.Context, id, userId string, status constants.LaundryStatus) error {
+	log := logging.WithContext(ctx)
+
+	tx, err := l.db.PostgresDBSqlx.BeginTxx(ctx, nil)
 	if err != nil {
-		return false
+		log.Error("error when begin transaction:", err.Error())
+		return errorutils.ErrorInternalServer.CustomMessage(constants.TRANSACTION_FAILED)
 	}
 
-	return id != ""
+	defer tx.Rollback()
+
+	currentTime := utils.TimeNow()
+
+	queryUpdate, args := squirrel.Update("laundries").
+		Set("status", status).
+		Set("updated_at", currentTime).
+		Where(squirrel.Eq{"id": id}).
+		Where(database.AccessibleBy("", userId, true)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := tx.ExecContext(ctx, queryUpdate, args...)
+	if err != nil {
+		log.Error("error when updating laundry status:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	queryLog, args := squirrel.Insert("laundry_status_logs").
+		Columns("laundry_id", "status", "created_by", "created_at").
+		Values(id, status, userId, currentTime).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, queryLog, args...); err != nil {
+		log.Error("error when adding laundry status log:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("error when commit transaction:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (l *LaundryRepositoryImpl) RecordReturns(ctx context.Context, id, userId string, returns map[string]int) error {
+	err := database.WithTransaction(ctx, l.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		for itemId, returnedAmount := range returns {
+			query, args := squirrel.Update("laundry_items").
+				Set("returned_amount", returnedAmount).
+				Where(squirrel.Eq{"id": itemId, "laundry_id": id}).
+				PlaceholderFormat(squirrel.Dollar).MustSql()
+
+			res, err := tx.ExecContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+
+			if affected, _ := res.RowsAffected(); affected == 0 {
+				return sql.ErrNoRows
+			}
+		}
+
+		query, args := squirrel.Update("laundries l").
+			Set("total_returned_items", squirrel.Expr("(SELECT SUM(li.returned_amount) FROM laundry_items li WHERE li.laundry_id = l.id)")).
+			Set("has_discrepancy", squirrel.Expr("EXISTS (SELECT 1 FROM laundry_items li WHERE li.laundry_id = l.id AND li.returned_amount IS DISTINCT FROM li.amount)")).
+			Set("updated_at", utils.TimeNow()).
+			Where(squirrel.Eq{"l.id": id}).
+			Where(database.AccessibleBy("l.", userId, true)).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when recording returned items:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (l *LaundryRepositoryImpl) ResolveDiscrepancy(ctx context.Context, id, userId, note string) error {
+	query, args := squirrel.Update("laundries").
+		Set("resolution_note", note).
+		Set("resolved_at", utils.TimeNow()).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id}).
+		Where(database.AccessibleBy("", userId, true)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := l.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when resolving laundry discrepancy:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (l *LaundryRepositoryImpl) GetMissingItems(ctx context.Context, userId string, includeResolved bool) ([]model.MissingItemResponse, error) {
+	result := []model.MissingItemResponse{}
+
+	query := squirrel.Select("l.id AS laundry_id, l.title, l.laundry_date, li.id AS item_id, li.category_id, c.name AS category_name",
+		"li.amount, li.returned_amount, li.amount - li.returned_amount AS missing, l.resolution_note, l.resolved_at").
+		From("laundry_items li").
+		Join("laundries l ON l.id = li.laundry_id").
+		Join("categories c ON c.id = li.category_id").
+		Where(database.AccessibleBy("l.", userId, false)).
+		Where("l.has_discrepancy").
+		Where("li.returned_amount < li.amount").
+		OrderBy("l.laundry_date DESC", "c.name")
+
+	if !includeResolved {
+		query = query.Where(squirrel.Eq{"l.resolved_at": nil})
+	}
+
+	queryMissing, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result, queryMissing, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting missing items:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (l *LaundryRepositoryImpl) GetWeeklySummary(ctx context.Context, userId string, from, to time.Time) (*model.LaundryWeeklySummary, error) {
+	log := logging.WithContext(ctx)
+
+	// the status logs are timed in UTC while the routines are planned by their local date
+	dateFrom := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	queryCompleted, args := squirrel.Select("COUNT(DISTINCT lsl.laundry_id)").
+		From("laundry_status_logs lsl").
+		Join("laundries l ON l.id = lsl.laundry_id").
+		Where(database.AccessibleBy("l.", userId, false)).
+		Where(squirrel.Eq{"lsl.status": constants.LAUNDRY_STATUS_DONE}).
+		Where(squirrel.GtOrEq{"lsl.created_at": from.UTC()}).
+		Where(squirrel.Lt{"lsl.created_at": to.UTC()}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.LaundryWeeklySummary
+	if err := l.db.PostgresDBSqlx.GetContext(ctx, &result.Completed, queryCompleted, args...); err != nil {
+		log.Error("error when counting completed laundries:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryPending, args := squirrel.Select("COUNT(*)").
+		From("laundries").
+		Where(database.AccessibleBy("", userId, false)).
+		Where(squirrel.Eq{
+			"status": []constants.LaundryStatus{constants.LAUNDRY_STATUS_PLANNED, constants.LAUNDRY_STATUS_WASHING, constants.LAUNDRY_STATUS_DRYING},
+		}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := l.db.PostgresDBSqlx.GetContext(ctx, &result.Pending, queryPending, args...); err != nil {
+		log.Error("error when counting pending laundries:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryCategories, args := squirrel.Select("c.name AS category_name, SUM(li.amount) AS amount").
+		From("laundry_items li").
+		Join("laundries l ON l.id = li.laundry_id").
+		Join("categories c ON c.id = li.category_id").
+		Where(database.AccessibleBy("l.", userId, false)).
+		Where(squirrel.NotEq{"l.status": constants.LAUNDRY_STATUS_CANCELLED}).
+		Where(squirrel.GtOrEq{"l.laundry_date": dateFrom}).
+		Where(squirrel.Lt{"l.laundry_date": dateTo}).
+		GroupBy("c.name").
+		OrderBy("amount DESC", "c.name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result.Categories = []model.LaundryCategoryAmount{}
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result.Categories, queryCategories, args...); err != nil {
+		log.Error("error when getting laundry amount per category:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	queryUpcoming, args := squirrel.Select("id, title, laundry_date, total_items, total_returned_items, has_discrepancy, status").
+		From("laundries").
+		Where(database.AccessibleBy("", userId, false)).
+		Where(squirrel.Eq{"status": constants.LAUNDRY_STATUS_PLANNED}).
+		Where(squirrel.GtOrEq{"laundry_date": dateTo}).
+		OrderBy("laundry_date", "laundry_time NULLS FIRST").
+		Limit(constants.LAUNDRY_UPCOMING_LIMIT).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	result.Upcoming = []model.LaundryResponse{}
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result.Upcoming, queryUpcoming, args...); err != nil {
+		log.Error("error when getting upcoming laundries:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
 }
 
-func (l *LaundryRepositoryImpl) AddLaundryData(ctx context.Context, userId string, request model.AddLaundryRequest) error {
-	// TODO: add main laundry data
+func (l *LaundryRepositoryImpl) GetTemplateList(ctx context.Context, userId string) ([]model.LaundryTemplateResponse, error) {
+	result := []model.LaundryTemplateResponse{}
 
-	// TODO: add laundry items
+	query, args := squirrel.Select("id, name, items, created_at, updated_at").
+		From("laundry_templates").
+		Where(squirrel.Eq{"user_id": userId}).
+		OrderBy("name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := l.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting template list:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (l *LaundryRepositoryImpl) GetTemplate(ctx context.Context, id, userId string) (*model.LaundryTemplateResponse, error) {
+	query, args := squirrel.Select("id, name, items, created_at, updated_at").
+		From("laundry_templates").
+		Where(squirrel.Eq{"id": id, "user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.LaundryTemplateResponse
+	if err := l.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting template:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (l *LaundryRepositoryImpl) AddTemplate(ctx context.Context, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error) {
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Insert("laundry_templates").
+		Columns("id", "user_id", "name", "items", "created_at", "updated_at").
+		Values(utils.GenerateCleanUUID(), userId, strings.TrimSpace(request.Name), model.LaundryItems(request.Items), currentTime, currentTime).
+		Suffix("RETURNING id, name, items, created_at, updated_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.LaundryTemplateResponse
+	if err := l.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when adding template:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (l *LaundryRepositoryImpl) UpdateTemplate(ctx context.Context, id, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error) {
+	query, args := squirrel.Update("laundry_templates").
+		Set("name", strings.TrimSpace(request.Name)).
+		Set("items", model.LaundryItems(request.Items)).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id, "user_id": userId}).
+		Suffix("RETURNING id, name, items, created_at, updated_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.LaundryTemplateResponse
+	if err := l.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when updating template:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (l *LaundryRepositoryImpl) DeleteTemplate(ctx context.Context, id, userId string) error {
+	query, args := squirrel.Delete("laundry_templates").
+		Where(squirrel.Eq{"id": id, "user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := l.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when deleting template:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
 
 	return nil
 }
+
+// filterLaundries applies the filters of the laundry list to a query on the laundries table aliased as l
+func filterLaundries(query squirrel.SelectBuilder, queryParam model.LaundryQueryParam) squirrel.SelectBuilder {
+	// filter by category, routines having at least one item of the category
+	if categoryName := strings.TrimSpace(queryParam.CategoryName); categoryName != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM laundry_items fli JOIN categories fc ON fc.id = fli.category_id WHERE fli.laundry_id = l.id AND LOWER(fc.name) = LOWER(?))", categoryName)
+	}
+
+	// filter by laundry date
+	if laundryDateFrom := queryParam.LaundryDateFrom; laundryDateFrom != nil {
+		query = query.Where("DATE(l.laundry_date) >= ?", laundryDateFrom.Format(constants.FORMAT_DATE_DEFAULT))
+	}
+
+	if laundryDateTo := queryParam.LaundryDateTo; laundryDateTo != nil {
+		query = query.Where("DATE(l.laundry_date) <= ?", laundryDateTo.Format(constants.FORMAT_DATE_DEFAULT))
+	}
+
+	return query
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"io"
+)
+
+func (l *LaundryServiceImpl) ExportLaundries(ctx context.Context, userId string, queryParam model.LaundryQueryParam, format string, w io.Writer) error {
+	locale := i18n.LocaleFromContext(ctx)
+
+	writer, err := tools.NewSpreadsheetWriter(format, i18n.T(locale, "export.sheet_name"), w)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when starting laundry export:", err)
+		return errorutils.ErrorBadRequest.CustomMessage("unsupported export format")
+	}
+
+	err = writer.WriteRow(
+		i18n.T(locale, "export.title"),
+		i18n.T(locale, "export.laundry_date"),
+		i18n.T(locale, "export.status"),
+		i18n.T(locale, "export.category"),
+		i18n.T(locale, "export.amount"),
+		i18n.T(locale, "export.notes"),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = l.laundryRepository.StreamLaundryExport(ctx, queryParam, userId, func(row model.LaundryExportRow) error {
+		var amount any
+		if row.Amount != nil {
+			amount = *row.Amount
+		}
+
+		return writer.WriteRow(
+			row.Title,
+			row.LaundryDate.Format(constants.FORMAT_DATE_DEFAULT),
+			model.LaundryStatusLabel(locale, constants.LaundryStatus(row.Status)),
+			optionalString(row.CategoryName),
+			amount,
+			optionalString(row.Notes),
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func optionalString(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,507 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/ical"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// csvDateLayouts are the date formats a CSV import accepts
+var csvDateLayouts = map[string]string{
+	"YYYY-MM-DD": "2006-01-02",
+	"DD/MM/YYYY": "02/01/2006",
+	"MM/DD/YYYY": "01/02/2006",
+	"DD-MM-YYYY": "02-01-2006",
+}
+
+func (l *LaundryServiceImpl) PreviewICSImport(ctx context.Context, userId string, file io.Reader) (*model.LaundryImportPreview, error) {
+	rows, err := icsImportRows(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.markDuplicates(ctx, userId, rows); err != nil {
+		return nil, err
+	}
+
+	return importPreview(i18n.LocaleFromContext(ctx), rows), nil
+}
+
+func (l *LaundryServiceImpl) ImportICS(ctx context.Context, userId string, file io.Reader, request model.LaundryImportRequest) (*model.LaundryImportResult, error) {
+	rows, err := icsImportRows(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.importRows(ctx, userId, rows, request)
+}
+
+func (l *LaundryServiceImpl) ImportCSV(ctx context.Context, userId string, file io.Reader, request model.LaundryCSVImportRequest) (*model.LaundryCSVImportResult, error) {
+	rows, err := csvImportRows(file, request)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := l.laundryRepository.GetCategoryList(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	existingCategories := map[string]bool{}
+	for _, category := range categories {
+		existingCategories[repository.CategoryKey(category.Name)] = true
+	}
+
+	dates := []time.Time{}
+	for _, row := range rows {
+		if row.Error == nil {
+			dates = append(dates, *row.LaundryDate)
+		}
+	}
+
+	existing, err := l.existingLaundryKeys(ctx, userId, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.LaundryCSVImportResult{
+		DryRun:            request.DryRun,
+		Rows:              rows,
+		CategoriesCreated: []string{},
+		Ids:               []string{},
+	}
+
+	// the lines of the same routine are grouped in the order the routines first appear
+	routineKeys := []string{}
+	routineRows := map[string][]int{}
+	categoryNames := []string{}
+	seenCategories := map[string]bool{}
+
+	for i := range rows {
+		if rows[i].Error != nil {
+			rows[i].Result = constants.LAUNDRY_IMPORT_ROW_FAILED
+			result.Failed++
+			continue
+		}
+
+		key := duplicateKey(rows[i].Title, *rows[i].LaundryDate)
+		if existing[key] {
+			rows[i].Result = constants.LAUNDRY_IMPORT_ROW_SKIPPED
+			result.Skipped++
+			continue
+		}
+
+		if _, ok := routineRows[key]; !ok {
+			routineKeys = append(routineKeys, key)
+		}
+		routineRows[key] = append(routineRows[key], i)
+
+		// every name is resolved by the import, creating the ones the user doesn't have yet
+		categoryKey := repository.CategoryKey(rows[i].CategoryName)
+		if !seenCategories[categoryKey] {
+			seenCategories[categoryKey] = true
+			categoryNames = append(categoryNames, rows[i].CategoryName)
+			if !existingCategories[categoryKey] {
+				result.CategoriesCreated = append(result.CategoriesCreated, rows[i].CategoryName)
+			}
+		}
+
+		rows[i].Result = constants.LAUNDRY_IMPORT_ROW_CREATED
+		result.Created++
+	}
+
+	result.RoutinesCreated = len(routineKeys)
+
+	locale := i18n.LocaleFromContext(ctx)
+	for i := range rows {
+		if rows[i].Error != nil {
+			translated := i18n.TranslateError(locale, *rows[i].Error)
+			rows[i].Error = &translated
+		}
+	}
+
+	if request.DryRun || len(routineKeys) == 0 {
+		return result, nil
+	}
+
+	ids, err := l.laundryRepository.ImportLaundries(ctx, userId, categoryNames, func(categoryIds map[string]string) []model.NewLaundry {
+		data := make([]model.NewLaundry, 0, len(routineKeys))
+		for _, key := range routineKeys {
+			first := rows[routineRows[key][0]]
+
+			items := []model.LaundryItemsRequest{}
+			for _, i := range routineRows[key] {
+				items = append(items, model.LaundryItemsRequest{
+					CategoryId: categoryIds[repository.CategoryKey(rows[i].CategoryName)],
+					Amount:     rows[i].Amount,
+					Notes:      rows[i].Notes,
+				})
+			}
+
+			data = append(data, model.NewLaundry{
+				Title:       first.Title,
+				LaundryDate: *first.LaundryDate,
+				Items:       items,
+			})
+		}
+		return data
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Ids = ids
+
+	return result, nil
+}
+
+// importRows creates the valid rows in one transaction, the duplicates are skipped unless they're included
+func (l *LaundryServiceImpl) importRows(ctx context.Context, userId string, rows []model.LaundryImportRow, request model.LaundryImportRequest) (*model.LaundryImportResult, error) {
+	if err := l.markDuplicates(ctx, userId, rows); err != nil {
+		return nil, err
+	}
+
+	items := []model.LaundryItemsRequest{}
+	if request.TemplateId != "" {
+		template, err := l.laundryRepository.GetTemplate(ctx, request.TemplateId, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := l.ValidateItems(ctx, userId, template.Items); err != nil {
+			return nil, err
+		}
+		items = template.Items
+	}
+
+	result := &model.LaundryImportResult{}
+	data := []model.NewLaundry{}
+	for _, row := range rows {
+		switch {
+		case row.Error != nil:
+			result.SkippedInvalid++
+		case row.Duplicate && !request.IncludeDuplicates:
+			result.SkippedDuplicates++
+		default:
+			data = append(data, model.NewLaundry{
+				Title:       row.Title,
+				LaundryDate: *row.LaundryDate,
+				Items:       items,
+			})
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("there is nothing to import")
+	}
+
+	ids, err := l.laundryRepository.ImportLaundries(ctx, userId, nil, func(map[string]string) []model.NewLaundry {
+		return data
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Ids = ids
+	result.Created = len(ids)
+
+	return result, nil
+}
+
+// markDuplicates flags the rows whose title is already planned on the same date, by the user or by an earlier row
+func (l *LaundryServiceImpl) markDuplicates(ctx context.Context, userId string, rows []model.LaundryImportRow) error {
+	dates := []time.Time{}
+	for _, row := range rows {
+		if row.Error == nil {
+			dates = append(dates, *row.LaundryDate)
+		}
+	}
+
+	seen, err := l.existingLaundryKeys(ctx, userId, dates)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		if rows[i].Error != nil {
+			continue
+		}
+
+		key := duplicateKey(rows[i].Title, *rows[i].LaundryDate)
+		rows[i].Duplicate = seen[key]
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// existingLaundryKeys returns the duplicate keys of the user's routines planned on the days of the given dates
+func (l *LaundryServiceImpl) existingLaundryKeys(ctx context.Context, userId string, dates []time.Time) (map[string]bool, error) {
+	result := map[string]bool{}
+
+	var from, to time.Time
+	for _, date := range dates {
+		if from.IsZero() || date.Before(from) {
+			from = date
+		}
+		if date.After(to) {
+			to = date
+		}
+	}
+
+	if from.IsZero() {
+		return result, nil
+	}
+
+	existing, err := l.laundryRepository.GetLaundriesBetween(ctx, userId, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, laundry := range existing {
+		result[duplicateKey(laundry.Title, laundry.LaundryDate)] = true
+	}
+
+	return result, nil
+}
+
+// icsImportRows maps the events of the calendar to routines: the summary becomes the title
+// and the date of the start the laundry date
+func icsImportRows(file io.Reader) ([]model.LaundryImportRow, error) {
+	calendar, err := ical.Parse(file)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid calendar file")
+	}
+
+	if len(calendar.Events) > constants.LAUNDRY_IMPORT_MAX_ROWS {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the file has too many rows")
+	}
+
+	rows := make([]model.LaundryImportRow, 0, len(calendar.Events))
+	for i, event := range calendar.Events {
+		row := model.LaundryImportRow{
+			Line:      i + 1,
+			Title:     strings.TrimSpace(event.Summary),
+			Recurring: event.RRule != "",
+		}
+
+		if !event.Start.IsZero() {
+			laundryDate := icsLaundryDate(event)
+			row.LaundryDate = &laundryDate
+		}
+
+		row.Error = validateImportRow(row.Title, row.LaundryDate)
+		if row.Error == nil && event.Status == ical.STATUS_CANCELLED {
+			message := "the event is cancelled"
+			row.Error = &message
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// icsLaundryDate is the date of the start of the event, in the timezone its start time was given in
+func icsLaundryDate(event ical.Event) time.Time {
+	if event.AllDay {
+		return event.Start
+	}
+
+	location, err := utils.LoadTimezone(event.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	laundryDate, _, _ := model.SplitPlannedAt(event.Start, location)
+	return laundryDate
+}
+
+// csvImportRows reads the lines of the file through the column mapping, the lines that can't be imported get an error
+func csvImportRows(file io.Reader, request model.LaundryCSVImportRequest) ([]model.LaundryCSVImportRow, error) {
+	dateLayout, ok := csvDateLayouts[strings.ToUpper(defaultValue(request.DateFormat, "YYYY-MM-DD"))]
+	if !ok {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("unsupported date format")
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	switch request.Delimiter {
+	case "", ",":
+	case ";":
+		reader.Comma = ';'
+	case "tab":
+		reader.Comma = '\t'
+	default:
+		return nil, errorutils.ErrorBadRequest.CustomMessage("unsupported delimiter")
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid csv file")
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\uFEFF")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	index := func(column, defaultColumn string) int {
+		if i, ok := columns[strings.ToLower(strings.TrimSpace(defaultValue(column, defaultColumn)))]; ok {
+			return i
+		}
+		return -1
+	}
+
+	titleIndex := index(request.TitleColumn, "title")
+	dateIndex := index(request.DateColumn, "date")
+	categoryIndex := index(request.CategoryColumn, "category")
+	amountIndex := index(request.AmountColumn, "amount")
+	notesIndex := index(request.NotesColumn, "notes")
+
+	if titleIndex < 0 || dateIndex < 0 || categoryIndex < 0 || amountIndex < 0 {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the file doesn't have the mapped columns")
+	}
+
+	rows := []model.LaundryCSVImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("invalid csv file")
+		}
+
+		// the empty lines are skipped by the reader, the line is read back from the position of the record
+		line, _ := reader.FieldPos(0)
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		if len(rows) == constants.LAUNDRY_IMPORT_MAX_ROWS {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("the file has too many rows")
+		}
+
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := model.LaundryCSVImportRow{
+			Line:         line,
+			Title:        field(titleIndex),
+			CategoryName: field(categoryIndex),
+		}
+
+		if notes := field(notesIndex); notes != "" {
+			row.Notes = &notes
+		}
+
+		if laundryDate, err := time.Parse(dateLayout, field(dateIndex)); err == nil {
+			row.LaundryDate = &laundryDate
+		}
+
+		amount, errAmount := strconv.Atoi(field(amountIndex))
+		row.Amount = amount
+
+		row.Error = validateImportRow(row.Title, row.LaundryDate)
+		if row.Error == nil {
+			var message string
+			switch {
+			case row.CategoryName == "":
+				message = "category is required"
+			case errAmount != nil || amount < 1:
+				message = "amount must be a positive number"
+			}
+			if message != "" {
+				row.Error = &message
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// validateImportRow checks the fields every imported routine needs, returning why the row can't be imported
+func validateImportRow(title string, laundryDate *time.Time) *string {
+	var message string
+
+	switch {
+	case title == "":
+		message = "title is required"
+	case utf8.RuneCountInString(title) > constants.LAUNDRY_TITLE_MAX_LENGTH:
+		message = "title is too long"
+	case laundryDate == nil:
+		message = "invalid laundry date"
+	default:
+		return nil
+	}
+
+	return &message
+}
+
+// importPreview counts the rows and translates their errors
+func importPreview(locale string, rows []model.LaundryImportRow) *model.LaundryImportPreview {
+	result := &model.LaundryImportPreview{
+		Rows:  rows,
+		Total: len(rows),
+	}
+
+	for i := range rows {
+		switch {
+		case rows[i].Error != nil:
+			translated := i18n.TranslateError(locale, *rows[i].Error)
+			rows[i].Error = &translated
+			result.Invalid++
+		case rows[i].Duplicate:
+			result.Duplicates++
+			result.Valid++
+		default:
+			result.Valid++
+		}
+	}
+
+	return result
+}
+
+func duplicateKey(title string, laundryDate time.Time) string {
+	return laundryDate.Format(constants.FORMAT_DATE_DEFAULT) + "|" + strings.ToLower(strings.TrimSpace(title))
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func defaultValue(value, defaultValue string) string {
+	if strings.TrimSpace(value) == "" {
+		return defaultValue
+	}
+	return value
+}
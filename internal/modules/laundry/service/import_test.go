@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// importedRow is the part of an imported row the tests compare, Date is empty when the date couldn't be read
+type importedRow struct {
+	Line      int
+	Title     string
+	Date      string
+	Category  string
+	Amount    int
+	Notes     string
+	Recurring bool
+	Error     string
+}
+
+func csvImportedRows(rows []model.LaundryCSVImportRow) []importedRow {
+	result := make([]importedRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, importedRow{
+			Line:     row.Line,
+			Title:    row.Title,
+			Date:     formatImportedDate(row.LaundryDate),
+			Category: row.CategoryName,
+			Amount:   row.Amount,
+			Notes:    valueOf(row.Notes),
+			Error:    valueOf(row.Error),
+		})
+	}
+	return result
+}
+
+func icsImportedRows(rows []model.LaundryImportRow) []importedRow {
+	result := make([]importedRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, importedRow{
+			Line:      row.Line,
+			Title:     row.Title,
+			Date:      formatImportedDate(row.LaundryDate),
+			Recurring: row.Recurring,
+			Error:     valueOf(row.Error),
+		})
+	}
+	return result
+}
+
+func formatImportedDate(laundryDate *time.Time) string {
+	if laundryDate == nil {
+		return ""
+	}
+	return laundryDate.Format(time.RFC3339)
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func TestCSVImportRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		request    model.LaundryCSVImportRequest
+		want       []importedRow
+		wantStatus int
+	}{
+		{
+			name:  "default mapping",
+			input: "title,date,category,amount,notes\nWeekly,2024-03-02,Shirts,3,white only\nWeekly,2024-03-02,Towels,2,\n",
+			want: []importedRow{
+				{Line: 2, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Category: "Shirts", Amount: 3, Notes: "white only"},
+				{Line: 3, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Category: "Towels", Amount: 2},
+			},
+		},
+		{
+			name:    "byte order mark, semicolons and blank lines",
+			input:   "\uFEFFTitle;Date;Category;Amount\n\n;;;\nWeekly; 02/03/2024;Shirts;3\n",
+			request: model.LaundryCSVImportRequest{Delimiter: ";", DateFormat: "dd/mm/yyyy"},
+			want: []importedRow{
+				{Line: 4, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Category: "Shirts", Amount: 3},
+			},
+		},
+		{
+			name:  "mapped columns",
+			input: "Name\tDay\tKind\tQty\tRemark\nWeekly\t03/02/2024\tShirts\t3\tsoft\n",
+			request: model.LaundryCSVImportRequest{
+				Delimiter:      "tab",
+				DateFormat:     "MM/DD/YYYY",
+				TitleColumn:    "name",
+				DateColumn:     "day",
+				CategoryColumn: "kind",
+				AmountColumn:   "qty",
+				NotesColumn:    "remark",
+			},
+			want: []importedRow{
+				{Line: 2, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Category: "Shirts", Amount: 3, Notes: "soft"},
+			},
+		},
+		{
+			name: "invalid lines",
+			input: "title,date,category,amount\n,2024-03-02,Shirts,3\nWeekly,02-03-2024,Shirts,3\nWeekly,2024-03-02,,3\n" +
+				"Weekly,2024-03-02,Shirts,none\nWeekly,2024-03-02,Shirts,0\nWeekly,2024-03-02\n" + strings.Repeat("a", constants.LAUNDRY_TITLE_MAX_LENGTH+1) + ",2024-03-02,Shirts,1\n",
+			want: []importedRow{
+				{Line: 2, Date: "2024-03-02T00:00:00Z", Category: "Shirts", Amount: 3, Error: "title is required"},
+				{Line: 3, Title: "Weekly", Category: "Shirts", Amount: 3, Error: "invalid laundry date"},
+				{Line: 4, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Amount: 3, Error: "category is required"},
+				{Line: 5, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Category: "Shirts", Error: "amount must be a positive number"},
+				{Line: 6, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Category: "Shirts", Error: "amount must be a positive number"},
+				{Line: 7, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Error: "category is required"},
+				{Line: 8, Title: strings.Repeat("a", constants.LAUNDRY_TITLE_MAX_LENGTH+1), Date: "2024-03-02T00:00:00Z", Category: "Shirts", Amount: 1, Error: "title is too long"},
+			},
+		},
+		{
+			name:       "unsupported delimiter",
+			input:      "title,date,category,amount\n",
+			request:    model.LaundryCSVImportRequest{Delimiter: "|"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported date format",
+			input:      "title,date,category,amount\n",
+			request:    model.LaundryCSVImportRequest{DateFormat: "YYYY/MM/DD"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unmapped column",
+			input:      "title,date,amount\nWeekly,2024-03-02,3\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty file",
+			input:      "",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too many rows",
+			input:      "title,date,category,amount\n" + strings.Repeat("Weekly,2024-03-02,Shirts,3\n", constants.LAUNDRY_IMPORT_MAX_ROWS+1),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := csvImportRows(strings.NewReader(tt.input), tt.request)
+			if tt.wantStatus != 0 {
+				if status, _ := errorutils.GetStatusCode(err); status != tt.wantStatus {
+					t.Fatalf("status = %d (%v), want %d", status, err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("csvImportRows: %v", err)
+			}
+
+			if got := csvImportedRows(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestICSImportRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     string
+		want       []importedRow
+		wantStatus int
+	}{
+		{
+			name:   "all day event",
+			events: "BEGIN:VEVENT\nSUMMARY: Weekly \nDTSTART;VALUE=DATE:20240302\nRRULE:FREQ=WEEKLY\nEND:VEVENT\n",
+			want:   []importedRow{{Line: 1, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Recurring: true}},
+		},
+		{
+			name:   "local date of the start",
+			events: "BEGIN:VEVENT\nSUMMARY:Weekly\nDTSTART;TZID=Asia/Jakarta:20240302T233000\nEND:VEVENT\n",
+			want:   []importedRow{{Line: 1, Title: "Weekly", Date: "2024-03-02T00:00:00Z"}},
+		},
+		{
+			name:   "utc start",
+			events: "BEGIN:VEVENT\nSUMMARY:Weekly\nDTSTART:20240302T233000Z\nEND:VEVENT\n",
+			want:   []importedRow{{Line: 1, Title: "Weekly", Date: "2024-03-02T00:00:00Z"}},
+		},
+		{
+			name: "invalid events",
+			events: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240302\nEND:VEVENT\nBEGIN:VEVENT\nSUMMARY:Weekly\nEND:VEVENT\n" +
+				"BEGIN:VEVENT\nSUMMARY:Weekly\nDTSTART;VALUE=DATE:20240302\nSTATUS:CANCELLED\nEND:VEVENT\n",
+			want: []importedRow{
+				{Line: 1, Date: "2024-03-02T00:00:00Z", Error: "title is required"},
+				{Line: 2, Title: "Weekly", Error: "invalid laundry date"},
+				{Line: 3, Title: "Weekly", Date: "2024-03-02T00:00:00Z", Error: "the event is cancelled"},
+			},
+		},
+		{
+			name:       "too many events",
+			events:     strings.Repeat("BEGIN:VEVENT\nSUMMARY:Weekly\nEND:VEVENT\n", constants.LAUNDRY_IMPORT_MAX_ROWS+1),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := icsImportRows(strings.NewReader("BEGIN:VCALENDAR\n" + tt.events + "END:VCALENDAR\n"))
+			if tt.wantStatus != 0 {
+				if status, _ := errorutils.GetStatusCode(err); status != tt.wantStatus {
+					t.Fatalf("status = %d (%v), want %d", status, err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("icsImportRows: %v", err)
+			}
+
+			if got := icsImportedRows(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := icsImportRows(strings.NewReader("not a calendar")); err == nil {
+		t.Error("icsImportRows accepted a file that isn't a calendar")
+	}
+}
+
+// importRepository has the categories of the user and stores the imported routines, the categories it creates
+// get their name as id
+type importRepository struct {
+	repository.LaundryRepository
+	categories    []model.CategoryResponse
+	categoryNames []string
+	imported      []model.NewLaundry
+}
+
+func (i *importRepository) GetCategoryList(ctx context.Context, userId ...string) ([]model.CategoryResponse, error) {
+	return i.categories, nil
+}
+
+func (i *importRepository) GetLaundriesBetween(ctx context.Context, userId string, from, to time.Time) ([]model.LaundryResponse, error) {
+	return nil, nil
+}
+
+func (i *importRepository) ImportLaundries(ctx context.Context, userId string, categoryNames []string, build func(categoryIds map[string]string) []model.NewLaundry) ([]string, error) {
+	categoryIds := map[string]string{}
+	for _, category := range i.categories {
+		categoryIds[repository.CategoryKey(category.Name)] = category.Id
+	}
+	for _, name := range categoryNames {
+		if _, ok := categoryIds[repository.CategoryKey(name)]; !ok {
+			categoryIds[repository.CategoryKey(name)] = name
+		}
+	}
+
+	i.categoryNames = categoryNames
+	i.imported = build(categoryIds)
+	return []string{"laundry-1"}, nil
+}
+
+func TestImportCSVCategories(t *testing.T) {
+	laundryRepository := &importRepository{categories: []model.CategoryResponse{{Id: "category-1", Name: " Shirts "}}}
+	input := "title,date,category,amount\nWeekly,2024-03-02,shirts,3\nWeekly,2024-03-02,Towels,2\nWeekly,2024-03-02, towels,1\n"
+
+	result, err := NewLaundryService(laundryRepository).ImportCSV(context.Background(), "user-1", strings.NewReader(input), model.LaundryCSVImportRequest{})
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+
+	if !reflect.DeepEqual(result.CategoriesCreated, []string{"Towels"}) {
+		t.Errorf("categories created = %v, want [Towels]", result.CategoriesCreated)
+	}
+	if !reflect.DeepEqual(laundryRepository.categoryNames, []string{"shirts", "Towels"}) {
+		t.Errorf("category names = %v, want [shirts Towels]", laundryRepository.categoryNames)
+	}
+
+	if len(laundryRepository.imported) != 1 {
+		t.Fatalf("imported %d routines, want 1", len(laundryRepository.imported))
+	}
+	categoryIds := []string{}
+	for _, item := range laundryRepository.imported[0].Items {
+		categoryIds = append(categoryIds, item.CategoryId)
+	}
+	if want := []string{"category-1", "Towels", "Towels"}; !reflect.DeepEqual(categoryIds, want) {
+		t.Errorf("item categories = %v, want %v", categoryIds, want)
+	}
+}
+
+func TestCategoryKey(t *testing.T) {
+	for _, name := range []string{"Shirts", " shirts", "SHIRTS \t"} {
+		if key := repository.CategoryKey(name); key != "shirts" {
+			t.Errorf("CategoryKey(%q) = %q, want shirts", name, key)
+		}
+	}
+}
@@ -3,15 +3,49 @@ package service
 import (
 	"context"
 	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"io"
 )
 
 type (
 	LaundryService interface {
 		GetLaundryList(ctx context.Context, queryParam model.LaundryQueryParam, userId string) ([]model.LaundryResponse, error)
-		AddLaundry(ctx context.Context, userId string, request model.AddLaundryRequest) error
+		// ExportLaundries writes the items of the routines matching the list filters to w as a CSV or XLSX file,
+		// one row per item. The rows are written as they're read, nothing is written when the format isn't supported.
+		ExportLaundries(ctx context.Context, userId string, queryParam model.LaundryQueryParam, format string, w io.Writer) error
+		AddLaundry(ctx context.Context, userId string, request model.AddLaundryRequest) (*model.LaundryDetailResponse, error)
+		// AddLaundryFromTemplate creates a routine with the items of a saved template
+		AddLaundryFromTemplate(ctx context.Context, userId, templateId string, request model.CopyLaundryRequest) (*model.LaundryDetailResponse, error)
+		// CloneLaundry creates a routine with the title and items of another routine
+		CloneLaundry(ctx context.Context, userId, id string, request model.CopyLaundryRequest) (*model.LaundryDetailResponse, error)
+		// PreviewICSImport reads the events of a calendar file as routines without creating them,
+		// flagging the ones that can't be imported and the duplicates of existing routines
+		PreviewICSImport(ctx context.Context, userId string, file io.Reader) (*model.LaundryImportPreview, error)
+		// ImportICS creates the routines read from a calendar file, all at once
+		ImportICS(ctx context.Context, userId string, file io.Reader, request model.LaundryImportRequest) (*model.LaundryImportResult, error)
+		// ImportCSV creates the routines read from a CSV file through the column mapping in one transaction, creating
+		// the categories that don't exist yet. A dry run only reports the outcome of every row.
+		ImportCSV(ctx context.Context, userId string, file io.Reader, request model.LaundryCSVImportRequest) (*model.LaundryCSVImportResult, error)
+		// ValidateItems checks that every item refers to a category of the user
+		ValidateItems(ctx context.Context, userId string, items []model.LaundryItemsRequest) error
+		GetLaundryDetail(ctx context.Context, id, userId string) (*model.LaundryDetailResponse, error)
+		UpdateLaundryStatus(ctx context.Context, id, userId string, status constants.LaundryStatus) (*model.LaundryDetailResponse, error)
+		// RecordReturns stores how many of every item of the routine came back, flagging the routine when they differ
+		// from what was sent
+		RecordReturns(ctx context.Context, id, userId string, request model.LaundryReturnsRequest) (*model.LaundryDetailResponse, error)
+		// ResolveDiscrepancy attaches a note explaining the discrepancy of the routine
+		ResolveDiscrepancy(ctx context.Context, id, userId string, request model.LaundryResolutionRequest) (*model.LaundryDetailResponse, error)
+		// GetMissingItems reports the items that came back short across the routines, by default only the unresolved ones
+		GetMissingItems(ctx context.Context, userId string, includeResolved bool) (*model.MissingItemsReport, error)
+		GetTemplateList(ctx context.Context, userId string) ([]model.LaundryTemplateResponse, error)
+		GetTemplate(ctx context.Context, id, userId string) (*model.LaundryTemplateResponse, error)
+		AddTemplate(ctx context.Context, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error)
+		UpdateTemplate(ctx context.Context, id, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error)
+		DeleteTemplate(ctx context.Context, id, userId string) error
 	}
 
 	LaundryServiceImpl struct {
@@ -26,23 +60,155 @@ func NewLaundryService(laundryRepo repository.LaundryRepository) LaundryService
 }
 
 func (l *LaundryServiceImpl) GetLaundryList(ctx context.Context, queryParam model.LaundryQueryParam, userId string) ([]model.LaundryResponse, error) {
-	return l.laundryRepository.GetLaundryList(ctx, queryParam, userId)
+	result, err := l.laundryRepository.GetLaundryList(ctx, queryParam, userId)
+	if err != nil {
+		return result, err
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+	for i := range result {
+		result[i].FillDisplayFields(locale)
+	}
+
+	return result, nil
+}
+
+func (l *LaundryServiceImpl) AddLaundry(ctx context.Context, userId string, request model.AddLaundryRequest) (*model.LaundryDetailResponse, error) {
+	if err := l.ValidateItems(ctx, userId, request.Items); err != nil {
+		return nil, err
+	}
+
+	id, err := l.laundryRepository.AddLaundryData(ctx, userId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.GetLaundryDetail(ctx, id, userId)
+}
+
+func (l *LaundryServiceImpl) AddLaundryFromTemplate(ctx context.Context, userId, templateId string, request model.CopyLaundryRequest) (*model.LaundryDetailResponse, error) {
+	template, err := l.laundryRepository.GetTemplate(ctx, templateId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	title := template.Name
+	if request.Title != nil {
+		title = *request.Title
+	}
+
+	return l.addCopiedLaundry(ctx, userId, model.AddLaundryRequest{
+		Title:       title,
+		LaundryDate: request.LaundryDate,
+		Items:       template.Items,
+	})
+}
+
+func (l *LaundryServiceImpl) CloneLaundry(ctx context.Context, userId, id string, request model.CopyLaundryRequest) (*model.LaundryDetailResponse, error) {
+	source, err := l.laundryRepository.GetLaundryDetail(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	title := source.Title
+	if request.Title != nil {
+		title = *request.Title
+	}
+
+	items := []model.LaundryItemsRequest{}
+	for _, item := range source.Items {
+		items = append(items, model.LaundryItemsRequest{CategoryId: item.CategoryId, Amount: item.Amount, Notes: item.Notes})
+	}
+
+	return l.addCopiedLaundry(ctx, userId, model.AddLaundryRequest{
+		Title:       title,
+		LaundryDate: request.LaundryDate,
+		Items:       items,
+	})
 }
 
-func (l *LaundryServiceImpl) AddLaundry(ctx context.Context, userId string, request model.AddLaundryRequest) error {
-	log := logging.WithContext(ctx)
+// addCopiedLaundry adds a routine built from a template or another routine, it has to pass the same checks
+// as a routine sent by the user (e.g. the title may have been cleared)
+func (l *LaundryServiceImpl) addCopiedLaundry(ctx context.Context, userId string, request model.AddLaundryRequest) (*model.LaundryDetailResponse, error) {
+	if err := errorutils.ValidateStruct(&request); err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
 
+	return l.AddLaundry(ctx, userId, request)
+}
+
+func (l *LaundryServiceImpl) ValidateItems(ctx context.Context, userId string, items []model.LaundryItemsRequest) error {
 	// validate category id(s)
 	var categoryIds []string
-	for _, item := range request.Items {
+	for _, item := range items {
 		categoryIds = append(categoryIds, item.CategoryId)
 	}
 
 	categoriesData, err := l.laundryRepository.GetCategoryById(ctx, userId, categoryIds...)
-	if err != nil || len(categoriesData) != len(request.Items) {
-		log.Error("invalid categories detected")
+	if err != nil || len(categoriesData) != len(items) {
+		logging.WithContext(ctx).Error("invalid categories detected")
 		return errorutils.ErrorBadRequest.CustomMessage("invalid category id")
 	}
 
-	return l.laundryRepository.AddLaundryData(ctx, userId, request)
+	return nil
+}
+
+func (l *LaundryServiceImpl) GetLaundryDetail(ctx context.Context, id, userId string) (*model.LaundryDetailResponse, error) {
+	result, err := l.laundryRepository.GetLaundryDetail(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result.FillDisplayFields(i18n.LocaleFromContext(ctx))
+
+	return result, nil
+}
+
+func (l *LaundryServiceImpl) UpdateLaundryStatus(ctx context.Context, id, userId string, status constants.LaundryStatus) (*model.LaundryDetailResponse, error) {
+	laundryData, err := l.laundryRepository.GetLaundryDetail(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !laundryData.CanEdit {
+		return nil, errorutils.ErrorForbidden.CustomMessage("your household role can't change this routine")
+	}
+
+	if !constants.LaundryStatus(laundryData.Status).CanMoveTo(status) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid status transition")
+	}
+
+	if err := l.laundryRepository.UpdateLaundryStatus(ctx, id, userId, status); err != nil {
+		return nil, err
+	}
+
+	return l.GetLaundryDetail(ctx, id, userId)
+}
+
+func (l *LaundryServiceImpl) GetTemplateList(ctx context.Context, userId string) ([]model.LaundryTemplateResponse, error) {
+	return l.laundryRepository.GetTemplateList(ctx, userId)
+}
+
+func (l *LaundryServiceImpl) GetTemplate(ctx context.Context, id, userId string) (*model.LaundryTemplateResponse, error) {
+	return l.laundryRepository.GetTemplate(ctx, id, userId)
+}
+
+func (l *LaundryServiceImpl) AddTemplate(ctx context.Context, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error) {
+	if err := l.ValidateItems(ctx, userId, request.Items); err != nil {
+		return nil, err
+	}
+
+	return l.laundryRepository.AddTemplate(ctx, userId, request)
+}
+
+func (l *LaundryServiceImpl) UpdateTemplate(ctx context.Context, id, userId string, request model.LaundryTemplateRequest) (*model.LaundryTemplateResponse, error) {
+	if err := l.ValidateItems(ctx, userId, request.Items); err != nil {
+		return nil, err
+	}
+
+	return l.laundryRepository.UpdateTemplate(ctx, id, userId, request)
+}
+
+func (l *LaundryServiceImpl) DeleteTemplate(ctx context.Context, id, userId string) error {
+	return l.laundryRepository.DeleteTemplate(ctx, id, userId)
 }
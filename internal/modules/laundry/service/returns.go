@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"strings"
+)
+
+func (l *LaundryServiceImpl) RecordReturns(ctx context.Context, id, userId string, request model.LaundryReturnsRequest) (*model.LaundryDetailResponse, error) {
+	laundryData, err := l.laundryRepository.GetLaundryDetail(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !laundryData.CanEdit {
+		return nil, errorutils.ErrorForbidden.CustomMessage("your household role can't change this routine")
+	}
+
+	if constants.LaundryStatus(laundryData.Status) == constants.LAUNDRY_STATUS_CANCELLED {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the returns of a cancelled routine can't be recorded")
+	}
+
+	itemIds := make(map[string]bool, len(laundryData.Items))
+	for _, item := range laundryData.Items {
+		itemIds[item.Id] = true
+	}
+
+	returns := make(map[string]int, len(request.Items))
+	for _, item := range request.Items {
+		if !itemIds[item.ItemId] {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("invalid item id")
+		}
+		if _, ok := returns[item.ItemId]; ok {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("an item can only be returned once")
+		}
+		returns[item.ItemId] = *item.ReturnedAmount
+	}
+
+	// the discrepancy is only known once every item is counted
+	if len(returns) != len(itemIds) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the returned amount of every item is required")
+	}
+
+	if err := l.laundryRepository.RecordReturns(ctx, id, userId, returns); err != nil {
+		return nil, err
+	}
+
+	return l.GetLaundryDetail(ctx, id, userId)
+}
+
+func (l *LaundryServiceImpl) ResolveDiscrepancy(ctx context.Context, id, userId string, request model.LaundryResolutionRequest) (*model.LaundryDetailResponse, error) {
+	note := strings.TrimSpace(request.Note)
+	if note == "" {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("note is required")
+	}
+
+	laundryData, err := l.laundryRepository.GetLaundryDetail(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !laundryData.CanEdit {
+		return nil, errorutils.ErrorForbidden.CustomMessage("your household role can't change this routine")
+	}
+
+	if !laundryData.HasDiscrepancy {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the routine has no discrepancy")
+	}
+
+	if err := l.laundryRepository.ResolveDiscrepancy(ctx, id, userId, note); err != nil {
+		return nil, err
+	}
+
+	return l.GetLaundryDetail(ctx, id, userId)
+}
+
+func (l *LaundryServiceImpl) GetMissingItems(ctx context.Context, userId string, includeResolved bool) (*model.MissingItemsReport, error) {
+	items, err := l.laundryRepository.GetMissingItems(ctx, userId, includeResolved)
+	if err != nil {
+		return nil, err
+	}
+
+	result := model.MissingItemsReport{
+		Items:      items,
+		Categories: []model.LaundryCategoryAmount{},
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+	categoryIndex := map[string]int{}
+	for i := range result.Items {
+		item := &result.Items[i]
+		item.LaundryDateString = i18n.FormatDate(locale, item.LaundryDate)
+
+		index, ok := categoryIndex[item.CategoryId]
+		if !ok {
+			index = len(result.Categories)
+			categoryIndex[item.CategoryId] = index
+			result.Categories = append(result.Categories, model.LaundryCategoryAmount{CategoryName: item.CategoryName})
+		}
+		result.Categories[index].Amount += item.Missing
+		result.TotalMissing += item.Missing
+	}
+
+	return &result, nil
+}
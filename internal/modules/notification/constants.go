@@ -0,0 +1,15 @@
+package notification
+
+type Type string
+
+const (
+	TYPE_REMINDER Type = "reminder"
+	// TYPE_ASSIGNMENT tells a household member it's their turn to do a routine
+	TYPE_ASSIGNMENT Type = "assignment"
+	// TYPE_SWAP is a request to swap a turn, or the answer to one
+	TYPE_SWAP Type = "swap"
+)
+
+const (
+	NOTIFICATION_LIST_LIMIT = 20
+)
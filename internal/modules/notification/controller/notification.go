@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+type (
+	NotificationController interface {
+		GetNotificationList(ctx *gin.Context)
+		GetUnreadCount(ctx *gin.Context)
+		MarkAsRead(ctx *gin.Context)
+		MarkAllAsRead(ctx *gin.Context)
+	}
+
+	NotificationControllerImpl struct {
+		notificationService service.NotificationService
+	}
+)
+
+func NewNotificationController(notificationService service.NotificationService) NotificationController {
+	return &NotificationControllerImpl{
+		notificationService: notificationService,
+	}
+}
+
+func (n *NotificationControllerImpl) GetNotificationList(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+	page := utils.ConvertStrToInt(strings.TrimSpace(ctx.Query("page")), 1)
+	unreadOnly := strings.TrimSpace(ctx.Query("unread")) == "true"
+
+	result, total, err := n.notificationService.GetNotificationList(ctx, userData.UserId, unreadOnly, page)
+	if err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	meta := httputils.SetBaseMeta(page, notification.NOTIFICATION_LIST_LIMIT, total)
+	httputils.SetHttpResponse(ctx, result, nil, &meta)
+}
+
+func (n *NotificationControllerImpl) GetUnreadCount(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := n.notificationService.GetUnreadCount(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (n *NotificationControllerImpl) MarkAsRead(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := n.notificationService.MarkAsRead(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+func (n *NotificationControllerImpl) MarkAllAsRead(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := n.notificationService.MarkAllAsRead(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+type (
+	NotificationRepository interface {
+		// AddNotification creates the notification on the given transaction, so it's only shown when the
+		// event it belongs to is committed. A source key that already exists is a no-op.
+		AddNotification(ctx context.Context, tx sqlx.ExtContext, n model.Notification) error
+		GetNotificationList(ctx context.Context, userId string, unreadOnly bool, page int) ([]model.NotificationResponse, int, error)
+		CountUnread(ctx context.Context, userId string) (int, error)
+		MarkAsRead(ctx context.Context, id, userId string) error
+		MarkAllAsRead(ctx context.Context, userId string) error
+	}
+
+	NotificationRepositoryImpl struct {
+		db database.DBCollection
+	}
+)
+
+func NewNotificationRepository(db database.DBCollection) NotificationRepository {
+	return &NotificationRepositoryImpl{
+		db: db,
+	}
+}
+
+func (n *NotificationRepositoryImpl) AddNotification(ctx context.Context, tx sqlx.ExtContext, data model.Notification) error {
+	query, args := squirrel.Insert("notifications").
+		Columns("id", "user_id", "type", "title", "body", "laundry_id", "source_key", "created_at").
+		Values(utils.GenerateCleanUUID(), data.UserId, data.Type, data.Title, data.Body, data.LaundryId, data.SourceKey, utils.TimeNow()).
+		Suffix("ON CONFLICT (source_key) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when adding notification:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (n *NotificationRepositoryImpl) GetNotificationList(ctx context.Context, userId string, unreadOnly bool, page int) ([]model.NotificationResponse, int, error) {
+	result := []model.NotificationResponse{}
+	log := logging.WithContext(ctx)
+
+	limit := notification.NOTIFICATION_LIST_LIMIT
+	offset := 0
+	if page > 1 {
+		offset = (page - 1) * limit
+	}
+
+	filter := squirrel.And{squirrel.Eq{"user_id": userId}}
+	if unreadOnly {
+		filter = append(filter, squirrel.Eq{"read_at": nil})
+	}
+
+	query, args := squirrel.Select("id, type, title, body, laundry_id, read_at, created_at").
+		From("notifications").
+		Where(filter).
+		OrderBy("created_at DESC", "id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := n.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		log.Error("error when getting notification list:", err)
+		return result, 0, errorutils.DefineSQLError(err)
+	}
+
+	queryCount, args := squirrel.Select("COUNT(*)").
+		From("notifications").
+		Where(filter).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var total int
+	if err := n.db.PostgresDBSqlx.GetContext(ctx, &total, queryCount, args...); err != nil {
+		log.Error("error when counting notifications:", err)
+		return result, 0, errorutils.DefineSQLError(err)
+	}
+
+	return result, total, nil
+}
+
+func (n *NotificationRepositoryImpl) CountUnread(ctx context.Context, userId string) (int, error) {
+	query, args := squirrel.Select("COUNT(*)").
+		From("notifications").
+		Where(squirrel.Eq{"user_id": userId, "read_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result int
+	if err := n.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when counting unread notifications:", err)
+		return 0, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (n *NotificationRepositoryImpl) MarkAsRead(ctx context.Context, id, userId string) error {
+	query, args := squirrel.Update("notifications").
+		Set("read_at", squirrel.Expr("COALESCE(read_at, ?)", utils.TimeNow())).
+		Where(squirrel.Eq{"id": id, "user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := n.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when marking notification as read:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (n *NotificationRepositoryImpl) MarkAllAsRead(ctx context.Context, userId string) error {
+	query, args := squirrel.Update("notifications").
+		Set("read_at", utils.TimeNow()).
+		Where(squirrel.Eq{"user_id": userId, "read_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := n.db.PostgresDBSqlx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when marking notifications as read:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
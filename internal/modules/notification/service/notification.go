@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/repository"
+)
+
+type (
+	NotificationService interface {
+		GetNotificationList(ctx context.Context, userId string, unreadOnly bool, page int) ([]model.NotificationResponse, int, error)
+		GetUnreadCount(ctx context.Context, userId string) (*model.UnreadNotificationsResponse, error)
+		MarkAsRead(ctx context.Context, id, userId string) error
+		MarkAllAsRead(ctx context.Context, userId string) error
+	}
+
+	NotificationServiceImpl struct {
+		notificationRepository repository.NotificationRepository
+	}
+)
+
+func NewNotificationService(n repository.NotificationRepository) NotificationService {
+	return &NotificationServiceImpl{
+		notificationRepository: n,
+	}
+}
+
+func (n *NotificationServiceImpl) GetNotificationList(ctx context.Context, userId string, unreadOnly bool, page int) ([]model.NotificationResponse, int, error) {
+	return n.notificationRepository.GetNotificationList(ctx, userId, unreadOnly, page)
+}
+
+func (n *NotificationServiceImpl) GetUnreadCount(ctx context.Context, userId string) (*model.UnreadNotificationsResponse, error) {
+	unread, err := n.notificationRepository.CountUnread(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UnreadNotificationsResponse{Unread: unread}, nil
+}
+
+func (n *NotificationServiceImpl) MarkAsRead(ctx context.Context, id, userId string) error {
+	return n.notificationRepository.MarkAsRead(ctx, id, userId)
+}
+
+func (n *NotificationServiceImpl) MarkAllAsRead(ctx context.Context, userId string) error {
+	return n.notificationRepository.MarkAllAsRead(ctx, userId)
+}
@@ -0,0 +1,23 @@
+package outbox
+
+type (
+	Status string
+)
+
+const (
+	STATUS_PENDING Status = "pending"
+	STATUS_SENDING Status = "sending"
+	STATUS_SENT    Status = "sent"
+	// STATUS_DEAD is the dead-letter state, the email ran out of attempts and won't be retried
+	STATUS_DEAD Status = "dead"
+)
+
+const (
+	DEFAULT_POLL_INTERVAL_SECONDS = 10
+	DEFAULT_BATCH_SIZE            = 20
+	DEFAULT_MAX_ATTEMPTS          = 8
+
+	// LOCK_DURATION_MINUTES is how long a claimed email is reserved for the worker that claimed it,
+	// after that it's considered abandoned (e.g. the process crashed) and can be claimed again
+	LOCK_DURATION_MINUTES = 5
+)
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type (
+	OutboxRepository interface {
+		// Enqueue stores the email to be delivered by the worker. It runs on the given transaction so the
+		// email is only sent when the data change it belongs to is committed. Enqueueing an idempotency
+		// key that already exists is a no-op.
+		Enqueue(ctx context.Context, tx sqlx.ExtContext, idempotencyKey string, message mail.Message) error
+		// Claim reserves a batch of due emails for the calling worker, skipping the ones claimed by other workers
+		Claim(ctx context.Context, limit int) ([]model.OutboxEmail, error)
+		// MarkSent records the delivery and redacts the content of the email (see redactedPayload)
+		MarkSent(ctx context.Context, id int64) error
+		// MarkFailed records a failed attempt, scheduling the next one or moving the email to the dead-letter state.
+		// The content of a dead-lettered email is redacted, it won't be sent anymore.
+		MarkFailed(ctx context.Context, id int64, errMessage string, nextAttemptAt *time.Time) error
+	}
+
+	OutboxRepositoryImpl struct {
+		db          database.DBCollection
+		maxAttempts int
+	}
+)
+
+// redactedPayload drops the content of an email that won't be sent anymore, it may carry secrets (e.g. the OTP
+// or an invitation token). The type, recipients and headers are kept for troubleshooting.
+var redactedPayload = squirrel.Expr("payload - 'data' - 'subject' - 'html_body' - 'text_body' - 'attachments'")
+
+func NewOutboxRepository(db database.DBCollection, maxAttempts int) OutboxRepository {
+	if maxAttempts <= 0 {
+		maxAttempts = outbox.DEFAULT_MAX_ATTEMPTS
+	}
+
+	return &OutboxRepositoryImpl{
+		db:          db,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (o *OutboxRepositoryImpl) Enqueue(ctx context.Context, tx sqlx.ExtContext, idempotencyKey string, message mail.Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when encoding outbox email:", err)
+		return errorutils.ErrorInternalServer.CustomMessage(err.Error())
+	}
+
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Insert("email_outbox").
+		Columns("idempotency_key", "email_type", "payload", "status", "max_attempts", "next_attempt_at", "created_at", "updated_at").
+		Values(idempotencyKey, message.Type, payload, outbox.STATUS_PENDING, o.maxAttempts, currentTime, currentTime, currentTime).
+		Suffix("ON CONFLICT (idempotency_key) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when enqueueing email:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (o *OutboxRepositoryImpl) Claim(ctx context.Context, limit int) ([]model.OutboxEmail, error) {
+	log := logging.WithContext(ctx)
+	result := []model.OutboxEmail{}
+
+	currentTime := utils.TimeNow()
+
+	err := database.WithTransaction(ctx, o.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Select("id, idempotency_key, email_type, payload, status, attempts, max_attempts, next_attempt_at, last_error, created_at, sent_at").
+			From("email_outbox").
+			Where(squirrel.Or{
+				squirrel.And{
+					squirrel.Eq{"status": outbox.STATUS_PENDING},
+					squirrel.LtOrEq{"next_attempt_at": currentTime},
+				},
+				squirrel.And{
+					squirrel.Eq{"status": outbox.STATUS_SENDING},
+					squirrel.Lt{"locked_until": currentTime},
+				},
+			}).
+			OrderBy("next_attempt_at", "id").
+			Limit(uint64(limit)).
+			Suffix("FOR UPDATE SKIP LOCKED").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if err := tx.SelectContext(ctx, &result, query, args...); err != nil {
+			return err
+		}
+
+		if len(result) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(result))
+		for _, email := range result {
+			ids = append(ids, email.Id)
+		}
+
+		queryUpdate, args := squirrel.Update("email_outbox").
+			Set("status", outbox.STATUS_SENDING).
+			Set("attempts", squirrel.Expr("attempts + 1")).
+			Set("locked_until", currentTime.Add(outbox.LOCK_DURATION_MINUTES*time.Minute)).
+			Set("updated_at", currentTime).
+			Where(squirrel.Eq{"id": ids}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		_, err := tx.ExecContext(ctx, queryUpdate, args...)
+		return err
+	})
+	if err != nil {
+		log.Error("error when claiming outbox emails:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	for i := range result {
+		result[i].Attempts++
+	}
+
+	return result, nil
+}
+
+func (o *OutboxRepositoryImpl) MarkSent(ctx context.Context, id int64) error {
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Update("email_outbox").
+		Set("status", outbox.STATUS_SENT).
+		Set("payload", redactedPayload).
+		Set("sent_at", currentTime).
+		Set("locked_until", nil).
+		Set("last_error", nil).
+		Set("updated_at", currentTime).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := o.db.PostgresDBSqlx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when marking email as sent:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (o *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id int64, errMessage string, nextAttemptAt *time.Time) error {
+	currentTime := utils.TimeNow()
+
+	query := squirrel.Update("email_outbox").
+		Set("last_error", errMessage).
+		Set("locked_until", nil).
+		Set("updated_at", currentTime).
+		Where(squirrel.Eq{"id": id})
+
+	if nextAttemptAt != nil {
+		query = query.Set("status", outbox.STATUS_PENDING).Set("next_attempt_at", *nextAttemptAt)
+	} else {
+		query = query.Set("status", outbox.STATUS_DEAD).Set("payload", redactedPayload)
+	}
+
+	sql, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := o.db.PostgresDBSqlx.ExecContext(ctx, sql, args...); err != nil {
+		logging.WithContext(ctx).Error("error when marking email as failed:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
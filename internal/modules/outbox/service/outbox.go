@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"math/rand"
+	"time"
+)
+
+type (
+	OutboxService interface {
+		// DeliverPending sends the emails that are due, meant to be run periodically by the worker
+		DeliverPending(ctx context.Context) error
+	}
+
+	OutboxServiceImpl struct {
+		smtpClient       tools.SMTPClient
+		outboxRepository repository.OutboxRepository
+		batchSize        int
+	}
+)
+
+const (
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 6 * time.Hour
+
+	// markSentAttempts bounds the retries of recording a delivery, an email that can't be marked as sent
+	// is delivered again once its lock expires
+	markSentAttempts = 3
+)
+
+// markSentDelay is the wait before the first retry of MarkSent, it grows with every attempt
+var markSentDelay = time.Second
+
+func NewOutboxService(smtp tools.SMTPClient, o repository.OutboxRepository, batchSize int) OutboxService {
+	if batchSize <= 0 {
+		batchSize = outbox.DEFAULT_BATCH_SIZE
+	}
+
+	return &OutboxServiceImpl{
+		smtpClient:       smtp,
+		outboxRepository: o,
+		batchSize:        batchSize,
+	}
+}
+
+func (o *OutboxServiceImpl) DeliverPending(ctx context.Context) error {
+	for {
+		emails, err := o.outboxRepository.Claim(ctx, o.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, email := range emails {
+			if ctx.Err() != nil {
+				// the claimed emails are picked up again once their lock expires
+				return nil
+			}
+			if err := o.deliver(ctx, email); err != nil {
+				// the outbox can't be updated, the rest of the batch is picked up again once its lock expires
+				return err
+			}
+		}
+
+		if len(emails) < o.batchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends the email and records the outcome, the error is about recording it
+func (o *OutboxServiceImpl) deliver(ctx context.Context, email model.OutboxEmail) error {
+	log := logging.WithContext(ctx).WithField("outbox_id", email.Id)
+
+	// the outcome is recorded even when the worker is stopping, the email may already be sent
+	recordCtx := context.WithoutCancel(ctx)
+
+	var message mail.Message
+	if err := json.Unmarshal(email.Payload, &message); err != nil {
+		log.Error("invalid outbox payload, moving to dead-letter:", err)
+		if err := o.outboxRepository.MarkFailed(recordCtx, email.Id, err.Error(), nil); err != nil {
+			log.Error("error when moving email to dead-letter:", err)
+			return err
+		}
+		return nil
+	}
+
+	if err := o.smtpClient.SendEmail(ctx, message); err != nil {
+		var nextAttemptAt *time.Time
+		if email.Attempts < email.MaxAttempts {
+			next := utils.TimeNow().Add(retryDelay(email.Attempts))
+			nextAttemptAt = &next
+		}
+
+		log.Errorf("error when delivering email (attempt %d/%d): %s", email.Attempts, email.MaxAttempts, err)
+		if err := o.outboxRepository.MarkFailed(recordCtx, email.Id, err.Error(), nextAttemptAt); err != nil {
+			// the email stays claimed, it's attempted again once its lock expires
+			log.Error("error when recording failed delivery:", err)
+			return err
+		}
+		return nil
+	}
+
+	return o.markSent(recordCtx, email)
+}
+
+// markSent records the delivery. It's retried before giving up, the email would otherwise be sent twice.
+func (o *OutboxServiceImpl) markSent(ctx context.Context, email model.OutboxEmail) error {
+	log := logging.WithContext(ctx).WithField("outbox_id", email.Id)
+
+	var err error
+	for attempt := 1; attempt <= markSentAttempts; attempt++ {
+		if err = o.outboxRepository.MarkSent(ctx, email.Id); err == nil {
+			return nil
+		}
+
+		log.Errorf("error when marking delivered email as sent (attempt %d/%d): %s", attempt, markSentAttempts, err)
+		if attempt < markSentAttempts {
+			time.Sleep(time.Duration(attempt) * markSentDelay)
+		}
+	}
+
+	log.Error("delivered email couldn't be marked as sent, it will be delivered again once its lock expires")
+	return err
+}
+
+// retryDelay grows exponentially with the number of attempts, with up to 20% jitter so retries don't bunch up
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeOutboxRepository hands out one batch and records the outcomes, the first markSentFailures MarkSent calls fail
+type fakeOutboxRepository struct {
+	repository.OutboxRepository
+	batch            []model.OutboxEmail
+	markSentFailures int
+	markFailedErr    error
+
+	sent   []int64
+	failed map[int64]*time.Time
+}
+
+func (f *fakeOutboxRepository) Claim(ctx context.Context, limit int) ([]model.OutboxEmail, error) {
+	batch := f.batch
+	f.batch = nil
+	return batch, nil
+}
+
+func (f *fakeOutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	if f.markSentFailures > 0 {
+		f.markSentFailures--
+		return errors.New("connection reset")
+	}
+	f.sent = append(f.sent, id)
+	return nil
+}
+
+func (f *fakeOutboxRepository) MarkFailed(ctx context.Context, id int64, errMessage string, nextAttemptAt *time.Time) error {
+	if f.failed == nil {
+		f.failed = map[int64]*time.Time{}
+	}
+	f.failed[id] = nextAttemptAt
+	return f.markFailedErr
+}
+
+type fakeSMTPClient struct {
+	err       error
+	delivered []string
+}
+
+func (f *fakeSMTPClient) SendEmail(ctx context.Context, message mail.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.delivered = append(f.delivered, message.To...)
+	return nil
+}
+
+func outboxEmail(t *testing.T, id int64, to string) model.OutboxEmail {
+	t.Helper()
+
+	payload, err := json.Marshal(mail.Message{Type: mail.EMAIL_TYPE_OTP_SIGNUP, To: []string{to}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return model.OutboxEmail{Id: id, Payload: payload, Attempts: 1, MaxAttempts: 3}
+}
+
+func TestDeliverPending(t *testing.T) {
+	markSentDelay = time.Millisecond
+
+	tests := []struct {
+		name          string
+		batch         []model.OutboxEmail
+		repository    *fakeOutboxRepository
+		smtpErr       error
+		wantErr       bool
+		wantDelivered int
+		wantSent      []int64
+		wantRetry     bool
+		wantDead      bool
+	}{
+		{
+			name:          "delivered",
+			batch:         []model.OutboxEmail{outboxEmail(t, 1, "a@example.com"), outboxEmail(t, 2, "b@example.com")},
+			repository:    &fakeOutboxRepository{},
+			wantDelivered: 2,
+			wantSent:      []int64{1, 2},
+		},
+		{
+			name:          "marked as sent on a retry",
+			batch:         []model.OutboxEmail{outboxEmail(t, 1, "a@example.com")},
+			repository:    &fakeOutboxRepository{markSentFailures: markSentAttempts - 1},
+			wantDelivered: 1,
+			wantSent:      []int64{1},
+		},
+		{
+			name:          "can't be marked as sent",
+			batch:         []model.OutboxEmail{outboxEmail(t, 1, "a@example.com"), outboxEmail(t, 2, "b@example.com")},
+			repository:    &fakeOutboxRepository{markSentFailures: markSentAttempts},
+			wantErr:       true,
+			wantDelivered: 1,
+		},
+		{
+			name:       "delivery failed",
+			batch:      []model.OutboxEmail{outboxEmail(t, 1, "a@example.com")},
+			repository: &fakeOutboxRepository{},
+			smtpErr:    errors.New("mailbox unavailable"),
+			wantRetry:  true,
+		},
+		{
+			name:       "failed delivery can't be recorded",
+			batch:      []model.OutboxEmail{outboxEmail(t, 1, "a@example.com"), outboxEmail(t, 2, "b@example.com")},
+			repository: &fakeOutboxRepository{markFailedErr: errors.New("connection reset")},
+			smtpErr:    errors.New("mailbox unavailable"),
+			wantErr:    true,
+			wantRetry:  true,
+		},
+		{
+			name:       "invalid payload",
+			batch:      []model.OutboxEmail{{Id: 1, Payload: []byte("{"), Attempts: 1, MaxAttempts: 3}},
+			repository: &fakeOutboxRepository{},
+			wantDead:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.repository.batch = tt.batch
+			smtpClient := &fakeSMTPClient{err: tt.smtpErr}
+
+			err := NewOutboxService(smtpClient, tt.repository, len(tt.batch)+1).DeliverPending(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if len(smtpClient.delivered) != tt.wantDelivered {
+				t.Errorf("delivered %v, want %d email(s)", smtpClient.delivered, tt.wantDelivered)
+			}
+			if !reflect.DeepEqual(tt.repository.sent, tt.wantSent) {
+				t.Errorf("marked as sent %v, want %v", tt.repository.sent, tt.wantSent)
+			}
+
+			nextAttemptAt, failed := tt.repository.failed[1]
+			if failed != (tt.wantRetry || tt.wantDead) || (nextAttemptAt != nil) != tt.wantRetry {
+				t.Errorf("failed = %t with next attempt %v, want retry %t, dead %t", failed, nextAttemptAt, tt.wantRetry, tt.wantDead)
+			}
+			if _, ok := tt.repository.failed[2]; ok && tt.wantErr {
+				t.Error("the batch went on after the outbox couldn't be updated")
+			}
+		})
+	}
+}
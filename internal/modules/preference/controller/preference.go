@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/preference/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	unsubscribeStateConfirm = "confirm"
+	unsubscribeStateDone    = "done"
+	unsubscribeStateInvalid = "invalid"
+)
+
+type (
+	PreferenceController interface {
+		GetEmailPreferences(ctx *gin.Context)
+		UpdateEmailPreferences(ctx *gin.Context)
+		UpdateTimezone(ctx *gin.Context)
+		GetUnsubscribePage(ctx *gin.Context)
+		Unsubscribe(ctx *gin.Context)
+	}
+
+	PreferenceControllerImpl struct {
+		preferenceService service.PreferenceService
+	}
+)
+
+func NewPreferenceController(preferenceService service.PreferenceService) PreferenceController {
+	return &PreferenceControllerImpl{
+		preferenceService: preferenceService,
+	}
+}
+
+func (p *PreferenceControllerImpl) GetEmailPreferences(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := p.preferenceService.GetEmailPreferences(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (p *PreferenceControllerImpl) UpdateEmailPreferences(ctx *gin.Context) {
+	var request model.UpdateEmailPreferencesRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := p.preferenceService.UpdateEmailPreferences(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (p *PreferenceControllerImpl) UpdateTimezone(ctx *gin.Context) {
+	var request model.UpdateTimezoneRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := p.preferenceService.UpdateTimezone(ctx, userData.UserId, request.Timezone)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// GetUnsubscribePage asks to confirm the unsubscribe, link scanners of mail providers open links on their own
+func (p *PreferenceControllerImpl) GetUnsubscribePage(ctx *gin.Context) {
+	token := ctx.Query("token")
+
+	category, err := p.preferenceService.VerifyUnsubscribeToken(ctx, token)
+	if err != nil {
+		httputils.SetHtmlResponse(ctx, http.StatusBadRequest, "unsubscribe.html", gin.H{"state": unsubscribeStateInvalid})
+		return
+	}
+
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "unsubscribe.html", gin.H{
+		"state":    unsubscribeStateConfirm,
+		"token":    token,
+		"category": i18n.T(i18n.LocaleFromContext(ctx), "email_category."+string(category)),
+	})
+}
+
+// Unsubscribe handles both the confirmation form and the one-click POST of mail clients (RFC 8058).
+// The signed token is what authorizes the request, there's no session or CSRF token involved.
+func (p *PreferenceControllerImpl) Unsubscribe(ctx *gin.Context) {
+	category, err := p.preferenceService.Unsubscribe(ctx, ctx.Query("token"))
+	if err != nil {
+		statusCode, _ := errorutils.GetStatusCode(err)
+		httputils.SetHtmlResponse(ctx, statusCode, "unsubscribe.html", gin.H{"state": unsubscribeStateInvalid})
+		return
+	}
+
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "unsubscribe.html", gin.H{
+		"state":    unsubscribeStateDone,
+		"category": i18n.T(i18n.LocaleFromContext(ctx), "email_category."+string(category)),
+	})
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+)
+
+type (
+	PreferenceRepository interface {
+		// GetEmailPreferences returns the choices the user made, categories without a choice fall back to their default
+		GetEmailPreferences(ctx context.Context, userId string) (map[mail.Category]bool, error)
+		SetEmailPreference(ctx context.Context, userId string, category mail.Category, subscribed bool) error
+		IsSubscribed(ctx context.Context, userId string, category mail.Category) (bool, error)
+		GetTimezone(ctx context.Context, userId string) (string, error)
+		UpdateTimezone(ctx context.Context, userId, timezone string) error
+	}
+
+	PreferenceRepositoryImpl struct {
+		db database.DBCollection
+	}
+
+	emailPreferenceRow struct {
+		Category   string `db:"category"`
+		Subscribed bool   `db:"subscribed"`
+	}
+)
+
+func NewPreferenceRepository(db database.DBCollection) PreferenceRepository {
+	return &PreferenceRepositoryImpl{
+		db: db,
+	}
+}
+
+func (p *PreferenceRepositoryImpl) GetEmailPreferences(ctx context.Context, userId string) (map[mail.Category]bool, error) {
+	query, args := squirrel.Select("category, subscribed").
+		From("email_preferences").
+		Where(squirrel.Eq{"user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var rows []emailPreferenceRow
+	if err := p.db.PostgresDBSqlx.SelectContext(ctx, &rows, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting email preferences:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	result := map[mail.Category]bool{}
+	for _, row := range rows {
+		result[mail.Category(row.Category)] = row.Subscribed
+	}
+
+	return result, nil
+}
+
+func (p *PreferenceRepositoryImpl) SetEmailPreference(ctx context.Context, userId string, category mail.Category, subscribed bool) error {
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Insert("email_preferences").
+		Columns("user_id", "category", "subscribed", "updated_at").
+		Values(userId, category, subscribed, currentTime).
+		Suffix("ON CONFLICT (user_id, category) DO UPDATE SET subscribed = EXCLUDED.subscribed, updated_at = EXCLUDED.updated_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := p.db.PostgresDBSqlx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when setting email preference:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (p *PreferenceRepositoryImpl) IsSubscribed(ctx context.Context, userId string, category mail.Category) (bool, error) {
+	if !category.IsOptional() {
+		return true, nil
+	}
+
+	preferences, err := p.GetEmailPreferences(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	subscribed, ok := preferences[category]
+	if !ok {
+		return category.DefaultSubscribed(), nil
+	}
+
+	return subscribed, nil
+}
+
+func (p *PreferenceRepositoryImpl) GetTimezone(ctx context.Context, userId string) (string, error) {
+	query, args := squirrel.Select("timezone").
+		From("users").
+		Where(squirrel.Eq{"id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var timezone string
+	if err := p.db.PostgresDBSqlx.GetContext(ctx, &timezone, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting timezone:", err)
+		return "", errorutils.DefineSQLError(err)
+	}
+
+	return timezone, nil
+}
+
+func (p *PreferenceRepositoryImpl) UpdateTimezone(ctx context.Context, userId, timezone string) error {
+	query, args := squirrel.Update("users").
+		Set("timezone", timezone).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := p.db.PostgresDBSqlx.ExecContext(ctx, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when updating timezone:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/preference/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+)
+
+type (
+	PreferenceService interface {
+		GetEmailPreferences(ctx context.Context, userId string) ([]model.EmailPreferenceResponse, error)
+		UpdateEmailPreferences(ctx context.Context, userId string, request model.UpdateEmailPreferencesRequest) ([]model.EmailPreferenceResponse, error)
+		// VerifyUnsubscribeToken returns the category of a valid unsubscribe link, without unsubscribing yet
+		VerifyUnsubscribeToken(ctx context.Context, token string) (mail.Category, error)
+		Unsubscribe(ctx context.Context, token string) (mail.Category, error)
+		UpdateTimezone(ctx context.Context, userId, timezone string) (*model.TimezoneResponse, error)
+	}
+
+	PreferenceServiceImpl struct {
+		preferenceRepository repository.PreferenceRepository
+		unsubscribeTokens    tools.UnsubscribeTokens
+	}
+)
+
+func NewPreferenceService(p repository.PreferenceRepository, u tools.UnsubscribeTokens) PreferenceService {
+	return &PreferenceServiceImpl{
+		preferenceRepository: p,
+		unsubscribeTokens:    u,
+	}
+}
+
+func (p *PreferenceServiceImpl) GetEmailPreferences(ctx context.Context, userId string) ([]model.EmailPreferenceResponse, error) {
+	preferences, err := p.preferenceRepository.GetEmailPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+
+	result := []model.EmailPreferenceResponse{}
+	for _, category := range mail.OptionalCategories() {
+		subscribed, ok := preferences[category]
+		if !ok {
+			subscribed = category.DefaultSubscribed()
+		}
+
+		result = append(result, model.EmailPreferenceResponse{
+			Category:   string(category),
+			Label:      i18n.T(locale, "email_category."+string(category)),
+			Subscribed: subscribed,
+		})
+	}
+
+	return result, nil
+}
+
+func (p *PreferenceServiceImpl) UpdateEmailPreferences(ctx context.Context, userId string, request model.UpdateEmailPreferencesRequest) ([]model.EmailPreferenceResponse, error) {
+	for category := range request.Preferences {
+		if !mail.Category(category).IsOptional() {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("invalid email category")
+		}
+	}
+
+	for category, subscribed := range request.Preferences {
+		if err := p.preferenceRepository.SetEmailPreference(ctx, userId, mail.Category(category), subscribed); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.GetEmailPreferences(ctx, userId)
+}
+
+func (p *PreferenceServiceImpl) VerifyUnsubscribeToken(ctx context.Context, token string) (mail.Category, error) {
+	_, category, err := p.unsubscribeTokens.Verify(token)
+	if err != nil {
+		logging.WithContext(ctx).Error("invalid unsubscribe token:", err)
+		return "", errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
+
+	return category, nil
+}
+
+func (p *PreferenceServiceImpl) Unsubscribe(ctx context.Context, token string) (mail.Category, error) {
+	userId, category, err := p.unsubscribeTokens.Verify(token)
+	if err != nil {
+		logging.WithContext(ctx).Error("invalid unsubscribe token:", err)
+		return "", errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
+
+	if err := p.preferenceRepository.SetEmailPreference(ctx, userId, category, false); err != nil {
+		return "", err
+	}
+
+	return category, nil
+}
+
+func (p *PreferenceServiceImpl) UpdateTimezone(ctx context.Context, userId, timezone string) (*model.TimezoneResponse, error) {
+	if _, err := utils.LoadTimezone(timezone); err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
+
+	if err := p.preferenceRepository.UpdateTimezone(ctx, userId, timezone); err != nil {
+		return nil, err
+	}
+
+	return &model.TimezoneResponse{Timezone: timezone}, nil
+}
@@ -0,0 +1,23 @@
+package reminder
+
+type Status string
+
+const (
+	STATUS_PENDING Status = "pending"
+	// STATUS_FIRED means the notification was created and the email queued in the outbox
+	STATUS_FIRED     Status = "fired"
+	STATUS_CANCELLED Status = "cancelled"
+)
+
+const (
+	// DISPATCH_INTERVAL_SECONDS is how often the scheduler looks for reminders that are due
+	DISPATCH_INTERVAL_SECONDS = 30
+
+	// BATCH_SIZE is how many due reminders are fired in one transaction
+	BATCH_SIZE = 50
+)
+
+// SourceKey identifies the reminder as the source of its notification and its email in the outbox
+func SourceKey(reminderId string) string {
+	return "reminder:" + reminderId
+}
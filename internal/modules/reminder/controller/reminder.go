@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	ReminderController interface {
+		GetReminderList(ctx *gin.Context)
+		AddReminder(ctx *gin.Context)
+		CancelReminder(ctx *gin.Context)
+	}
+
+	ReminderControllerImpl struct {
+		reminderService service.ReminderService
+	}
+)
+
+func NewReminderController(reminderService service.ReminderService) ReminderController {
+	return &ReminderControllerImpl{
+		reminderService: reminderService,
+	}
+}
+
+func (r *ReminderControllerImpl) GetReminderList(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := r.reminderService.GetReminderList(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (r *ReminderControllerImpl) AddReminder(ctx *gin.Context) {
+	var request model.ReminderRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := r.reminderService.AddReminder(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (r *ReminderControllerImpl) CancelReminder(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := r.reminderService.CancelReminder(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
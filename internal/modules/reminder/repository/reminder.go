@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	notificationRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/repository"
+	outboxRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/outbox/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+const reminderColumns = "id, laundry_id, message, remind_at, status, fired_at, created_at"
+
+type (
+	ReminderRepository interface {
+		// GetReminderList lists the reminders of a routine the user owns or shares with their household
+		GetReminderList(ctx context.Context, laundryId, userId string) ([]model.ReminderResponse, error)
+		// AddReminder attaches a reminder for the user to a routine they can change, ErrorNotFound is returned when
+		// they can't
+		AddReminder(ctx context.Context, laundryId, userId, message string, remindAt time.Time) (*model.ReminderResponse, error)
+		// CancelReminder cancels a reminder that hasn't fired yet of a routine the user can change
+		CancelReminder(ctx context.Context, id, userId string) error
+		// FireDueReminders creates the notification of a batch of due reminders, queues their email and marks them
+		// as fired, all in one transaction. Reminders being fired by another instance are skipped, so each fires once.
+		FireDueReminders(ctx context.Context, limit int, build func(r model.DueReminder) (model.Notification, mail.Message)) (int, error)
+	}
+
+	ReminderRepositoryImpl struct {
+		db                     database.DBCollection
+		outboxRepository       outboxRepository.OutboxRepository
+		notificationRepository notificationRepository.NotificationRepository
+	}
+)
+
+func NewReminderRepository(db database.DBCollection, o outboxRepository.OutboxRepository, n notificationRepository.NotificationRepository) ReminderRepository {
+	return &ReminderRepositoryImpl{
+		db:                     db,
+		outboxRepository:       o,
+		notificationRepository: n,
+	}
+}
+
+func (r *ReminderRepositoryImpl) GetReminderList(ctx context.Context, laundryId, userId string) ([]model.ReminderResponse, error) {
+	result := []model.ReminderResponse{}
+
+	query, args := squirrel.Select(reminderColumns).
+		From("reminders").
+		Where(squirrel.Eq{"laundry_id": laundryId}).
+		Where(squirrel.Expr("laundry_id IN (?)", accessibleLaundries(userId, false))).
+		OrderBy("remind_at", "id").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := r.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting reminder list:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (r *ReminderRepositoryImpl) AddReminder(ctx context.Context, laundryId, userId, message string, remindAt time.Time) (*model.ReminderResponse, error) {
+	currentTime := utils.TimeNow()
+
+	laundry := squirrel.Select("id").
+		Column("?::VARCHAR", userId).
+		Column("?::VARCHAR", utils.GenerateCleanUUID()).
+		Column("?::VARCHAR", message).
+		Column("?::TIMESTAMP", remindAt).
+		Column("?::VARCHAR", reminder.STATUS_PENDING).
+		Column("?::TIMESTAMP", currentTime).
+		Column("?::TIMESTAMP", currentTime).
+		From("laundries").
+		Where(squirrel.Eq{"id": laundryId}).
+		Where(database.AccessibleBy("", userId, true))
+
+	query, args := squirrel.Insert("reminders").
+		Columns("laundry_id", "user_id", "id", "message", "remind_at", "status", "created_at", "updated_at").
+		Select(laundry).
+		Suffix("RETURNING " + reminderColumns).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.ReminderResponse
+	if err := r.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when adding reminder:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (r *ReminderRepositoryImpl) CancelReminder(ctx context.Context, id, userId string) error {
+	query, args := squirrel.Update("reminders").
+		Set("status", reminder.STATUS_CANCELLED).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id, "status": reminder.STATUS_PENDING}).
+		Where(squirrel.Expr("laundry_id IN (?)", accessibleLaundries(userId, true))).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := r.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when cancelling reminder:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (r *ReminderRepositoryImpl) FireDueReminders(ctx context.Context, limit int, build func(r model.DueReminder) (model.Notification, mail.Message)) (int, error) {
+	currentTime := utils.TimeNow()
+	fired := 0
+
+	err := database.WithTransaction(ctx, r.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		// the claimed reminders stay locked until they're marked as fired, other instances skip them
+		query, args := squirrel.Select("r.id, r.laundry_id, l.title AS laundry_title, r.message, r.remind_at").
+			Columns("u.id AS user_id, u.email, u.full_name, u.locale, u.timezone").
+			Column("NOT EXISTS (SELECT 1 FROM email_preferences p WHERE p.user_id = u.id AND p.category = ? AND NOT p.subscribed) AS email_subscribed", mail.CATEGORY_REMINDERS).
+			From("reminders r").
+			Join("laundries l ON l.id = r.laundry_id").
+			Join("users u ON u.id = r.user_id").
+			Where(squirrel.Eq{"r.status": reminder.STATUS_PENDING}).
+			Where(squirrel.LtOrEq{"r.remind_at": currentTime}).
+			OrderBy("r.remind_at", "r.id").
+			Limit(uint64(limit)).
+			Suffix("FOR UPDATE OF r SKIP LOCKED").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		var due []model.DueReminder
+		if err := tx.SelectContext(ctx, &due, query, args...); err != nil {
+			return err
+		}
+
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(due))
+		for _, item := range due {
+			notification, message := build(item)
+
+			if err := r.notificationRepository.AddNotification(ctx, tx, notification); err != nil {
+				return err
+			}
+
+			if item.EmailSubscribed {
+				if err := r.outboxRepository.Enqueue(ctx, tx, reminder.SourceKey(item.Id), message); err != nil {
+					return err
+				}
+			}
+
+			ids = append(ids, item.Id)
+		}
+
+		queryUpdate, args := squirrel.Update("reminders").
+			Set("status", reminder.STATUS_FIRED).
+			Set("fired_at", currentTime).
+			Set("updated_at", currentTime).
+			Where(squirrel.Eq{"id": ids}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, queryUpdate, args...); err != nil {
+			return err
+		}
+
+		fired = len(ids)
+		return nil
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when firing due reminders:", err)
+		return 0, errorutils.DefineSQLError(err)
+	}
+
+	return fired, nil
+}
+
+// accessibleLaundries selects the ids of the routines the user owns or shares with their household
+func accessibleLaundries(userId string, writable bool) squirrel.SelectBuilder {
+	return squirrel.Select("id").
+		From("laundries").
+		Where(database.AccessibleBy("", userId, writable))
+}
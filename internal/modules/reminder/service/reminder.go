@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"time"
+)
+
+type (
+	ReminderService interface {
+		GetReminderList(ctx context.Context, laundryId, userId string) ([]model.ReminderResponse, error)
+		AddReminder(ctx context.Context, laundryId, userId string, request model.ReminderRequest) (*model.ReminderResponse, error)
+		CancelReminder(ctx context.Context, id, userId string) error
+		// FireDueReminders fires every reminder that is due, it's run by the reminder scheduler
+		FireDueReminders(ctx context.Context) error
+	}
+
+	ReminderServiceImpl struct {
+		reminderRepository repository.ReminderRepository
+	}
+)
+
+func NewReminderService(r repository.ReminderRepository) ReminderService {
+	return &ReminderServiceImpl{
+		reminderRepository: r,
+	}
+}
+
+func (r *ReminderServiceImpl) GetReminderList(ctx context.Context, laundryId, userId string) ([]model.ReminderResponse, error) {
+	return r.reminderRepository.GetReminderList(ctx, laundryId, userId)
+}
+
+func (r *ReminderServiceImpl) AddReminder(ctx context.Context, laundryId, userId string, request model.ReminderRequest) (*model.ReminderResponse, error) {
+	if (request.RemindAt == nil) == (request.RemindInMinutes == nil) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("either remind_at or remind_in_minutes is required")
+	}
+
+	currentTime := utils.TimeNow()
+
+	var remindAt time.Time
+	if request.RemindAt != nil {
+		remindAt = request.RemindAt.UTC()
+	} else {
+		remindAt = currentTime.Add(time.Duration(*request.RemindInMinutes) * time.Minute)
+	}
+
+	if !remindAt.After(currentTime) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("reminder time must be in the future")
+	}
+
+	return r.reminderRepository.AddReminder(ctx, laundryId, userId, request.Message, remindAt)
+}
+
+func (r *ReminderServiceImpl) CancelReminder(ctx context.Context, id, userId string) error {
+	return r.reminderRepository.CancelReminder(ctx, id, userId)
+}
+
+func (r *ReminderServiceImpl) FireDueReminders(ctx context.Context) error {
+	log := logging.WithContext(ctx)
+
+	for ctx.Err() == nil {
+		fired, err := r.reminderRepository.FireDueReminders(ctx, reminder.BATCH_SIZE, reminderNotification)
+		if err != nil {
+			return err
+		}
+
+		if fired > 0 {
+			log.Infof("%d reminder(s) fired", fired)
+		}
+
+		if fired < reminder.BATCH_SIZE {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// reminderNotification builds the in-app notification and the email of a due reminder, in the user's locale and timezone
+func reminderNotification(due model.DueReminder) (model.Notification, mail.Message) {
+	locale := i18n.Normalize(due.Locale)
+
+	location, err := utils.LoadTimezone(due.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	laundryId := due.LaundryId
+
+	n := model.Notification{
+		UserId:    due.UserId,
+		Type:      string(notification.TYPE_REMINDER),
+		Title:     i18n.T(locale, "notification.reminder.title", due.LaundryTitle),
+		Body:      due.Message,
+		LaundryId: &laundryId,
+		SourceKey: reminder.SourceKey(due.Id),
+	}
+
+	message := mail.Message{
+		Type:     mail.EMAIL_TYPE_REMINDER,
+		Category: mail.CATEGORY_REMINDERS,
+		UserId:   due.UserId,
+		Locale:   locale,
+		To:       []string{due.Email},
+		Data: mail.Data{
+			"FullName":     due.FullName,
+			"LaundryTitle": due.LaundryTitle,
+			"Message":      due.Message,
+			"RemindAt":     i18n.FormatDateTime(locale, due.RemindAt.In(location)),
+		},
+	}
+
+	return n, message
+}
@@ -0,0 +1,24 @@
+package series
+
+type ExceptionKind string
+
+const (
+	// EXCEPTION_SKIPPED is an occurrence that won't happen, its routine is cancelled or never generated
+	EXCEPTION_SKIPPED ExceptionKind = "skipped"
+	// EXCEPTION_MODIFIED is an occurrence whose routine was edited on its own, updating the series keeps it
+	EXCEPTION_MODIFIED ExceptionKind = "modified"
+)
+
+const (
+	// GENERATION_HORIZON_DAYS is how far ahead the occurrences are materialized as routines
+	GENERATION_HORIZON_DAYS = 14
+
+	// GENERATE_INTERVAL_MINUTES is how often the generator extends the series to the horizon
+	GENERATE_INTERVAL_MINUTES = 60
+
+	// BATCH_SIZE is how many series are generated in one transaction
+	BATCH_SIZE = 20
+
+	// OCCURRENCE_LIST_DAYS is the default range of the occurrence list
+	OCCURRENCE_LIST_DAYS = 30
+)
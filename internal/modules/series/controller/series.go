@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"time"
+)
+
+type (
+	SeriesController interface {
+		GetSeriesList(ctx *gin.Context)
+		GetSeries(ctx *gin.Context)
+		AddSeries(ctx *gin.Context)
+		UpdateSeries(ctx *gin.Context)
+		EndSeries(ctx *gin.Context)
+		GetOccurrenceList(ctx *gin.Context)
+		SkipOccurrence(ctx *gin.Context)
+		UpdateOccurrence(ctx *gin.Context)
+	}
+
+	SeriesControllerImpl struct {
+		seriesService service.SeriesService
+	}
+)
+
+func NewSeriesController(seriesService service.SeriesService) SeriesController {
+	return &SeriesControllerImpl{
+		seriesService: seriesService,
+	}
+}
+
+func (s *SeriesControllerImpl) GetSeriesList(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := s.seriesService.GetSeriesList(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (s *SeriesControllerImpl) GetSeries(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := s.seriesService.GetSeries(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (s *SeriesControllerImpl) AddSeries(ctx *gin.Context) {
+	var request model.SeriesRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := s.seriesService.AddSeries(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (s *SeriesControllerImpl) UpdateSeries(ctx *gin.Context) {
+	var request model.SeriesRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := s.seriesService.UpdateSeries(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (s *SeriesControllerImpl) EndSeries(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := s.seriesService.EndSeries(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+// GetOccurrenceList lists the occurrences from the "from" date through the "to" date (YYYY-MM-DD), by default the next 30 days
+func (s *SeriesControllerImpl) GetOccurrenceList(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	var from, to *time.Time
+	if fromStr := strings.TrimSpace(ctx.Query("from")); fromStr != "" {
+		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, fromStr)
+		if err == nil {
+			from = &timeObj
+		}
+	}
+
+	if toStr := strings.TrimSpace(ctx.Query("to")); toStr != "" {
+		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, toStr)
+		if err == nil {
+			timeObj = timeObj.AddDate(0, 0, 1)
+			to = &timeObj
+		}
+	}
+
+	result, err := s.seriesService.GetOccurrenceList(ctx, ctx.Param("id"), userData.UserId, from, to)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (s *SeriesControllerImpl) SkipOccurrence(ctx *gin.Context) {
+	var request model.OccurrenceRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := s.seriesService.SkipOccurrence(ctx, ctx.Param("id"), userData.UserId, request.OccurrenceAt)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+func (s *SeriesControllerImpl) UpdateOccurrence(ctx *gin.Context) {
+	var request model.UpdateOccurrenceRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := s.seriesService.UpdateOccurrence(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
@@ -0,0 +1,426 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	choreRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/repository"
+	laundryRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+const seriesColumns = "id, user_id, title, rrule, starts_at, timezone, items, is_active, generated_until, created_at, updated_at"
+
+type (
+	SeriesRepository interface {
+		GetSeriesList(ctx context.Context, userId string) ([]model.SeriesResponse, error)
+		GetSeries(ctx context.Context, id, userId string) (*model.SeriesResponse, error)
+		AddSeries(ctx context.Context, userId string, data model.SeriesData) (*model.SeriesResponse, error)
+		// UpdateSeries replaces the definition of the series. The upcoming routines that weren't edited or started
+		// are removed so the generator creates them again from the new definition.
+		UpdateSeries(ctx context.Context, id, userId string, data model.SeriesData) (*model.SeriesResponse, error)
+		// EndSeries stops the series, its upcoming routines that weren't edited or started are removed
+		EndSeries(ctx context.Context, id, userId string) error
+		GetExceptions(ctx context.Context, seriesId string, from, to time.Time) ([]model.SeriesException, error)
+		// GetSeriesLaundries returns the routines generated for the occurrences between from and to, inclusive
+		GetSeriesLaundries(ctx context.Context, seriesId string, from, to time.Time) ([]model.SeriesLaundry, error)
+		// SkipOccurrence records the occurrence as skipped and cancels its routine when it was already generated
+		SkipOccurrence(ctx context.Context, s model.SeriesResponse, occurrenceAt time.Time) error
+		// UpdateOccurrence generates the routine of the occurrence when needed, replaces its data and records
+		// the occurrence as modified. Returns the id of the routine.
+		UpdateOccurrence(ctx context.Context, s model.SeriesResponse, occurrenceAt time.Time, data model.NewLaundry) (string, error)
+		// GenerateOccurrences materializes the occurrences of a batch of active series up to the given time,
+		// expand returns the occurrences of a series between two times. The generated routines of a series with a
+		// rotation are assigned in turn, notify builds the notification of the assignee. Series being generated by
+		// another instance are skipped. Returns how many series were generated.
+		GenerateOccurrences(ctx context.Context, until time.Time, limit int, expand func(s model.SeriesResponse, from, to time.Time) ([]time.Time, error), notify func(a model.ChoreAssignment) model.Notification, seriesId ...string) (int, error)
+	}
+
+	SeriesRepositoryImpl struct {
+		db                database.DBCollection
+		laundryRepository laundryRepository.LaundryRepository
+		choreRepository   choreRepository.ChoreRepository
+	}
+)
+
+func NewSeriesRepository(db database.DBCollection, l laundryRepository.LaundryRepository, c choreRepository.ChoreRepository) SeriesRepository {
+	return &SeriesRepositoryImpl{
+		db:                db,
+		laundryRepository: l,
+		choreRepository:   c,
+	}
+}
+
+func (s *SeriesRepositoryImpl) GetSeriesList(ctx context.Context, userId string) ([]model.SeriesResponse, error) {
+	result := []model.SeriesResponse{}
+
+	query, args := squirrel.Select(seriesColumns).
+		From("laundry_series").
+		Where(squirrel.Eq{"user_id": userId}).
+		OrderBy("is_active DESC", "created_at DESC").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := s.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting series list:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (s *SeriesRepositoryImpl) GetSeries(ctx context.Context, id, userId string) (*model.SeriesResponse, error) {
+	query, args := squirrel.Select(seriesColumns).
+		From("laundry_series").
+		Where(squirrel.Eq{"id": id, "user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.SeriesResponse
+	if err := s.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting series:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (s *SeriesRepositoryImpl) AddSeries(ctx context.Context, userId string, data model.SeriesData) (*model.SeriesResponse, error) {
+	currentTime := utils.TimeNow()
+
+	query, args := squirrel.Insert("laundry_series").
+		Columns("id", "user_id", "title", "rrule", "starts_at", "timezone", "items", "is_active", "created_at", "updated_at").
+		Values(utils.GenerateCleanUUID(), userId, data.Title, data.RRule, data.StartsAt, data.Timezone, data.Items, true, currentTime, currentTime).
+		Suffix("RETURNING " + seriesColumns).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.SeriesResponse
+	if err := s.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when adding series:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (s *SeriesRepositoryImpl) UpdateSeries(ctx context.Context, id, userId string, data model.SeriesData) (*model.SeriesResponse, error) {
+	currentTime := utils.TimeNow()
+
+	var result model.SeriesResponse
+	err := database.WithTransaction(ctx, s.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Update("laundry_series").
+			Set("title", data.Title).
+			Set("rrule", data.RRule).
+			Set("starts_at", data.StartsAt).
+			Set("timezone", data.Timezone).
+			Set("items", data.Items).
+			Set("generated_until", nil).
+			Set("updated_at", currentTime).
+			Where(squirrel.Eq{"id": id, "user_id": userId, "is_active": true}).
+			Suffix("RETURNING " + seriesColumns).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if err := tx.GetContext(ctx, &result, query, args...); err != nil {
+			return err
+		}
+
+		return deleteUpcomingOccurrences(ctx, tx, id, currentTime)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when updating series:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (s *SeriesRepositoryImpl) EndSeries(ctx context.Context, id, userId string) error {
+	currentTime := utils.TimeNow()
+
+	err := database.WithTransaction(ctx, s.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Update("laundry_series").
+			Set("is_active", false).
+			Set("updated_at", currentTime).
+			Where(squirrel.Eq{"id": id, "user_id": userId, "is_active": true}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		return deleteUpcomingOccurrences(ctx, tx, id, currentTime)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when ending series:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (s *SeriesRepositoryImpl) GetExceptions(ctx context.Context, seriesId string, from, to time.Time) ([]model.SeriesException, error) {
+	result := []model.SeriesException{}
+
+	query, args := squirrel.Select("occurrence_at, kind").
+		From("laundry_series_exceptions").
+		Where(squirrel.Eq{"series_id": seriesId}).
+		Where(squirrel.GtOrEq{"occurrence_at": from}).
+		Where(squirrel.LtOrEq{"occurrence_at": to}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := s.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting series exceptions:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (s *SeriesRepositoryImpl) GetSeriesLaundries(ctx context.Context, seriesId string, from, to time.Time) ([]model.SeriesLaundry, error) {
+	result := []model.SeriesLaundry{}
+
+	query, args := squirrel.Select("occurrence_at, id, laundry_date, laundry_time, timezone, status").
+		From("laundries").
+		Where(squirrel.Eq{"series_id": seriesId}).
+		Where(squirrel.GtOrEq{"occurrence_at": from}).
+		Where(squirrel.LtOrEq{"occurrence_at": to}).
+		OrderBy("occurrence_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := s.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting series laundries:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (s *SeriesRepositoryImpl) SkipOccurrence(ctx context.Context, data model.SeriesResponse, occurrenceAt time.Time) error {
+	currentTime := utils.TimeNow()
+
+	err := database.WithTransaction(ctx, s.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		if err := upsertException(ctx, tx, data.Id, occurrenceAt, series.EXCEPTION_SKIPPED); err != nil {
+			return err
+		}
+
+		query, args := squirrel.Update("laundries").
+			Set("status", constants.LAUNDRY_STATUS_CANCELLED).
+			Set("updated_at", currentTime).
+			Where(squirrel.Eq{"series_id": data.Id, "occurrence_at": occurrenceAt, "status": constants.LAUNDRY_STATUS_PLANNED}).
+			Suffix("RETURNING id").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		var laundryId string
+		if err := tx.GetContext(ctx, &laundryId, query, args...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		queryLog, args := squirrel.Insert("laundry_status_logs").
+			Columns("laundry_id", "status", "created_by", "created_at").
+			Values(laundryId, constants.LAUNDRY_STATUS_CANCELLED, data.UserId, currentTime).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		_, err := tx.ExecContext(ctx, queryLog, args...)
+		return err
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when skipping series occurrence:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (s *SeriesRepositoryImpl) UpdateOccurrence(ctx context.Context, data model.SeriesResponse, occurrenceAt time.Time, laundry model.NewLaundry) (string, error) {
+	var laundryId string
+
+	err := database.WithTransaction(ctx, s.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		if _, err := s.laundryRepository.InsertLaundry(ctx, tx, data.UserId, occurrenceLaundry(data, occurrenceAt)); err != nil {
+			return err
+		}
+
+		// only a routine that hasn't started can be edited
+		query, args := squirrel.Select("id").
+			From("laundries").
+			Where(squirrel.Eq{"series_id": data.Id, "occurrence_at": occurrenceAt, "status": constants.LAUNDRY_STATUS_PLANNED}).
+			Suffix("FOR UPDATE").
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if err := tx.GetContext(ctx, &laundryId, query, args...); err != nil {
+			return err
+		}
+
+		if err := s.laundryRepository.UpdateLaundryData(ctx, tx, laundryId, laundry); err != nil {
+			return err
+		}
+
+		return upsertException(ctx, tx, data.Id, occurrenceAt, series.EXCEPTION_MODIFIED)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when updating series occurrence:", err)
+		return "", errorutils.DefineSQLError(err)
+	}
+
+	return laundryId, nil
+}
+
+func (s *SeriesRepositoryImpl) GenerateOccurrences(ctx context.Context, until time.Time, limit int, expand func(s model.SeriesResponse, from, to time.Time) ([]time.Time, error), notify func(a model.ChoreAssignment) model.Notification, seriesId ...string) (int, error) {
+	currentTime := utils.TimeNow()
+	generated := 0
+
+	err := database.WithTransaction(ctx, s.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		// the claimed series stay locked until they're generated, other instances skip them
+		query := squirrel.Select(seriesColumns).
+			From("laundry_series").
+			Where(squirrel.Eq{"is_active": true}).
+			Where(squirrel.Or{squirrel.Eq{"generated_until": nil}, squirrel.Lt{"generated_until": until}}).
+			OrderBy("generated_until NULLS FIRST", "id").
+			Limit(uint64(limit)).
+			Suffix("FOR UPDATE SKIP LOCKED")
+
+		if len(seriesId) > 0 {
+			query = query.Where(squirrel.Eq{"id": seriesId})
+		}
+
+		querySeries, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		var due []model.SeriesResponse
+		if err := tx.SelectContext(ctx, &due, querySeries, args...); err != nil {
+			return err
+		}
+
+		for _, item := range due {
+			// past occurrences are never backfilled
+			from := currentTime
+			if item.GeneratedUntil != nil && item.GeneratedUntil.After(from) {
+				from = *item.GeneratedUntil
+			}
+
+			occurrences, err := expand(item, from, until)
+			if err != nil {
+				return err
+			}
+
+			querySkipped, args := squirrel.Select("occurrence_at").
+				From("laundry_series_exceptions").
+				Where(squirrel.Eq{"series_id": item.Id, "kind": series.EXCEPTION_SKIPPED}).
+				Where(squirrel.GtOrEq{"occurrence_at": from}).
+				PlaceholderFormat(squirrel.Dollar).MustSql()
+
+			var skipped []time.Time
+			if err := tx.SelectContext(ctx, &skipped, querySkipped, args...); err != nil {
+				return err
+			}
+
+			isSkipped := map[int64]bool{}
+			for _, occurrenceAt := range skipped {
+				isSkipped[occurrenceAt.Unix()] = true
+			}
+
+			for _, occurrenceAt := range occurrences {
+				if isSkipped[occurrenceAt.Unix()] {
+					continue
+				}
+
+				laundryId, err := s.laundryRepository.InsertLaundry(ctx, tx, item.UserId, occurrenceLaundry(item, occurrenceAt))
+				if err != nil {
+					return err
+				}
+
+				if err := s.choreRepository.AssignNextTurn(ctx, tx, item.Id, laundryId, notify); err != nil {
+					return err
+				}
+			}
+
+			queryUpdate, args := squirrel.Update("laundry_series").
+				Set("generated_until", until).
+				Where(squirrel.Eq{"id": item.Id}).
+				PlaceholderFormat(squirrel.Dollar).MustSql()
+
+			if _, err := tx.ExecContext(ctx, queryUpdate, args...); err != nil {
+				return err
+			}
+		}
+
+		generated = len(due)
+		return nil
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when generating series occurrences:", err)
+		return 0, errorutils.DefineSQLError(err)
+	}
+
+	return generated, nil
+}
+
+// occurrenceLaundry is the routine of an occurrence as defined by the series, planned at its time in the series timezone
+func occurrenceLaundry(s model.SeriesResponse, occurrenceAt time.Time) model.NewLaundry {
+	location, err := utils.LoadTimezone(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	laundryDate, laundryTime, timezone := model.SplitPlannedAt(occurrenceAt, location)
+
+	return model.NewLaundry{
+		Title:        s.Title,
+		LaundryDate:  laundryDate,
+		LaundryTime:  laundryTime,
+		Timezone:     timezone,
+		Items:        s.Items,
+		SeriesId:     &s.Id,
+		OccurrenceAt: &occurrenceAt,
+	}
+}
+
+func upsertException(ctx context.Context, tx sqlx.ExtContext, seriesId string, occurrenceAt time.Time, kind series.ExceptionKind) error {
+	query, args := squirrel.Insert("laundry_series_exceptions").
+		Columns("series_id", "occurrence_at", "kind", "created_at").
+		Values(seriesId, occurrenceAt, kind, utils.TimeNow()).
+		Suffix("ON CONFLICT (series_id, occurrence_at) DO UPDATE SET kind = EXCLUDED.kind").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// deleteUpcomingOccurrences removes the upcoming routines of the series that are still planned and weren't edited
+func deleteUpcomingOccurrences(ctx context.Context, tx sqlx.ExtContext, seriesId string, now time.Time) error {
+	upcoming := squirrel.Select("l.id").
+		From("laundries l").
+		Where(squirrel.Eq{"l.series_id": seriesId, "l.status": constants.LAUNDRY_STATUS_PLANNED}).
+		Where(squirrel.GtOrEq{"l.occurrence_at": now}).
+		Where("NOT EXISTS (SELECT 1 FROM laundry_series_exceptions e WHERE e.series_id = l.series_id AND e.occurrence_at = l.occurrence_at)")
+
+	upcomingSql, upcomingArgs := upcoming.MustSql()
+
+	queryItems, args := squirrel.Delete("laundry_items").
+		Where("laundry_id IN ("+upcomingSql+")", upcomingArgs...).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if _, err := tx.ExecContext(ctx, queryItems, args...); err != nil {
+		return err
+	}
+
+	queryLaundries, args := squirrel.Delete("laundries").
+		Where("id IN ("+upcomingSql+")", upcomingArgs...).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	_, err := tx.ExecContext(ctx, queryLaundries, args...)
+	return err
+}
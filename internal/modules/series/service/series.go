@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	choreService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/service"
+	laundryService "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/teambition/rrule-go"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	SeriesService interface {
+		GetSeriesList(ctx context.Context, userId string) ([]model.SeriesResponse, error)
+		GetSeries(ctx context.Context, id, userId string) (*model.SeriesResponse, error)
+		AddSeries(ctx context.Context, userId string, request model.SeriesRequest) (*model.SeriesResponse, error)
+		UpdateSeries(ctx context.Context, id, userId string, request model.SeriesRequest) (*model.SeriesResponse, error)
+		EndSeries(ctx context.Context, id, userId string) error
+		// GetOccurrenceList lists the occurrences between from and to with the routine generated for each of them
+		GetOccurrenceList(ctx context.Context, id, userId string, from, to *time.Time) ([]model.SeriesOccurrenceResponse, error)
+		SkipOccurrence(ctx context.Context, id, userId string, occurrenceAt time.Time) error
+		UpdateOccurrence(ctx context.Context, id, userId string, request model.UpdateOccurrenceRequest) (*model.LaundryDetailResponse, error)
+		// GenerateOccurrences materializes the upcoming occurrences of every active series, it's run by the generator
+		GenerateOccurrences(ctx context.Context) error
+	}
+
+	SeriesServiceImpl struct {
+		seriesRepository repository.SeriesRepository
+		laundryService   laundryService.LaundryService
+		choreService     choreService.ChoreService
+	}
+)
+
+func NewSeriesService(s repository.SeriesRepository, l laundryService.LaundryService, c choreService.ChoreService) SeriesService {
+	return &SeriesServiceImpl{
+		seriesRepository: s,
+		laundryService:   l,
+		choreService:     c,
+	}
+}
+
+func (s *SeriesServiceImpl) GetSeriesList(ctx context.Context, userId string) ([]model.SeriesResponse, error) {
+	result, err := s.seriesRepository.GetSeriesList(ctx, userId)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range result {
+		result[i].StartsAtString = result[i].StartsAt.Format(constants.FORMAT_DATETIME_DEFAULT)
+	}
+
+	return result, nil
+}
+
+func (s *SeriesServiceImpl) GetSeries(ctx context.Context, id, userId string) (*model.SeriesResponse, error) {
+	result, err := s.seriesRepository.GetSeries(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result.StartsAtString = result.StartsAt.Format(constants.FORMAT_DATETIME_DEFAULT)
+
+	return result, nil
+}
+
+func (s *SeriesServiceImpl) AddSeries(ctx context.Context, userId string, request model.SeriesRequest) (*model.SeriesResponse, error) {
+	data, err := s.seriesData(ctx, userId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.seriesRepository.AddSeries(ctx, userId, *data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.generate(ctx, result.Id, userId)
+}
+
+func (s *SeriesServiceImpl) UpdateSeries(ctx context.Context, id, userId string, request model.SeriesRequest) (*model.SeriesResponse, error) {
+	data, err := s.seriesData(ctx, userId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.seriesRepository.UpdateSeries(ctx, id, userId, *data); err != nil {
+		return nil, err
+	}
+
+	return s.generate(ctx, id, userId)
+}
+
+func (s *SeriesServiceImpl) EndSeries(ctx context.Context, id, userId string) error {
+	return s.seriesRepository.EndSeries(ctx, id, userId)
+}
+
+func (s *SeriesServiceImpl) GetOccurrenceList(ctx context.Context, id, userId string, from, to *time.Time) ([]model.SeriesOccurrenceResponse, error) {
+	data, err := s.seriesRepository.GetSeries(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeFrom := utils.TimeNow()
+	if from != nil {
+		rangeFrom = from.UTC()
+	}
+
+	rangeTo := rangeFrom.AddDate(0, 0, series.OCCURRENCE_LIST_DAYS)
+	if to != nil {
+		rangeTo = to.UTC()
+	}
+
+	if rangeTo.Before(rangeFrom) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+	}
+
+	occurrences, err := expandOccurrences(*data, rangeFrom, rangeTo)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
+
+	exceptions, err := s.seriesRepository.GetExceptions(ctx, id, rangeFrom, rangeTo)
+	if err != nil {
+		return nil, err
+	}
+
+	laundries, err := s.seriesRepository.GetSeriesLaundries(ctx, id, rangeFrom, rangeTo)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+
+	// occurrences are keyed by their original time, an edited routine may no longer match the rule
+	byTime := map[int64]*model.SeriesOccurrenceResponse{}
+	add := func(occurrenceAt time.Time) *model.SeriesOccurrenceResponse {
+		if item, ok := byTime[occurrenceAt.Unix()]; ok {
+			return item
+		}
+		item := &model.SeriesOccurrenceResponse{OccurrenceAt: occurrenceAt.UTC()}
+		byTime[occurrenceAt.Unix()] = item
+		return item
+	}
+
+	for _, occurrenceAt := range occurrences {
+		add(occurrenceAt)
+	}
+
+	for _, laundry := range laundries {
+		item := add(laundry.OccurrenceAt)
+		item.LaundryId = &laundry.Id
+		item.LaundryDate = &laundry.LaundryDate
+		if plannedAt, ok := model.LaundryPlannedAt(laundry.LaundryDate, laundry.LaundryTime, laundry.Timezone); ok {
+			plannedAt = plannedAt.UTC()
+			item.LaundryDate = &plannedAt
+		}
+		label := model.LaundryStatusLabel(locale, constants.LaundryStatus(laundry.Status))
+		item.StatusLabel = &label
+	}
+
+	for _, exception := range exceptions {
+		item := add(exception.OccurrenceAt)
+		item.Skipped = series.ExceptionKind(exception.Kind) == series.EXCEPTION_SKIPPED
+		item.Modified = series.ExceptionKind(exception.Kind) == series.EXCEPTION_MODIFIED
+	}
+
+	result := []model.SeriesOccurrenceResponse{}
+	for _, item := range byTime {
+		result = append(result, *item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OccurrenceAt.Before(result[j].OccurrenceAt)
+	})
+
+	return result, nil
+}
+
+func (s *SeriesServiceImpl) SkipOccurrence(ctx context.Context, id, userId string, occurrenceAt time.Time) error {
+	data, err := s.seriesRepository.GetSeries(ctx, id, userId)
+	if err != nil {
+		return err
+	}
+
+	occurrenceAt = occurrenceAt.UTC()
+
+	laundry, err := s.occurrenceLaundry(ctx, *data, occurrenceAt)
+	if err != nil {
+		return err
+	}
+
+	if laundry != nil && laundry.Status != int(constants.LAUNDRY_STATUS_PLANNED) && laundry.Status != int(constants.LAUNDRY_STATUS_CANCELLED) {
+		return errorutils.ErrorBadRequest.CustomMessage("the routine of this occurrence has already started")
+	}
+
+	return s.seriesRepository.SkipOccurrence(ctx, *data, occurrenceAt)
+}
+
+func (s *SeriesServiceImpl) UpdateOccurrence(ctx context.Context, id, userId string, request model.UpdateOccurrenceRequest) (*model.LaundryDetailResponse, error) {
+	data, err := s.seriesRepository.GetSeries(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrenceAt := request.OccurrenceAt.UTC()
+
+	laundry, err := s.occurrenceLaundry(ctx, *data, occurrenceAt)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := s.seriesRepository.GetExceptions(ctx, id, occurrenceAt, occurrenceAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, exception := range exceptions {
+		if series.ExceptionKind(exception.Kind) == series.EXCEPTION_SKIPPED {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("the occurrence is skipped")
+		}
+	}
+
+	location, err := utils.LoadTimezone(data.Timezone)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid timezone")
+	}
+
+	// omitted fields keep the current values of the routine, or the series ones when it isn't generated yet
+	values := model.NewLaundry{
+		Title: data.Title,
+		Items: data.Items,
+	}
+	values.LaundryDate, values.LaundryTime, values.Timezone = model.SplitPlannedAt(occurrenceAt, location)
+
+	if laundry != nil {
+		if laundry.Status != int(constants.LAUNDRY_STATUS_PLANNED) {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("the routine of this occurrence has already started")
+		}
+
+		detail, err := s.laundryService.GetLaundryDetail(ctx, laundry.Id, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		values.Title = detail.Title
+		values.LaundryDate, values.LaundryTime, values.Timezone = detail.LaundryDate, detail.LaundryTime, detail.Timezone
+		values.Items = []model.LaundryItemsRequest{}
+		for _, item := range detail.Items {
+			values.Items = append(values.Items, model.LaundryItemsRequest{CategoryId: item.CategoryId, Amount: item.Amount, Notes: item.Notes})
+		}
+	}
+
+	if request.Title != nil {
+		values.Title = *request.Title
+	}
+
+	if request.LaundryDate != nil {
+		values.LaundryDate, values.LaundryTime, values.Timezone = model.SplitPlannedAt(*request.LaundryDate, location)
+	}
+
+	if request.Items != nil {
+		if err := s.laundryService.ValidateItems(ctx, userId, request.Items); err != nil {
+			return nil, err
+		}
+		values.Items = request.Items
+	}
+
+	laundryId, err := s.seriesRepository.UpdateOccurrence(ctx, *data, occurrenceAt, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.laundryService.GetLaundryDetail(ctx, laundryId, userId)
+}
+
+func (s *SeriesServiceImpl) GenerateOccurrences(ctx context.Context) error {
+	log := logging.WithContext(ctx)
+	until := utils.TimeNow().AddDate(0, 0, series.GENERATION_HORIZON_DAYS)
+
+	for ctx.Err() == nil {
+		generated, err := s.seriesRepository.GenerateOccurrences(ctx, until, series.BATCH_SIZE, expandOccurrences, s.choreService.AssignmentNotification)
+		if err != nil {
+			return err
+		}
+
+		if generated > 0 {
+			log.Infof("%d series generated until %s", generated, until.Format(constants.FORMAT_DATETIME_DEFAULT))
+		}
+
+		if generated < series.BATCH_SIZE {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// generate materializes the upcoming occurrences of the series right away, instead of waiting for the generator
+func (s *SeriesServiceImpl) generate(ctx context.Context, id, userId string) (*model.SeriesResponse, error) {
+	until := utils.TimeNow().AddDate(0, 0, series.GENERATION_HORIZON_DAYS)
+
+	if _, err := s.seriesRepository.GenerateOccurrences(ctx, until, 1, expandOccurrences, s.choreService.AssignmentNotification, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetSeries(ctx, id, userId)
+}
+
+// occurrenceLaundry validates that the time is an occurrence of the series and returns its routine, if generated
+func (s *SeriesServiceImpl) occurrenceLaundry(ctx context.Context, data model.SeriesResponse, occurrenceAt time.Time) (*model.SeriesLaundry, error) {
+	laundries, err := s.seriesRepository.GetSeriesLaundries(ctx, data.Id, occurrenceAt, occurrenceAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(laundries) > 0 {
+		return &laundries[0], nil
+	}
+
+	occurrences, err := expandOccurrences(data, occurrenceAt, occurrenceAt)
+	if err != nil || len(occurrences) == 0 {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid occurrence")
+	}
+
+	return nil, nil
+}
+
+func (s *SeriesServiceImpl) seriesData(ctx context.Context, userId string, request model.SeriesRequest) (*model.SeriesData, error) {
+	startsAt, err := time.Parse(constants.FORMAT_DATETIME_DEFAULT, request.StartsAt)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid starts_at")
+	}
+
+	data := model.SeriesData{
+		Title:    request.Title,
+		RRule:    strings.TrimPrefix(strings.TrimSpace(request.RRule), "RRULE:"),
+		StartsAt: startsAt,
+		Timezone: request.Timezone,
+		Items:    request.Items,
+	}
+
+	if _, err := parseRule(data.RRule, data.Timezone, data.StartsAt); err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage(err.Error())
+	}
+
+	if err := s.laundryService.ValidateItems(ctx, userId, request.Items); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// expandOccurrences returns the occurrences of the series between from and to, inclusive, in UTC
+func expandOccurrences(s model.SeriesResponse, from, to time.Time) ([]time.Time, error) {
+	rule, err := parseRule(s.RRule, s.Timezone, s.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []time.Time{}
+	for _, occurrenceAt := range rule.Between(from, to, true) {
+		result = append(result, occurrenceAt.UTC())
+	}
+
+	return result, nil
+}
+
+// parseRule builds the recurrence of a series, startsAt is the wall-clock time of the first occurrence in the
+// timezone, so a weekly wash at 09:00 stays at 09:00 across daylight saving changes
+func parseRule(value, timezone string, startsAt time.Time) (*rrule.RRule, error) {
+	location, err := utils.LoadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	option, err := rrule.StrToROptionInLocation(value, location)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid rrule: " + err.Error())
+	}
+
+	// routines are planned by the day at most, more frequent rules would flood the list
+	if option.Freq > rrule.DAILY {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("rrule can't repeat more often than daily")
+	}
+
+	option.Dtstart = time.Date(startsAt.Year(), startsAt.Month(), startsAt.Day(), startsAt.Hour(), startsAt.Minute(), startsAt.Second(), 0, location)
+
+	return rrule.NewRRule(*option)
+}
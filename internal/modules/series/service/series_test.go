@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExpandOccurrences(t *testing.T) {
+	utc := func(day, hour int) time.Time {
+		return time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		series   model.SeriesResponse
+		from, to time.Time
+		want     []time.Time
+		wantErr  bool
+	}{
+		{
+			name:   "weekly in a fixed offset",
+			series: model.SeriesResponse{RRule: "FREQ=WEEKLY;BYDAY=SA", Timezone: "Asia/Jakarta", StartsAt: utc(2, 9)},
+			from:   utc(1, 0),
+			to:     utc(16, 2),
+			want:   []time.Time{utc(2, 2), utc(9, 2), utc(16, 2)},
+		},
+		{
+			name:   "same local time across daylight saving",
+			series: model.SeriesResponse{RRule: "FREQ=WEEKLY", Timezone: "America/New_York", StartsAt: utc(2, 9)},
+			from:   utc(1, 0),
+			to:     utc(20, 0),
+			want:   []time.Time{utc(2, 14), utc(9, 14), utc(16, 13)},
+		},
+		{
+			name:   "count and range",
+			series: model.SeriesResponse{RRule: "FREQ=DAILY;COUNT=5", Timezone: "UTC", StartsAt: utc(1, 8)},
+			from:   utc(3, 0),
+			to:     utc(31, 0),
+			want:   []time.Time{utc(3, 8), utc(4, 8), utc(5, 8)},
+		},
+		{
+			name:   "nothing in range",
+			series: model.SeriesResponse{RRule: "FREQ=MONTHLY", Timezone: "UTC", StartsAt: utc(1, 8)},
+			from:   utc(2, 0),
+			to:     utc(31, 0),
+			want:   []time.Time{},
+		},
+		{
+			name:    "more often than daily",
+			series:  model.SeriesResponse{RRule: "FREQ=HOURLY", Timezone: "UTC", StartsAt: utc(1, 8)},
+			from:    utc(1, 0),
+			to:      utc(2, 0),
+			wantErr: true,
+		},
+		{
+			name:    "invalid rule",
+			series:  model.SeriesResponse{RRule: "FREQ=SOMETIMES", Timezone: "UTC", StartsAt: utc(1, 8)},
+			from:    utc(1, 0),
+			to:      utc(2, 0),
+			wantErr: true,
+		},
+		{
+			name:    "invalid timezone",
+			series:  model.SeriesResponse{RRule: "FREQ=DAILY", Timezone: "Local", StartsAt: utc(1, 8)},
+			from:    utc(1, 0),
+			to:      utc(2, 0),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandOccurrences(tt.series, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package stats
+
+type Interval string
+
+const (
+	INTERVAL_WEEK  Interval = "week"
+	INTERVAL_MONTH Interval = "month"
+)
+
+const (
+	// DEFAULT_RANGE_DAYS is the range of the stats when no start date is given
+	DEFAULT_RANGE_DAYS = 90
+
+	// MAX_RANGE_DAYS bounds the range so the series stay readable and the queries cheap
+	MAX_RANGE_DAYS = 731
+)
+
+// series keys of the routine chart
+const (
+	SERIES_TOTAL     = "total"
+	SERIES_DONE      = "done"
+	SERIES_CANCELLED = "cancelled"
+	SERIES_ITEMS     = "items"
+	SERIES_ROUTINES  = "routines"
+)
+
+func (i Interval) IsValid() bool {
+	return i == INTERVAL_WEEK || i == INTERVAL_MONTH
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type (
+	StatsController interface {
+		GetStats(ctx *gin.Context)
+		GetStatsPage(ctx *gin.Context)
+	}
+
+	StatsControllerImpl struct {
+		statsService service.StatsService
+	}
+)
+
+func NewStatsController(statsService service.StatsService) StatsController {
+	return &StatsControllerImpl{
+		statsService: statsService,
+	}
+}
+
+// GetStats aggregates the routines from the "from" date through the "to" date (YYYY-MM-DD), by default the last 90 days,
+// per "interval" (week or month)
+func (s *StatsControllerImpl) GetStats(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	queryParam := model.StatsQueryParam{
+		From:     ctx.Query("from"),
+		To:       ctx.Query("to"),
+		Interval: ctx.Query("interval"),
+	}
+
+	result, err := s.statsService.GetStats(ctx, userData.UserId, queryParam)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// GetStatsPage renders the stats of the "from" through "to" range as charts, with the volume per month
+func (s *StatsControllerImpl) GetStatsPage(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		httputils.InvalidateCookie(ctx, constants.COOKIE_AUTH_TOKEN)
+		ctx.Redirect(http.StatusTemporaryRedirect, "/login")
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+	locale := i18n.LocaleFromContext(ctx)
+
+	queryParam := model.StatsQueryParam{
+		From:     ctx.Query("from"),
+		To:       ctx.Query("to"),
+		Interval: string(stats.INTERVAL_MONTH),
+	}
+
+	result, err := s.statsService.GetStats(ctx, userData.UserId, queryParam)
+	if err != nil {
+		statusCode, message := errorutils.GetStatusCode(err)
+		httputils.SetHtmlResponse(ctx, statusCode, "stats.html", gin.H{
+			"error": i18n.TranslateError(locale, message),
+			"from":  queryParam.From,
+			"to":    queryParam.To,
+		})
+		return
+	}
+
+	result.Routines.Labels = monthLabels(locale, result.Routines.Labels)
+
+	dataHtml := gin.H{
+		"data": result,
+		"from": result.From,
+		"to":   result.To,
+	}
+
+	if result.AverageTurnaroundHours != nil {
+		dataHtml["average_turnaround"] = fmt.Sprintf("%.1f", *result.AverageTurnaroundHours)
+	}
+
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "stats.html", dataHtml)
+}
+
+// monthLabels turns the first days of the months (YYYY-MM-DD) into short localized names, e.g. "Jan 2025"
+func monthLabels(locale string, labels []string) []string {
+	result := make([]string, len(labels))
+	for i, label := range labels {
+		month, err := time.Parse(constants.FORMAT_DATE_DEFAULT, label)
+		if err != nil {
+			result[i] = label
+			continue
+		}
+		result[i] = fmt.Sprintf("%s %d", string([]rune(i18n.MonthName(locale, month.Month()))[:3]), month.Year())
+	}
+	return result
+}
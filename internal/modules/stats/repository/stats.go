@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+)
+
+type (
+	StatsRepository interface {
+		// GetRoutineBuckets counts the routines per week or month, the buckets without routines are left out
+		GetRoutineBuckets(ctx context.Context, filter model.StatsFilter, interval stats.Interval) ([]model.StatsBucket, error)
+		// GetItemsPerCategory sums the items of the routines that weren't cancelled per category
+		GetItemsPerCategory(ctx context.Context, filter model.StatsFilter) ([]model.LaundryCategoryAmount, error)
+		// GetStatusCounts counts the routines per current status, the statuses without routines are left out
+		GetStatusCounts(ctx context.Context, filter model.StatsFilter) ([]model.StatsStatus, error)
+		// GetWeekdays counts the routines that weren't cancelled per day of the week
+		GetWeekdays(ctx context.Context, filter model.StatsFilter) ([]model.StatsWeekday, error)
+		// GetAverageTurnaround is the average hours from planned to done of the done routines, nil when there are none
+		GetAverageTurnaround(ctx context.Context, filter model.StatsFilter) (*float64, error)
+		// GetLongestOnTimeStreak is the most routines in a row, by planned time, that were done by their planned date.
+		// Cancelled routines don't break the streak, the filter should end by today so upcoming routines don't either.
+		GetLongestOnTimeStreak(ctx context.Context, filter model.StatsFilter) (int, error)
+	}
+
+	StatsRepositoryImpl struct {
+		db database.DBCollection
+	}
+)
+
+func NewStatsRepository(db database.DBCollection) StatsRepository {
+	return &StatsRepositoryImpl{
+		db: db,
+	}
+}
+
+func (s *StatsRepositoryImpl) GetRoutineBuckets(ctx context.Context, filter model.StatsFilter, interval stats.Interval) ([]model.StatsBucket, error) {
+	result := []model.StatsBucket{}
+
+	query, args := filterStats(squirrel.Select(), filter).
+		Column(squirrel.Expr("date_trunc(?, l.laundry_date) AS bucket", string(interval))).
+		Column("COUNT(*) AS total").
+		Column(squirrel.Expr("COUNT(*) FILTER (WHERE l.status = ?) AS done", constants.LAUNDRY_STATUS_DONE)).
+		Column(squirrel.Expr("COUNT(*) FILTER (WHERE l.status = ?) AS cancelled", constants.LAUNDRY_STATUS_CANCELLED)).
+		GroupBy("bucket").
+		OrderBy("bucket").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := s.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when counting routines per bucket:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (s *StatsRepositoryImpl) GetItemsPerCategory(ctx context.Context, filter model.StatsFilter) ([]model.LaundryCategoryAmount, error) {
+	result := []model.LaundryCategoryAmount{}
+
+	query, args := filterStats(squirrel.Select("c.name AS category_name, SUM(li.amount) AS amount"), filter).
+		Join("laundry_items li ON li.laundry_id = l.id").
+		Join("categories c ON c.id = li.category_id").
+		Where(squirrel.NotEq{"l.status": constants.LAUNDRY_STATUS_CANCELLED}).
+		GroupBy("c.name").
+		OrderBy("amount DESC", "c.name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := s.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when summing items per category:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (s *StatsRepositoryImpl) GetStatusCounts(ctx context.Context, filter model.StatsFilter) ([]model.StatsStatus, error) {
+	result := []model.StatsStatus{}
+
+	query, args := filterStats(squirrel.Select("l.status, COUNT(*) AS total"), filter).
+		GroupBy("l.status").
+		OrderBy("l.status").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := s.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when counting routines per status:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (s *StatsRepositoryImpl) GetWeekdays(ctx context.Context, filter model.StatsFilter) ([]model.StatsWeekday, error) {
+	result := []model.StatsWeekday{}
+
+	query, args := filterStats(squirrel.Select(), filter).
+		Column("EXTRACT(ISODOW FROM l.laundry_date)::INT AS weekday").
+		Column("COUNT(*) AS total").
+		Where(squirrel.NotEq{"l.status": constants.LAUNDRY_STATUS_CANCELLED}).
+		GroupBy("weekday").
+		OrderBy("weekday").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := s.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when counting routines per weekday:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (s *StatsRepositoryImpl) GetAverageTurnaround(ctx context.Context, filter model.StatsFilter) (*float64, error) {
+	// the first time each done routine was planned and done
+	turnarounds := filterStats(squirrel.Select(), filter).
+		Column(squirrel.Expr("MIN(lsl.created_at) FILTER (WHERE lsl.status = ?) AS planned_at", constants.LAUNDRY_STATUS_PLANNED)).
+		Column(squirrel.Expr("MIN(lsl.created_at) FILTER (WHERE lsl.status = ?) AS done_at", constants.LAUNDRY_STATUS_DONE)).
+		Join("laundry_status_logs lsl ON lsl.laundry_id = l.id").
+		Where(squirrel.Eq{"l.status": constants.LAUNDRY_STATUS_DONE}).
+		GroupBy("l.id")
+
+	query, args := squirrel.Select("AVG(EXTRACT(EPOCH FROM t.done_at - t.planned_at)) / 3600").
+		FromSelect(turnarounds, "t").
+		Where("t.planned_at IS NOT NULL AND t.done_at >= t.planned_at").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result sql.NullFloat64
+	if err := s.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting average turnaround:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	if !result.Valid {
+		return nil, nil
+	}
+	return &result.Float64, nil
+}
+
+func (s *StatsRepositoryImpl) GetLongestOnTimeStreak(ctx context.Context, filter model.StatsFilter) (int, error) {
+	// whether each routine was done by its planned date, in the user's timezone
+	routines := filterStats(squirrel.Select("l.id, l.laundry_date, l.laundry_time"), filter).
+		Column(squirrel.Expr(
+			"(l.status = ? AND d.done_at IS NOT NULL AND ((d.done_at AT TIME ZONE 'UTC') AT TIME ZONE ?)::DATE <= l.laundry_date::DATE) AS on_time",
+			constants.LAUNDRY_STATUS_DONE, filter.Timezone,
+		)).
+		LeftJoin("LATERAL (SELECT MIN(lsl.created_at) AS done_at FROM laundry_status_logs lsl WHERE lsl.laundry_id = l.id AND lsl.status = ?) d ON TRUE", constants.LAUNDRY_STATUS_DONE).
+		Where(squirrel.NotEq{"l.status": constants.LAUNDRY_STATUS_CANCELLED})
+
+	// consecutive routines with the same outcome share a group (gaps and islands)
+	groups := squirrel.Select("r.on_time, ROW_NUMBER() OVER (ORDER BY r.laundry_date, r.laundry_time NULLS FIRST, r.id) - ROW_NUMBER() OVER (PARTITION BY r.on_time ORDER BY r.laundry_date, r.laundry_time NULLS FIRST, r.id) AS grp").
+		FromSelect(routines, "r")
+
+	streaks := squirrel.Select("COUNT(*) AS streak").
+		FromSelect(groups, "g").
+		Where("g.on_time").
+		GroupBy("g.grp")
+
+	query, args := squirrel.Select("COALESCE(MAX(s.streak), 0)").
+		FromSelect(streaks, "s").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result int
+	if err := s.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting longest on-time streak:", err)
+		return 0, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+// filterStats selects the routines of the user and their household planned between the dates of the filter, both inclusive
+func filterStats(query squirrel.SelectBuilder, filter model.StatsFilter) squirrel.SelectBuilder {
+	return query.From("laundries l").
+		Where(database.AccessibleBy("l.", filter.UserId, false)).
+		Where(squirrel.GtOrEq{"l.laundry_date": filter.From}).
+		Where(squirrel.Lt{"l.laundry_date": filter.To.AddDate(0, 0, 1)})
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	preferenceRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/preference/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"strings"
+	"time"
+)
+
+type (
+	StatsService interface {
+		// GetStats aggregates the routines of the user planned in the range, bucketed in the user's timezone
+		GetStats(ctx context.Context, userId string, queryParam model.StatsQueryParam) (*model.StatsResponse, error)
+	}
+
+	StatsServiceImpl struct {
+		statsRepository      repository.StatsRepository
+		preferenceRepository preferenceRepository.PreferenceRepository
+	}
+)
+
+func NewStatsService(s repository.StatsRepository, p preferenceRepository.PreferenceRepository) StatsService {
+	return &StatsServiceImpl{
+		statsRepository:      s,
+		preferenceRepository: p,
+	}
+}
+
+func (s *StatsServiceImpl) GetStats(ctx context.Context, userId string, queryParam model.StatsQueryParam) (*model.StatsResponse, error) {
+	timezone, err := s.preferenceRepository.GetTimezone(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := utils.LoadTimezone(timezone)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when loading timezone of user:", err)
+		return nil, err
+	}
+
+	interval := stats.INTERVAL_WEEK
+	if value := strings.TrimSpace(queryParam.Interval); value != "" {
+		interval = stats.Interval(value)
+	}
+	if !interval.IsValid() {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid interval")
+	}
+
+	// today is taken in the user's timezone, dates are kept at midnight UTC like the date-only routines
+	now := utils.TimeNow().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	filter := model.StatsFilter{
+		UserId:   userId,
+		Timezone: timezone,
+		From:     today.AddDate(0, 0, 1-stats.DEFAULT_RANGE_DAYS),
+		To:       today,
+	}
+
+	if value := strings.TrimSpace(queryParam.To); value != "" {
+		if filter.To, err = time.Parse(constants.FORMAT_DATE_DEFAULT, value); err != nil {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+		}
+		filter.From = filter.To.AddDate(0, 0, 1-stats.DEFAULT_RANGE_DAYS)
+	}
+
+	if value := strings.TrimSpace(queryParam.From); value != "" {
+		if filter.From, err = time.Parse(constants.FORMAT_DATE_DEFAULT, value); err != nil {
+			return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+		}
+	}
+
+	if filter.From.After(filter.To) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+	}
+	if filter.To.Sub(filter.From) >= stats.MAX_RANGE_DAYS*24*time.Hour {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("the date range is too long")
+	}
+
+	buckets, err := s.statsRepository.GetRoutineBuckets(ctx, filter, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.statsRepository.GetItemsPerCategory(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.statsRepository.GetStatusCounts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	weekdays, err := s.statsRepository.GetWeekdays(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	turnaround, err := s.statsRepository.GetAverageTurnaround(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// upcoming routines can't be late yet, so the streak only counts the routines due by today
+	streakFilter := filter
+	if streakFilter.To.After(today) {
+		streakFilter.To = today
+	}
+
+	streak := 0
+	if !streakFilter.From.After(streakFilter.To) {
+		if streak, err = s.statsRepository.GetLongestOnTimeStreak(ctx, streakFilter); err != nil {
+			return nil, err
+		}
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+
+	result := model.StatsResponse{
+		From:                   filter.From.Format(constants.FORMAT_DATE_DEFAULT),
+		To:                     filter.To.Format(constants.FORMAT_DATE_DEFAULT),
+		Interval:               string(interval),
+		Timezone:               timezone,
+		Routines:               routineSeries(locale, filter, interval, buckets),
+		ItemsPerCategory:       categorySeries(locale, categories),
+		Statuses:               statusSeries(locale, statuses),
+		AverageTurnaroundHours: turnaround,
+		LongestOnTimeStreak:    streak,
+	}
+	result.Weekdays, result.BusiestWeekday = weekdaySeries(locale, weekdays)
+
+	return &result, nil
+}
+
+// routineSeries has a label for every bucket of the range, the buckets without routines count zero
+func routineSeries(locale string, filter model.StatsFilter, interval stats.Interval, buckets []model.StatsBucket) model.ChartSeries {
+	counts := make(map[string]model.StatsBucket, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Bucket.Format(constants.FORMAT_DATE_DEFAULT)] = bucket
+	}
+
+	total := model.ChartDataset{Key: stats.SERIES_TOTAL, Label: i18n.T(locale, "stats.total"), Data: []float64{}}
+	done := model.ChartDataset{Key: stats.SERIES_DONE, Label: i18n.T(locale, "stats.done"), Data: []float64{}}
+	cancelled := model.ChartDataset{Key: stats.SERIES_CANCELLED, Label: i18n.T(locale, "stats.cancelled"), Data: []float64{}}
+
+	labels := []string{}
+	for start := bucketStart(filter.From, interval); !start.After(filter.To); start = nextBucket(start, interval) {
+		label := start.Format(constants.FORMAT_DATE_DEFAULT)
+		bucket := counts[label]
+
+		labels = append(labels, label)
+		total.Data = append(total.Data, float64(bucket.Total))
+		done.Data = append(done.Data, float64(bucket.Done))
+		cancelled.Data = append(cancelled.Data, float64(bucket.Cancelled))
+	}
+
+	return model.ChartSeries{
+		Labels:   labels,
+		Datasets: []model.ChartDataset{total, done, cancelled},
+	}
+}
+
+func categorySeries(locale string, categories []model.LaundryCategoryAmount) model.ChartSeries {
+	items := model.ChartDataset{Key: stats.SERIES_ITEMS, Label: i18n.T(locale, "stats.items"), Data: []float64{}}
+
+	labels := []string{}
+	for _, category := range categories {
+		labels = append(labels, category.CategoryName)
+		items.Data = append(items.Data, float64(category.Amount))
+	}
+
+	return model.ChartSeries{
+		Labels:   labels,
+		Datasets: []model.ChartDataset{items},
+	}
+}
+
+// statusSeries has a label for every status in the order a routine goes through them
+func statusSeries(locale string, statuses []model.StatsStatus) model.ChartSeries {
+	counts := make(map[constants.LaundryStatus]int, len(statuses))
+	for _, status := range statuses {
+		counts[status.Status] = status.Total
+	}
+
+	routines := model.ChartDataset{Key: stats.SERIES_ROUTINES, Label: i18n.T(locale, "stats.routines"), Data: []float64{}}
+
+	labels := []string{}
+	for _, status := range constants.LaundryStatuses() {
+		labels = append(labels, model.LaundryStatusLabel(locale, status))
+		routines.Data = append(routines.Data, float64(counts[status]))
+	}
+
+	return model.ChartSeries{
+		Labels:   labels,
+		Datasets: []model.ChartDataset{routines},
+	}
+}
+
+// weekdaySeries counts the routines from Monday through Sunday and picks the busiest day, the earliest one on a tie
+func weekdaySeries(locale string, weekdays []model.StatsWeekday) (model.ChartSeries, *string) {
+	counts := make(map[int]int, len(weekdays))
+	for _, weekday := range weekdays {
+		counts[weekday.Weekday] = weekday.Total
+	}
+
+	routines := model.ChartDataset{Key: stats.SERIES_ROUTINES, Label: i18n.T(locale, "stats.routines"), Data: []float64{}}
+
+	var busiest *string
+	busiestTotal := 0
+
+	labels := []string{}
+	for isoWeekday := 1; isoWeekday <= 7; isoWeekday++ {
+		name := i18n.WeekdayName(locale, time.Weekday(isoWeekday%7))
+
+		labels = append(labels, name)
+		routines.Data = append(routines.Data, float64(counts[isoWeekday]))
+
+		if counts[isoWeekday] > busiestTotal {
+			busiest = &name
+			busiestTotal = counts[isoWeekday]
+		}
+	}
+
+	return model.ChartSeries{
+		Labels:   labels,
+		Datasets: []model.ChartDataset{routines},
+	}, busiest
+}
+
+// bucketStart is the first day of the week (Monday, like Postgres' date_trunc) or month of the date
+func bucketStart(date time.Time, interval stats.Interval) time.Time {
+	if interval == stats.INTERVAL_MONTH {
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+}
+
+func nextBucket(start time.Time, interval stats.Interval) time.Time {
+	if interval == stats.INTERVAL_MONTH {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
@@ -0,0 +1,10 @@
+package vendors
+
+const (
+	// units a vendor charges a category in
+	PRICE_UNIT_KG   = "kg"
+	PRICE_UNIT_ITEM = "item"
+
+	// SPEND_DEFAULT_DAYS is the range of the vendor spend when no start date is given
+	SPEND_DEFAULT_DAYS = 90
+)
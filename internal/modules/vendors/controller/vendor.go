@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors/service"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	VendorController interface {
+		GetVendorList(ctx *gin.Context)
+		GetVendor(ctx *gin.Context)
+		AddVendor(ctx *gin.Context)
+		UpdateVendor(ctx *gin.Context)
+		DeleteVendor(ctx *gin.Context)
+		LinkLaundry(ctx *gin.Context)
+		UnlinkLaundry(ctx *gin.Context)
+		GetOverduePickups(ctx *gin.Context)
+		GetVendorSpend(ctx *gin.Context)
+		GetVendorPage(ctx *gin.Context)
+		SubmitVendorForm(ctx *gin.Context)
+	}
+
+	VendorControllerImpl struct {
+		vendorService service.VendorService
+		flashStore    tools.FlashStore
+	}
+)
+
+func NewVendorController(vendorService service.VendorService, flashStore tools.FlashStore) VendorController {
+	return &VendorControllerImpl{
+		vendorService: vendorService,
+		flashStore:    flashStore,
+	}
+}
+
+func (v *VendorControllerImpl) GetVendorList(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := v.vendorService.GetVendorList(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (v *VendorControllerImpl) GetVendor(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := v.vendorService.GetVendor(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (v *VendorControllerImpl) AddVendor(ctx *gin.Context) {
+	var request model.VendorRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := v.vendorService.AddVendor(ctx, userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (v *VendorControllerImpl) UpdateVendor(ctx *gin.Context) {
+	var request model.VendorRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := v.vendorService.UpdateVendor(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (v *VendorControllerImpl) DeleteVendor(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := v.vendorService.DeleteVendor(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+func (v *VendorControllerImpl) LinkLaundry(ctx *gin.Context) {
+	var request model.LaundryVendorRequest
+	if err := errorutils.ValidatePayload(ctx.Request, &request); err != nil {
+		httputils.SetHttpResponse(ctx, nil, err, nil)
+		return
+	}
+
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := v.vendorService.LinkLaundry(ctx, ctx.Param("id"), userData.UserId, request)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+func (v *VendorControllerImpl) UnlinkLaundry(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	err := v.vendorService.UnlinkLaundry(ctx, ctx.Param("id"), userData.UserId)
+	httputils.SetHttpResponse(ctx, nil, err, nil)
+}
+
+func (v *VendorControllerImpl) GetOverduePickups(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+
+	result, err := v.vendorService.GetOverduePickups(ctx, userData.UserId)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// GetVendorSpend sums the spend per vendor from the "from" date through the "to" date (YYYY-MM-DD), by default the last 90 days
+func (v *VendorControllerImpl) GetVendorSpend(ctx *gin.Context) {
+	userData := ctx.MustGet(constants.USER_DATA).(model.UserClaims)
+	from, to := spendRange(ctx)
+
+	result, err := v.vendorService.GetVendorSpend(ctx, userData.UserId, from, to)
+	httputils.SetHttpResponse(ctx, result, err, nil)
+}
+
+// GetVendorPage lists the vendors with their price lists, the overdue pickups and the spend per vendor
+func (v *VendorControllerImpl) GetVendorPage(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		httputils.InvalidateCookie(ctx, constants.COOKIE_AUTH_TOKEN)
+		ctx.Redirect(http.StatusTemporaryRedirect, "/login")
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+	locale := i18n.LocaleFromContext(ctx)
+
+	dataHtml := gin.H{}
+
+	vendors, err := v.vendorService.GetVendorList(ctx, userData.UserId)
+	if err != nil {
+		_, message := errorutils.GetStatusCode(err)
+		dataHtml["error"] = i18n.TranslateError(locale, message)
+	}
+	dataHtml["vendors"] = vendors
+
+	overdue, err := v.vendorService.GetOverduePickups(ctx, userData.UserId)
+	if err != nil {
+		_, message := errorutils.GetStatusCode(err)
+		dataHtml["error"] = i18n.TranslateError(locale, message)
+	}
+	dataHtml["overdue"] = overdue
+
+	spend, err := v.vendorService.GetVendorSpend(ctx, userData.UserId, nil, nil)
+	if err != nil {
+		_, message := errorutils.GetStatusCode(err)
+		dataHtml["error"] = i18n.TranslateError(locale, message)
+	}
+	dataHtml["spend"] = spend
+
+	httputils.SetHtmlResponse(ctx, http.StatusOK, "vendors.html", dataHtml)
+}
+
+// SubmitVendorForm adds a vendor from the form of the vendor page, the price list is managed through the API
+func (v *VendorControllerImpl) SubmitVendorForm(ctx *gin.Context) {
+	userDataCtx, ok := ctx.Get(constants.USER_DATA)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userData := userDataCtx.(model.UserClaims)
+	locale := i18n.LocaleFromContext(ctx)
+
+	request := model.VendorRequest{
+		Name:    strings.TrimSpace(ctx.PostForm("name")),
+		Address: optionalFormValue(ctx, "address"),
+		Contact: optionalFormValue(ctx, "contact"),
+	}
+
+	err := errorutils.ValidateStruct(&request)
+	if err == nil {
+		_, err = v.vendorService.AddVendor(ctx, userData.UserId, request)
+	}
+
+	if err != nil {
+		_, message := errorutils.GetStatusCode(err)
+		v.flashStore.Add(ctx, tools.FLASH_LEVEL_ERROR, i18n.T(locale, "flash.vendor_add_failed", i18n.TranslateError(locale, message)))
+	} else {
+		v.flashStore.Add(ctx, tools.FLASH_LEVEL_SUCCESS, i18n.T(locale, "flash.vendor_added", request.Name))
+	}
+
+	ctx.Redirect(http.StatusSeeOther, "/vendors")
+}
+
+// spendRange reads the range of the vendor spend, the dates that can't be parsed are ignored
+func spendRange(ctx *gin.Context) (*time.Time, *time.Time) {
+	var from, to *time.Time
+	if fromStr := strings.TrimSpace(ctx.Query("from")); fromStr != "" {
+		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, fromStr)
+		if err == nil {
+			from = &timeObj
+		}
+	}
+
+	if toStr := strings.TrimSpace(ctx.Query("to")); toStr != "" {
+		timeObj, err := time.Parse(constants.FORMAT_DATE_DEFAULT, toStr)
+		if err == nil {
+			to = &timeObj
+		}
+	}
+
+	return from, to
+}
+
+func optionalFormValue(ctx *gin.Context, key string) *string {
+	value := strings.TrimSpace(ctx.PostForm(key))
+	if value == "" {
+		return nil
+	}
+	return &value
+}
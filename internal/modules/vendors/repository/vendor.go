@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Masterminds/squirrel"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/database"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/jmoiron/sqlx"
+	"strings"
+	"time"
+)
+
+const vendorColumns = "id, name, address, contact, created_at, updated_at"
+
+type (
+	VendorRepository interface {
+		// GetVendorList returns the active vendors of the user with their price lists
+		GetVendorList(ctx context.Context, userId string) ([]model.VendorResponse, error)
+		GetVendor(ctx context.Context, id, userId string) (*model.VendorResponse, error)
+		AddVendor(ctx context.Context, userId string, request model.VendorRequest) (string, error)
+		// UpdateVendor replaces the details and the whole price list of the vendor
+		UpdateVendor(ctx context.Context, id, userId string, request model.VendorRequest) error
+		// DeleteVendor deactivates the vendor, the routines sent to it keep their vendor and cost
+		DeleteVendor(ctx context.Context, id, userId string) error
+		// LinkLaundry stores the vendor details of a routine the user can change
+		LinkLaundry(ctx context.Context, laundryId, userId string, data model.LaundryVendor) error
+		UnlinkLaundry(ctx context.Context, laundryId, userId string) error
+		// GetLaundryVendor returns the vendor details of a routine, ErrorNotFound when it wasn't sent to a vendor
+		GetLaundryVendor(ctx context.Context, laundryId, userId string) (*model.LaundryVendorResponse, error)
+		// GetOverduePickups returns the routines of the user and their household whose promised pickup date has passed,
+		// the oldest first
+		GetOverduePickups(ctx context.Context, userId string) ([]model.LaundryVendorResponse, error)
+		// GetVendorSpend sums the cost of the routines dropped off from the from date through the to date per vendor
+		GetVendorSpend(ctx context.Context, userId string, from, to time.Time) ([]model.VendorSpendResponse, error)
+	}
+
+	VendorRepositoryImpl struct {
+		db database.DBCollection
+	}
+)
+
+func NewVendorRepository(db database.DBCollection) VendorRepository {
+	return &VendorRepositoryImpl{
+		db: db,
+	}
+}
+
+func (v *VendorRepositoryImpl) GetVendorList(ctx context.Context, userId string) ([]model.VendorResponse, error) {
+	result := []model.VendorResponse{}
+
+	query, args := squirrel.Select(vendorColumns).
+		From("vendors").
+		Where(squirrel.Eq{"user_id": userId, "is_active": true}).
+		OrderBy("name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := v.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting vendor list:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, len(result))
+	for i := range result {
+		ids[i] = result[i].Id
+	}
+
+	prices, err := v.getPrices(ctx, ids...)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range result {
+		result[i].Prices = prices[result[i].Id]
+	}
+
+	return result, nil
+}
+
+func (v *VendorRepositoryImpl) GetVendor(ctx context.Context, id, userId string) (*model.VendorResponse, error) {
+	query, args := squirrel.Select(vendorColumns).
+		From("vendors").
+		Where(squirrel.Eq{"id": id, "user_id": userId, "is_active": true}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.VendorResponse
+	if err := v.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting vendor:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	prices, err := v.getPrices(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result.Prices = prices[id]
+
+	return &result, nil
+}
+
+func (v *VendorRepositoryImpl) AddVendor(ctx context.Context, userId string, request model.VendorRequest) (string, error) {
+	currentTime := utils.TimeNow()
+	id := utils.GenerateCleanUUID()
+
+	err := database.WithTransaction(ctx, v.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Insert("vendors").
+			Columns("id", "user_id", "name", "address", "contact", "created_at", "updated_at").
+			Values(id, userId, strings.TrimSpace(request.Name), request.Address, request.Contact, currentTime, currentTime).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		return insertPrices(ctx, tx, id, request.Prices)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when adding vendor:", err)
+		return "", errorutils.DefineSQLError(err)
+	}
+
+	return id, nil
+}
+
+func (v *VendorRepositoryImpl) UpdateVendor(ctx context.Context, id, userId string, request model.VendorRequest) error {
+	err := database.WithTransaction(ctx, v.db.PostgresDBSqlx, func(tx *sqlx.Tx) error {
+		query, args := squirrel.Update("vendors").
+			Set("name", strings.TrimSpace(request.Name)).
+			Set("address", request.Address).
+			Set("contact", request.Contact).
+			Set("updated_at", utils.TimeNow()).
+			Where(squirrel.Eq{"id": id, "user_id": userId, "is_active": true}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		queryDelete, args := squirrel.Delete("vendor_prices").
+			Where(squirrel.Eq{"vendor_id": id}).
+			PlaceholderFormat(squirrel.Dollar).MustSql()
+
+		if _, err := tx.ExecContext(ctx, queryDelete, args...); err != nil {
+			return err
+		}
+
+		return insertPrices(ctx, tx, id, request.Prices)
+	})
+	if err != nil {
+		logging.WithContext(ctx).Error("error when updating vendor:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	return nil
+}
+
+func (v *VendorRepositoryImpl) DeleteVendor(ctx context.Context, id, userId string) error {
+	query, args := squirrel.Update("vendors").
+		Set("is_active", false).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": id, "user_id": userId, "is_active": true}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := v.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when deleting vendor:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (v *VendorRepositoryImpl) LinkLaundry(ctx context.Context, laundryId, userId string, data model.LaundryVendor) error {
+	query, args := squirrel.Update("laundries").
+		Set("vendor_id", data.VendorId).
+		Set("drop_off_date", data.DropOffDate.Format(constants.FORMAT_DATE_DEFAULT)).
+		Set("pickup_date", data.PickupDate.Format(constants.FORMAT_DATE_DEFAULT)).
+		Set("weight_kg", data.WeightKg).
+		Set("cost", data.Cost).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": laundryId}).
+		Where(database.AccessibleBy("", userId, true)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := v.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when linking laundry to vendor:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (v *VendorRepositoryImpl) UnlinkLaundry(ctx context.Context, laundryId, userId string) error {
+	query, args := squirrel.Update("laundries").
+		Set("vendor_id", nil).
+		Set("drop_off_date", nil).
+		Set("pickup_date", nil).
+		Set("weight_kg", nil).
+		Set("cost", nil).
+		Set("updated_at", utils.TimeNow()).
+		Where(squirrel.Eq{"id": laundryId}).
+		Where(database.AccessibleBy("", userId, true)).
+		Where(squirrel.NotEq{"vendor_id": nil}).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	res, err := v.db.PostgresDBSqlx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logging.WithContext(ctx).Error("error when unlinking laundry from vendor:", err)
+		return errorutils.DefineSQLError(err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errorutils.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (v *VendorRepositoryImpl) GetLaundryVendor(ctx context.Context, laundryId, userId string) (*model.LaundryVendorResponse, error) {
+	query, args := SelectLaundryVendors().
+		Where(squirrel.Eq{"l.id": laundryId}).
+		Where(database.AccessibleBy("l.", userId, false)).
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var result model.LaundryVendorResponse
+	if err := v.db.PostgresDBSqlx.GetContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting laundry vendor:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	return &result, nil
+}
+
+func (v *VendorRepositoryImpl) GetOverduePickups(ctx context.Context, userId string) ([]model.LaundryVendorResponse, error) {
+	result := []model.LaundryVendorResponse{}
+
+	query, args := squirrel.Select("*").
+		FromSelect(SelectLaundryVendors().Where(database.AccessibleBy("l.", userId, false)), "lv").
+		Where("lv.is_overdue").
+		OrderBy("lv.pickup_date", "lv.laundry_id").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := v.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting overdue pickups:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+func (v *VendorRepositoryImpl) GetVendorSpend(ctx context.Context, userId string, from, to time.Time) ([]model.VendorSpendResponse, error) {
+	result := []model.VendorSpendResponse{}
+
+	query, args := squirrel.Select("v.id AS vendor_id, v.name AS vendor_name, COUNT(*) AS routines").
+		Column("COALESCE(SUM(l.weight_kg), 0) AS weight_kg, COALESCE(SUM(l.cost), 0) AS total").
+		From("laundries l").
+		Join("vendors v ON v.id = l.vendor_id").
+		Where(database.AccessibleBy("l.", userId, false)).
+		Where(squirrel.NotEq{"l.status": constants.LAUNDRY_STATUS_CANCELLED}).
+		Where("l.drop_off_date BETWEEN ?::DATE AND ?::DATE", from.Format(constants.FORMAT_DATE_DEFAULT), to.Format(constants.FORMAT_DATE_DEFAULT)).
+		GroupBy("v.id", "v.name").
+		OrderBy("total DESC", "v.name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	if err := v.db.PostgresDBSqlx.SelectContext(ctx, &result, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting vendor spend:", err)
+		return result, errorutils.DefineSQLError(err)
+	}
+
+	return result, nil
+}
+
+// getPrices returns the price lists of the vendors by vendor id, every vendor gets a list even when it's empty
+func (v *VendorRepositoryImpl) getPrices(ctx context.Context, vendorIds ...string) (map[string][]model.VendorPriceResponse, error) {
+	query, args := squirrel.Select("vp.vendor_id, vp.category_id, c.name AS category_name, vp.unit, vp.price").
+		From("vendor_prices vp").
+		Join("categories c ON c.id = vp.category_id").
+		Where(squirrel.Eq{"vp.vendor_id": vendorIds}).
+		OrderBy("c.name").
+		PlaceholderFormat(squirrel.Dollar).MustSql()
+
+	var prices []model.VendorPriceResponse
+	if err := v.db.PostgresDBSqlx.SelectContext(ctx, &prices, query, args...); err != nil {
+		logging.WithContext(ctx).Error("error when getting vendor prices:", err)
+		return nil, errorutils.DefineSQLError(err)
+	}
+
+	result := make(map[string][]model.VendorPriceResponse, len(vendorIds))
+	for _, id := range vendorIds {
+		result[id] = []model.VendorPriceResponse{}
+	}
+	for _, price := range prices {
+		result[price.VendorId] = append(result[price.VendorId], price)
+	}
+
+	return result, nil
+}
+
+func insertPrices(ctx context.Context, tx sqlx.ExtContext, vendorId string, prices []model.VendorPriceRequest) error {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	query := squirrel.Insert("vendor_prices").
+		Columns("vendor_id", "category_id", "unit", "price")
+
+	for _, price := range prices {
+		query = query.Values(vendorId, price.CategoryId, price.Unit, price.Price)
+	}
+
+	queryInsert, args := query.PlaceholderFormat(squirrel.Dollar).MustSql()
+	_, err := tx.ExecContext(ctx, queryInsert, args...)
+	return err
+}
+
+// SelectLaundryVendors selects the routines sent to a vendor, the pickup is overdue once the promised date has passed
+// in the timezone of the owner while the routine is neither done nor cancelled
+func SelectLaundryVendors() squirrel.SelectBuilder {
+	return squirrel.Select("l.id AS laundry_id, l.title, v.id AS vendor_id, v.name AS vendor_name, v.contact AS vendor_contact").
+		Column("l.drop_off_date, l.pickup_date, l.weight_kg, COALESCE(l.cost, 0) AS cost").
+		Column(squirrel.Expr(
+			"(l.pickup_date < (NOW() AT TIME ZONE u.timezone)::DATE AND l.status NOT IN (?, ?)) AS is_overdue",
+			constants.LAUNDRY_STATUS_DONE, constants.LAUNDRY_STATUS_CANCELLED,
+		)).
+		From("laundries l").
+		Join("vendors v ON v.id = l.vendor_id").
+		Join("users u ON u.id = l.user_id")
+}
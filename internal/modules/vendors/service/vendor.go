@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	laundryRepository "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors/repository"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"math"
+	"time"
+)
+
+type (
+	VendorService interface {
+		GetVendorList(ctx context.Context, userId string) ([]model.VendorResponse, error)
+		GetVendor(ctx context.Context, id, userId string) (*model.VendorResponse, error)
+		AddVendor(ctx context.Context, userId string, request model.VendorRequest) (*model.VendorResponse, error)
+		UpdateVendor(ctx context.Context, id, userId string, request model.VendorRequest) (*model.VendorResponse, error)
+		DeleteVendor(ctx context.Context, id, userId string) error
+		// LinkLaundry sends a routine to a vendor, its cost is computed from the current price list of the vendor
+		LinkLaundry(ctx context.Context, laundryId, userId string, request model.LaundryVendorRequest) (*model.LaundryVendorResponse, error)
+		UnlinkLaundry(ctx context.Context, laundryId, userId string) error
+		GetOverduePickups(ctx context.Context, userId string) ([]model.LaundryVendorResponse, error)
+		// GetVendorSpend sums the cost per vendor of the routines dropped off between from and to,
+		// by default the last 90 days
+		GetVendorSpend(ctx context.Context, userId string, from, to *time.Time) ([]model.VendorSpendResponse, error)
+	}
+
+	VendorServiceImpl struct {
+		vendorRepository  repository.VendorRepository
+		laundryRepository laundryRepository.LaundryRepository
+	}
+)
+
+func NewVendorService(v repository.VendorRepository, l laundryRepository.LaundryRepository) VendorService {
+	return &VendorServiceImpl{
+		vendorRepository:  v,
+		laundryRepository: l,
+	}
+}
+
+func (v *VendorServiceImpl) GetVendorList(ctx context.Context, userId string) ([]model.VendorResponse, error) {
+	return v.vendorRepository.GetVendorList(ctx, userId)
+}
+
+func (v *VendorServiceImpl) GetVendor(ctx context.Context, id, userId string) (*model.VendorResponse, error) {
+	return v.vendorRepository.GetVendor(ctx, id, userId)
+}
+
+func (v *VendorServiceImpl) AddVendor(ctx context.Context, userId string, request model.VendorRequest) (*model.VendorResponse, error) {
+	if err := v.validatePrices(ctx, userId, request.Prices); err != nil {
+		return nil, err
+	}
+
+	id, err := v.vendorRepository.AddVendor(ctx, userId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.vendorRepository.GetVendor(ctx, id, userId)
+}
+
+func (v *VendorServiceImpl) UpdateVendor(ctx context.Context, id, userId string, request model.VendorRequest) (*model.VendorResponse, error) {
+	if err := v.validatePrices(ctx, userId, request.Prices); err != nil {
+		return nil, err
+	}
+
+	if err := v.vendorRepository.UpdateVendor(ctx, id, userId, request); err != nil {
+		return nil, err
+	}
+
+	return v.vendorRepository.GetVendor(ctx, id, userId)
+}
+
+func (v *VendorServiceImpl) DeleteVendor(ctx context.Context, id, userId string) error {
+	return v.vendorRepository.DeleteVendor(ctx, id, userId)
+}
+
+func (v *VendorServiceImpl) LinkLaundry(ctx context.Context, laundryId, userId string, request model.LaundryVendorRequest) (*model.LaundryVendorResponse, error) {
+	dropOffDate, err := time.Parse(constants.FORMAT_DATE_DEFAULT, request.DropOffDate)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid drop_off_date")
+	}
+
+	pickupDate, err := time.Parse(constants.FORMAT_DATE_DEFAULT, request.PickupDate)
+	if err != nil {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid pickup_date")
+	}
+
+	if pickupDate.Before(dropOffDate) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("pickup_date must not be before drop_off_date")
+	}
+
+	laundryData, err := v.laundryRepository.GetLaundryDetail(ctx, laundryId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	vendorData, err := v.vendorRepository.GetVendor(ctx, request.VendorId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	cost, err := laundryCost(laundryData.Items, vendorData.Prices, request.WeightKg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.vendorRepository.LinkLaundry(ctx, laundryId, userId, model.LaundryVendor{
+		VendorId:    vendorData.Id,
+		DropOffDate: dropOffDate,
+		PickupDate:  pickupDate,
+		WeightKg:    request.WeightKg,
+		Cost:        cost,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := v.vendorRepository.GetLaundryVendor(ctx, laundryId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result.FillDisplayFields(i18n.LocaleFromContext(ctx))
+
+	return result, nil
+}
+
+func (v *VendorServiceImpl) UnlinkLaundry(ctx context.Context, laundryId, userId string) error {
+	return v.vendorRepository.UnlinkLaundry(ctx, laundryId, userId)
+}
+
+func (v *VendorServiceImpl) GetOverduePickups(ctx context.Context, userId string) ([]model.LaundryVendorResponse, error) {
+	result, err := v.vendorRepository.GetOverduePickups(ctx, userId)
+	if err != nil {
+		return result, err
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+	for i := range result {
+		result[i].FillDisplayFields(locale)
+	}
+
+	return result, nil
+}
+
+func (v *VendorServiceImpl) GetVendorSpend(ctx context.Context, userId string, from, to *time.Time) ([]model.VendorSpendResponse, error) {
+	now := utils.TimeNow()
+	rangeTo := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if to != nil {
+		rangeTo = *to
+	}
+
+	rangeFrom := rangeTo.AddDate(0, 0, 1-vendors.SPEND_DEFAULT_DAYS)
+	if from != nil {
+		rangeFrom = *from
+	}
+
+	if rangeFrom.After(rangeTo) {
+		return nil, errorutils.ErrorBadRequest.CustomMessage("invalid date range")
+	}
+
+	return v.vendorRepository.GetVendorSpend(ctx, userId, rangeFrom, rangeTo)
+}
+
+// validatePrices checks that the price list names every category of the user at most once
+func (v *VendorServiceImpl) validatePrices(ctx context.Context, userId string, prices []model.VendorPriceRequest) error {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	categoryIds := make([]string, 0, len(prices))
+	seen := map[string]bool{}
+	for _, price := range prices {
+		if seen[price.CategoryId] {
+			return errorutils.ErrorBadRequest.CustomMessage("a category can only be priced once")
+		}
+		seen[price.CategoryId] = true
+		categoryIds = append(categoryIds, price.CategoryId)
+	}
+
+	categoriesData, err := v.laundryRepository.GetCategoryById(ctx, userId, categoryIds...)
+	if err != nil || len(categoriesData) != len(categoryIds) {
+		logging.WithContext(ctx).Error("invalid categories detected")
+		return errorutils.ErrorBadRequest.CustomMessage("invalid category id")
+	}
+
+	return nil
+}
+
+// laundryCost prices the items of a routine. Items priced per item cost their amount times the price, the weight of
+// the routine is shared by the items priced per kg in proportion to their amounts. Items of categories the vendor
+// doesn't price are free.
+func laundryCost(items []model.LaundryItemResponse, prices []model.VendorPriceResponse, weightKg *float64) (float64, error) {
+	priceByCategory := make(map[string]model.VendorPriceResponse, len(prices))
+	for _, price := range prices {
+		priceByCategory[price.CategoryId] = price
+	}
+
+	cost := 0.0
+	kgAmount, kgPrice := 0, 0.0
+	for _, item := range items {
+		price, ok := priceByCategory[item.CategoryId]
+		if !ok {
+			continue
+		}
+
+		switch price.Unit {
+		case vendors.PRICE_UNIT_ITEM:
+			cost += float64(item.Amount) * price.Price
+		case vendors.PRICE_UNIT_KG:
+			kgAmount += item.Amount
+			kgPrice += float64(item.Amount) * price.Price
+		}
+	}
+
+	if kgAmount > 0 {
+		if weightKg == nil {
+			return 0, errorutils.ErrorBadRequest.CustomMessage("weight_kg is required by the per kg prices of the vendor")
+		}
+		cost += *weightKg * kgPrice / float64(kgAmount)
+	}
+
+	return math.Round(cost*100) / 100, nil
+}
@@ -5,29 +5,51 @@ import (
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/middleware"
 	authController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/auth/controller"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar"
+	calendarController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/calendar/controller"
+	campaignController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/campaign/controller"
+	choreController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/chore/controller"
+	householdController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/household/controller"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/laundry/controller"
+	notificationController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/notification/controller"
+	preferenceController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/preference/controller"
+	reminderController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/reminder/controller"
+	seriesController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/series/controller"
+	statsController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/stats/controller"
+	vendorController "github.com/audricimanuel/laundry-routine-tracking-service/internal/modules/vendors/controller"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/tools"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/view"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"html/template"
 	"net/http"
-	"os"
 )
 
 func RegisterRouter(
 	cfg config.Config,
 	// additional middlewares
+	mid middleware.GoMiddleware,
 	authMiddleware middleware.AuthMiddleware,
 	// register new controllers here
 	authController authController.AuthController,
 	laundryController controller.LaundryController,
+	campaignController campaignController.CampaignController,
+	preferenceController preferenceController.PreferenceController,
+	reminderController reminderController.ReminderController,
+	notificationController notificationController.NotificationController,
+	seriesController seriesController.SeriesController,
+	calendarController calendarController.CalendarController,
+	statsController statsController.StatsController,
+	vendorController vendorController.VendorController,
+	householdController householdController.HouseholdController,
+	choreController choreController.ChoreController,
 ) *gin.Engine {
 	r := gin.Default()
 
-	setHTMLTemplate(r)
-
-	mid := middleware.InitMiddleware(cfg)
+	setHTMLTemplate(cfg, r)
 
 	setMiddlewareGlobal(cfg, mid, r)
 
@@ -43,14 +65,52 @@ func RegisterRouter(
 	})
 
 	// route of FE
-	viewApi := r.Group("")
+	viewApi := r.Group("", mid.ViewContext(), mid.CSRFProtect())
 	{
 		// /login
 		viewApi.GET("/login", authMiddleware.ValidateGetLoginPage(), authController.GetLoginPage)
+		viewApi.POST("/login", authController.SubmitLoginForm)
+
+		// /verify-email
+		viewApi.GET("/verify-email", authMiddleware.ValidateJWTFromCookie(), authController.GetVerifyEmailPage)
+		viewApi.POST("/verify-email", authMiddleware.ValidateJWTFromCookie(), authController.SubmitVerifyEmailForm)
 
 		viewApi.GET("/", authMiddleware.ValidateJWTFromCookie(), laundryController.GetLaundryList)
+
+		// /stats?from=&to= (charts of the routines)
+		viewApi.GET("/stats", authMiddleware.ValidateJWTFromCookie(), statsController.GetStatsPage)
+
+		// /settings (calendar subscription)
+		viewApi.GET("/settings", authMiddleware.ValidateJWTFromCookie(), calendarController.GetSettingsPage)
+		viewApi.POST("/settings/calendar/regenerate", authMiddleware.ValidateJWTFromCookie(), calendarController.SubmitRegenerateFeedForm)
+
+		// /vendors (laundry shops)
+		viewApi.GET("/vendors", authMiddleware.ValidateJWTFromCookie(), vendorController.GetVendorPage)
+		viewApi.POST("/vendors", authMiddleware.ValidateJWTFromCookie(), vendorController.SubmitVendorForm)
+
+		// /household/invitations/accept?token= (the link of the invitation email)
+		viewApi.GET(tools.INVITATION_PATH, authMiddleware.ValidateJWTFromCookie(), householdController.GetInvitationPage)
+		viewApi.POST(tools.INVITATION_PATH, authMiddleware.ValidateJWTFromCookie(), householdController.SubmitInvitationForm)
+
+		// /laundry/:id/details (HTML fragment of the routine detail modal)
+		viewApi.GET("/laundry/:id/details", authMiddleware.ValidateJWTFromCookie(), laundryController.GetLaundryDetail)
+		viewApi.POST("/laundry/:id/status", authMiddleware.ValidateJWTFromCookie(), laundryController.UpdateLaundryStatus)
+	}
+
+	// /unsubscribe is opened from emails and posted to by mail clients, it's authorized by its signed token instead of CSRF
+	unsubscribeApi := r.Group(tools.UNSUBSCRIBE_PATH, mid.ViewContext())
+	{
+		unsubscribeApi.GET("", preferenceController.GetUnsubscribePage)
+		unsubscribeApi.POST("", preferenceController.Unsubscribe)
 	}
 
+	// campaign open and click tracking, authorized by their signed token
+	r.GET(tools.TRACKING_OPEN_PATH+":token", campaignController.TrackOpen)
+	r.GET(tools.TRACKING_CLICK_PATH+":token", campaignController.TrackClick)
+
+	// iCal subscription, authorized by the secret token of its URL (/calendar/:token.ics)
+	r.GET(calendar.FEED_PATH+":file", calendarController.ExportFeed)
+
 	api := r.Group("/api")
 	{
 		// /api/v1/auth
@@ -66,12 +126,177 @@ func RegisterRouter(
 			authApi.POST("/forgot-password", authController.ForgotPassword)
 			// /api/v1/auth/refresh
 			authApi.POST("/refresh", authMiddleware.RefreshJWT())
+			// /api/v1/auth/locale
+			authApi.POST("/locale", authMiddleware.ValidateJWT(), authController.UpdateLocale)
 		}
 
 		// /api/v1/laundry
 		laundryApi := api.Group("/v1/laundry")
 		{
 			laundryApi.POST("/", authMiddleware.ValidateJWT(), laundryController.AddLaundry)
+			// /api/v1/laundry/export?format=csv|xlsx
+			laundryApi.GET("/export", authMiddleware.ValidateJWT(), laundryController.ExportLaundries)
+			// /api/v1/laundry/from-template/:id
+			laundryApi.POST("/from-template/:id", authMiddleware.ValidateJWT(), laundryController.AddLaundryFromTemplate)
+			// /api/v1/laundry/:id/clone
+			laundryApi.POST("/:id/clone", authMiddleware.ValidateJWT(), laundryController.CloneLaundry)
+
+			// /api/v1/laundry/import/ics (multipart form with the .ics file)
+			laundryApi.POST("/import/ics/preview", authMiddleware.ValidateJWT(), laundryController.PreviewICSImport)
+			laundryApi.POST("/import/ics", authMiddleware.ValidateJWT(), laundryController.ImportICS)
+			// /api/v1/laundry/import/csv (multipart form with the .csv file and the column mapping, dry_run=true to validate only)
+			laundryApi.POST("/import/csv", authMiddleware.ValidateJWT(), laundryController.ImportCSV)
+
+			// /api/v1/laundry/:id/reminders
+			laundryApi.GET("/:id/reminders", authMiddleware.ValidateJWT(), reminderController.GetReminderList)
+			laundryApi.POST("/:id/reminders", authMiddleware.ValidateJWT(), reminderController.AddReminder)
+
+			// /api/v1/laundry/missing-items?include_resolved= (items that came back short)
+			laundryApi.GET("/missing-items", authMiddleware.ValidateJWT(), laundryController.GetMissingItems)
+			// /api/v1/laundry/:id/returns (returned amount of every item)
+			laundryApi.PUT("/:id/returns", authMiddleware.ValidateJWT(), laundryController.RecordReturns)
+			// /api/v1/laundry/:id/resolution (note explaining the discrepancy)
+			laundryApi.PUT("/:id/resolution", authMiddleware.ValidateJWT(), laundryController.ResolveDiscrepancy)
+
+			// /api/v1/laundry/:id/vendor (send the routine to a laundry shop)
+			laundryApi.PUT("/:id/vendor", authMiddleware.ValidateJWT(), vendorController.LinkLaundry)
+			laundryApi.DELETE("/:id/vendor", authMiddleware.ValidateJWT(), vendorController.UnlinkLaundry)
+
+			// /api/v1/laundry/:id/assignments (whose turn it is to do the routine or one of its steps)
+			laundryApi.PUT("/:id/assignments", authMiddleware.ValidateJWT(), choreController.Assign)
+			laundryApi.DELETE("/:id/assignments/:step", authMiddleware.ValidateJWT(), choreController.Unassign)
+
+			// /api/v1/laundry/templates
+			templateApi := laundryApi.Group("/templates", authMiddleware.ValidateJWT())
+			{
+				templateApi.GET("", laundryController.GetTemplateList)
+				templateApi.POST("", laundryController.AddTemplate)
+				templateApi.GET("/:id", laundryController.GetTemplate)
+				templateApi.PUT("/:id", laundryController.UpdateTemplate)
+				templateApi.DELETE("/:id", laundryController.DeleteTemplate)
+			}
+
+			// /api/v1/laundry/series (recurring routines)
+			seriesApi := laundryApi.Group("/series", authMiddleware.ValidateJWT())
+			{
+				seriesApi.GET("", seriesController.GetSeriesList)
+				seriesApi.POST("", seriesController.AddSeries)
+				seriesApi.GET("/:id", seriesController.GetSeries)
+				seriesApi.PUT("/:id", seriesController.UpdateSeries)
+				seriesApi.DELETE("/:id", seriesController.EndSeries)
+				// /api/v1/laundry/series/:id/occurrences
+				seriesApi.GET("/:id/occurrences", seriesController.GetOccurrenceList)
+				seriesApi.PUT("/:id/occurrences", seriesController.UpdateOccurrence)
+				// /api/v1/laundry/series/:id/occurrences/skip
+				seriesApi.POST("/:id/occurrences/skip", seriesController.SkipOccurrence)
+				// /api/v1/laundry/series/:id/rotation (the members the generated routines go to in turn)
+				seriesApi.GET("/:id/rotation", choreController.GetRotation)
+				seriesApi.PUT("/:id/rotation", choreController.SetRotation)
+				seriesApi.DELETE("/:id/rotation", choreController.DeleteRotation)
+			}
+		}
+
+		// /api/v1/vendors (laundry shops)
+		vendorApi := api.Group("/v1/vendors", authMiddleware.ValidateJWT())
+		{
+			vendorApi.GET("", vendorController.GetVendorList)
+			vendorApi.POST("", vendorController.AddVendor)
+			// /api/v1/vendors/overdue-pickups
+			vendorApi.GET("/overdue-pickups", vendorController.GetOverduePickups)
+			// /api/v1/vendors/spend?from=&to=
+			vendorApi.GET("/spend", vendorController.GetVendorSpend)
+			vendorApi.GET("/:id", vendorController.GetVendor)
+			vendorApi.PUT("/:id", vendorController.UpdateVendor)
+			vendorApi.DELETE("/:id", vendorController.DeleteVendor)
+		}
+
+		// /api/v1/household (the household of the user, shared with its members)
+		householdApi := api.Group("/v1/household", authMiddleware.ValidateJWT())
+		{
+			householdApi.GET("", householdController.GetHousehold)
+			householdApi.POST("", householdController.CreateHousehold)
+			householdApi.PUT("", householdController.UpdateHousehold)
+			// /api/v1/household/invitations
+			householdApi.POST("/invitations", householdController.InviteMember)
+			householdApi.DELETE("/invitations/:id", householdController.RevokeInvitation)
+			// /api/v1/household/invitations/accept (the token of the invitation link)
+			householdApi.POST("/invitations/accept", householdController.AcceptInvitation)
+			// /api/v1/household/members/:userId (removing oneself leaves the household)
+			householdApi.PUT("/members/:userId", householdController.UpdateMemberRole)
+			householdApi.DELETE("/members/:userId", householdController.RemoveMember)
+			// /api/v1/household/workload?from=&to= (turns of every member)
+			householdApi.GET("/workload", choreController.GetWorkload)
+			// /api/v1/household/swaps (requests to swap a turn with another member)
+			householdApi.GET("/swaps", choreController.GetSwapList)
+			householdApi.POST("/swaps", choreController.RequestSwap)
+			householdApi.POST("/swaps/:id/accept", choreController.AcceptSwap)
+			householdApi.POST("/swaps/:id/decline", choreController.DeclineSwap)
+			householdApi.DELETE("/swaps/:id", choreController.CancelSwap)
+		}
+
+		// /api/v1/reminders
+		reminderApi := api.Group("/v1/reminders", authMiddleware.ValidateJWT())
+		{
+			// /api/v1/reminders/:id/cancel
+			reminderApi.POST("/:id/cancel", reminderController.CancelReminder)
+		}
+
+		// /api/v1/notifications
+		notificationApi := api.Group("/v1/notifications", authMiddleware.ValidateJWT())
+		{
+			notificationApi.GET("", notificationController.GetNotificationList)
+			// /api/v1/notifications/unread-count
+			notificationApi.GET("/unread-count", notificationController.GetUnreadCount)
+			// /api/v1/notifications/read-all
+			notificationApi.POST("/read-all", notificationController.MarkAllAsRead)
+			// /api/v1/notifications/:id/read
+			notificationApi.POST("/:id/read", notificationController.MarkAsRead)
+		}
+
+		// /api/v1/calendar
+		calendarApi := api.Group("/v1/calendar", authMiddleware.ValidateJWT())
+		{
+			// /api/v1/calendar/feed (the iCal subscription URL of the user, created on first use)
+			calendarApi.GET("/feed", calendarController.GetFeed)
+			// /api/v1/calendar/feed/regenerate (replaces the URL, the previous one stops working)
+			calendarApi.POST("/feed/regenerate", calendarController.RegenerateFeed)
+		}
+
+		// /api/v1/stats?from=&to=&interval=week|month
+		api.GET("/v1/stats", authMiddleware.ValidateJWT(), statsController.GetStats)
+
+		// /api/v1/preferences
+		preferenceApi := api.Group("/v1/preferences", authMiddleware.ValidateJWT())
+		{
+			// /api/v1/preferences/email
+			preferenceApi.GET("/email", preferenceController.GetEmailPreferences)
+			preferenceApi.PUT("/email", preferenceController.UpdateEmailPreferences)
+
+			// /api/v1/preferences/timezone
+			preferenceApi.PUT("/timezone", preferenceController.UpdateTimezone)
+		}
+
+		// /api/v1/admin
+		adminApi := api.Group("/v1/admin", authMiddleware.ValidateJWT(), authMiddleware.RequireRole(constants.ROLE_ADMIN, constants.ROLE_SUPER_ADMIN))
+		{
+			// /api/v1/admin/campaigns
+			campaignApi := adminApi.Group("/campaigns")
+			{
+				campaignApi.GET("", campaignController.GetCampaignList)
+				campaignApi.POST("", campaignController.AddCampaign)
+				campaignApi.GET("/:id", campaignController.GetCampaign)
+				campaignApi.PUT("/:id", campaignController.UpdateCampaign)
+				// /api/v1/admin/campaigns/:id/preview
+				campaignApi.POST("/:id/preview", campaignController.PreviewCampaign)
+				// /api/v1/admin/campaigns/:id/schedule
+				campaignApi.POST("/:id/schedule", campaignController.ScheduleCampaign)
+				// /api/v1/admin/campaigns/:id/cancel
+				campaignApi.POST("/:id/cancel", campaignController.CancelCampaign)
+				// /api/v1/admin/campaigns/:id/recipients
+				campaignApi.GET("/:id/recipients", campaignController.GetRecipientList)
+				// /api/v1/admin/campaigns/:id/stats
+				campaignApi.GET("/:id/stats", campaignController.GetCampaignStats)
+			}
 		}
 	}
 
@@ -82,6 +307,9 @@ func setMiddlewareGlobal(cfg config.Config, mid middleware.GoMiddleware, r *gin.
 	// Logger
 	r.Use(mid.LogRequest())
 
+	// Locale negotiation
+	r.Use(mid.Localize())
+
 	// Cors
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{cfg.Host.FEBaseUrl},
@@ -95,14 +323,19 @@ func setMiddlewareGlobal(cfg config.Config, mid middleware.GoMiddleware, r *gin.
 	r.Use(mid.RecoverPanic())
 }
 
-func setHTMLTemplate(r *gin.Engine) {
-	var templates *template.Template
+func setHTMLTemplate(cfg config.Config, r *gin.Engine) {
+	assets, err := view.NewAssets(cfg.ViewDevMode)
+	if err != nil {
+		logrus.Fatal("error when loading static assets: ", err)
+	}
 
-	r.Static("/static/", "./view/static")
+	renderer, err := view.NewRenderer(cfg.ViewDevMode, assets)
+	if err != nil {
+		logrus.Fatal("error when parsing templates: ", err)
+	}
 
-	dir, _ := os.Getwd()
-	fmt.Println("Current working directory:", dir)
-	templates = template.Must(template.ParseGlob(dir + "/view/templates/*.html"))
+	r.GET(view.ASSET_URL_PREFIX+"*filepath", assets.Handler())
+	r.HEAD(view.ASSET_URL_PREFIX+"*filepath", assets.Handler())
 
-	r.SetHTMLTemplate(templates)
+	r.HTMLRender = renderer
 }
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/httputils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type (
+	// FlashStore keeps one-time messages in a signed cookie so they survive a redirect
+	FlashStore interface {
+		// Add queues a message to be shown on the next rendered page
+		Add(ctx *gin.Context, level, message string)
+		// Pop reads and clears the messages queued by the previous request
+		Pop(ctx *gin.Context) []httputils.FlashMessage
+	}
+
+	FlashStoreImpl struct {
+		secret []byte
+	}
+)
+
+const (
+	FLASH_LEVEL_SUCCESS = "success"
+	FLASH_LEVEL_WARNING = "warning"
+	FLASH_LEVEL_ERROR   = "error"
+
+	// flashPendingKey holds the messages added during the current request
+	flashPendingKey = "flash-pending"
+	flashMaxAge     = 60
+)
+
+func NewFlashStore(cfg config.Config) FlashStore {
+	secret := cfg.CookieSecret
+	if secret == "" {
+		secret = cfg.JWTSecret
+	}
+
+	return &FlashStoreImpl{
+		secret: []byte(secret),
+	}
+}
+
+func (f *FlashStoreImpl) Add(ctx *gin.Context, level, message string) {
+	var pending []httputils.FlashMessage
+	if value, ok := ctx.Get(flashPendingKey); ok {
+		pending = value.([]httputils.FlashMessage)
+	}
+
+	pending = append(pending, httputils.FlashMessage{Level: level, Message: message})
+	ctx.Set(flashPendingKey, pending)
+
+	payload, _ := json.Marshal(pending)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     constants.COOKIE_FLASH,
+		Value:    encoded + "." + f.sign(encoded),
+		Path:     "/",
+		MaxAge:   flashMaxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (f *FlashStoreImpl) Pop(ctx *gin.Context) []httputils.FlashMessage {
+	cookie, err := ctx.Cookie(constants.COOKIE_FLASH)
+	if err != nil || cookie == "" {
+		return nil
+	}
+
+	httputils.InvalidateCookie(ctx, constants.COOKIE_FLASH)
+
+	encoded, signature, ok := strings.Cut(cookie, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(f.sign(encoded))) {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+
+	var result []httputils.FlashMessage
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return nil
+	}
+
+	return result
+}
+
+func (f *FlashStoreImpl) sign(value string) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
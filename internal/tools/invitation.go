@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"net/url"
+	"strings"
+)
+
+const (
+	// INVITATION_PATH is the page the household invitation links point to
+	INVITATION_PATH = "/household/invitations/accept"
+
+	// invitationKeyPrefix keeps the signatures apart from the other values signed with the same secret
+	invitationKeyPrefix = "invitation:"
+)
+
+var ErrInvalidInvitationToken = errors.New("invalid invitation token")
+
+type (
+	// InvitationTokens signs the id of a household invitation, so the link sent by email can't be forged
+	// for another invitation. Whether the invitation is still pending is checked against the database.
+	InvitationTokens interface {
+		Sign(invitationId string) string
+		Verify(token string) (invitationId string, err error)
+		// URL is the absolute link accepting the invitation
+		URL(invitationId string) string
+	}
+
+	InvitationTokensImpl struct {
+		secret  []byte
+		baseUrl string
+	}
+)
+
+func NewInvitationTokens(cfg config.Config) InvitationTokens {
+	secret := cfg.CookieSecret
+	if secret == "" {
+		secret = cfg.JWTSecret
+	}
+
+	return &InvitationTokensImpl{
+		secret:  []byte(invitationKeyPrefix + secret),
+		baseUrl: strings.TrimSuffix(cfg.Host.BaseUrl, "/"),
+	}
+}
+
+func (i *InvitationTokensImpl) Sign(invitationId string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(invitationId))
+	return payload + "." + i.sign(payload)
+}
+
+func (i *InvitationTokensImpl) Verify(token string) (string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(i.sign(payload))) {
+		return "", ErrInvalidInvitationToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(decoded) == 0 {
+		return "", ErrInvalidInvitationToken
+	}
+
+	return string(decoded), nil
+}
+
+func (i *InvitationTokensImpl) URL(invitationId string) string {
+	return i.baseUrl + INVITATION_PATH + "?token=" + url.QueryEscape(i.Sign(invitationId))
+}
+
+func (i *InvitationTokensImpl) sign(value string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"errors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestInvitationTokens(cookieSecret, jwtSecret string) InvitationTokens {
+	return NewInvitationTokens(config.Config{
+		CookieSecret: cookieSecret,
+		JWTSecret:    jwtSecret,
+		Host:         config.Host{BaseUrl: "https://laundry.example.com/"},
+	})
+}
+
+func TestInvitationTokensVerify(t *testing.T) {
+	tokens := newTestInvitationTokens("cookie-secret", "jwt-secret")
+	valid := tokens.Sign("invitation-1")
+	payload, signature, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", token: valid, want: "invitation-1"},
+		{name: "empty", token: "", wantErr: ErrInvalidInvitationToken},
+		{name: "no signature", token: payload, wantErr: ErrInvalidInvitationToken},
+		{name: "tampered payload", token: tokens.Sign("invitation-2")[:len(payload)] + "." + signature, wantErr: ErrInvalidInvitationToken},
+		{name: "tampered signature", token: payload + "." + strings.ToUpper(signature), wantErr: ErrInvalidInvitationToken},
+		{name: "other secret", token: newTestInvitationTokens("other-secret", "jwt-secret").Sign("invitation-1"), wantErr: ErrInvalidInvitationToken},
+		{name: "empty id", token: tokens.Sign(""), wantErr: ErrInvalidInvitationToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokens.Verify(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvitationTokensSecret(t *testing.T) {
+	tests := []struct {
+		name         string
+		signer       InvitationTokens
+		verifier     InvitationTokens
+		wantVerified bool
+	}{
+		{
+			name:         "same cookie secret",
+			signer:       newTestInvitationTokens("cookie-secret", "jwt-secret"),
+			verifier:     newTestInvitationTokens("cookie-secret", "other-jwt-secret"),
+			wantVerified: true,
+		},
+		{
+			name:         "falls back to the jwt secret",
+			signer:       newTestInvitationTokens("", "jwt-secret"),
+			verifier:     newTestInvitationTokens("", "jwt-secret"),
+			wantVerified: true,
+		},
+		{
+			name:     "different jwt secret",
+			signer:   newTestInvitationTokens("", "jwt-secret"),
+			verifier: newTestInvitationTokens("", "other-jwt-secret"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.verifier.Verify(tt.signer.Sign("invitation-1"))
+			if verified := err == nil; verified != tt.wantVerified {
+				t.Errorf("verified = %t, want %t", verified, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestInvitationTokensURL(t *testing.T) {
+	tokens := newTestInvitationTokens("cookie-secret", "jwt-secret")
+
+	link, err := url.Parse(tokens.URL("invitation-1"))
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+
+	if got := link.Scheme + "://" + link.Host + link.Path; got != "https://laundry.example.com"+INVITATION_PATH {
+		t.Errorf("link = %q", got)
+	}
+
+	got, err := tokens.Verify(link.Query().Get("token"))
+	if err != nil || got != "invitation-1" {
+		t.Errorf("Verify(token) = %q, %v", got, err)
+	}
+}
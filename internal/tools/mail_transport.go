@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MAIL_TRANSPORT_SMTP    = "smtp"
+	MAIL_TRANSPORT_FILE    = "file"
+	MAIL_TRANSPORT_MAILDIR = "maildir"
+	MAIL_TRANSPORT_LOG     = "log"
+	MAIL_TRANSPORT_MEMORY  = "memory"
+
+	DEFAULT_MAIL_FILE_DIR = "tmp/mail"
+
+	smtpDialTimeout = 30 * time.Second
+	// DEFAULT_SMTP_TIMEOUT_SECONDS bounds a delivery when SMTP_TIMEOUT isn't set
+	DEFAULT_SMTP_TIMEOUT_SECONDS = 60
+)
+
+type (
+	// MailTransport hands a fully built email over to wherever it's delivered
+	MailTransport interface {
+		Deliver(ctx context.Context, from string, recipients []string, raw []byte) error
+	}
+
+	// SMTPTransport delivers through an SMTP server. With UseTLS the connection is TLS from the start
+	// (implicit TLS, usually port 465), otherwise it's upgraded with STARTTLS when the server offers it.
+	// Timeout bounds the whole delivery, DEFAULT_SMTP_TIMEOUT_SECONDS when it's not set.
+	SMTPTransport struct {
+		Host     string
+		Port     string
+		User     string
+		Password string
+		UseTLS   bool
+		Timeout  time.Duration
+	}
+
+	// FileTransport writes every email as an .eml file into Dir
+	FileTransport struct {
+		Dir string
+	}
+
+	// MaildirTransport delivers into the "new" folder of the maildir at Dir, so it can be opened by a mail client
+	MaildirTransport struct {
+		Dir string
+	}
+
+	// LogTransport only logs the emails, including their content
+	LogTransport struct{}
+
+	// MemoryTransport keeps the emails in memory so they can be asserted against
+	MemoryTransport struct {
+		mu       sync.Mutex
+		messages []SentEmail
+	}
+
+	SentEmail struct {
+		From       string
+		Recipients []string
+		Raw        []byte
+	}
+)
+
+// NewMailTransport returns the transport selected by MAIL_TRANSPORT, defaulting to SMTP
+func NewMailTransport(transport string, smtpTransport SMTPTransport, fileDir string) (MailTransport, error) {
+	if fileDir == "" {
+		fileDir = DEFAULT_MAIL_FILE_DIR
+	}
+
+	switch strings.ToLower(transport) {
+	case "", MAIL_TRANSPORT_SMTP:
+		return &smtpTransport, nil
+	case MAIL_TRANSPORT_FILE:
+		return &FileTransport{Dir: fileDir}, nil
+	case MAIL_TRANSPORT_MAILDIR:
+		return &MaildirTransport{Dir: fileDir}, nil
+	case MAIL_TRANSPORT_LOG:
+		return &LogTransport{}, nil
+	case MAIL_TRANSPORT_MEMORY:
+		return &MemoryTransport{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown mail transport %q", transport)
+}
+
+func (s *SMTPTransport) Deliver(ctx context.Context, from string, recipients []string, raw []byte) error {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DEFAULT_SMTP_TIMEOUT_SECONDS * time.Second
+	}
+
+	// a server that stops responding must not hold the worker, the deadline applies to every command
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	address := net.JoinHostPort(s.Host, s.Port)
+	tlsConfig := &tls.Config{ServerName: s.Host}
+
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+
+	if s.UseTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !s.UseTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.User != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", s.User, s.Password, s.Host)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(raw); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (f *FileTransport) Deliver(ctx context.Context, from string, recipients []string, raw []byte) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(f.Dir, uniqueMailName()+".eml")
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return err
+	}
+
+	logging.WithContext(ctx).Infof("email to %s written to %s", strings.Join(recipients, ", "), path)
+	return nil
+}
+
+func (m *MaildirTransport) Deliver(ctx context.Context, from string, recipients []string, raw []byte) error {
+	for _, dir := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(m.Dir, dir), 0o755); err != nil {
+			return err
+		}
+	}
+
+	// maildir delivery: write into tmp first, then move into new so readers never see a partial file
+	name := uniqueMailName()
+	tmpPath := filepath.Join(m.Dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, raw, 0o644); err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.Dir, "new", name)
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	logging.WithContext(ctx).Infof("email to %s delivered to %s", strings.Join(recipients, ", "), path)
+	return nil
+}
+
+func (l *LogTransport) Deliver(ctx context.Context, from string, recipients []string, raw []byte) error {
+	logging.WithContext(ctx).Infof("email from %s to %s:\n%s", from, strings.Join(recipients, ", "), raw)
+	return nil
+}
+
+func (m *MemoryTransport) Deliver(ctx context.Context, from string, recipients []string, raw []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, SentEmail{
+		From:       from,
+		Recipients: append([]string{}, recipients...),
+		Raw:        append([]byte{}, raw...),
+	})
+	return nil
+}
+
+// Messages returns the emails delivered so far, oldest first
+func (m *MemoryTransport) Messages() []SentEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]SentEmail{}, m.messages...)
+}
+
+// Reset forgets the emails delivered so far
+func (m *MemoryTransport) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = nil
+}
+
+// uniqueMailName follows the maildir naming (time, unique part, host) which also keeps .eml files in order
+func uniqueMailName() string {
+	random := make([]byte, 6)
+	rand.Read(random)
+
+	host, _ := os.Hostname()
+	host = strings.NewReplacer("/", "_", ":", "_").Replace(host)
+
+	return fmt.Sprintf("%d.%s.%s", time.Now().UnixNano(), hex.EncodeToString(random), host)
+}
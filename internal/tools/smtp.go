@@ -1,72 +1,122 @@
 package tools
 
 import (
+	"context"
 	"github.com/audricimanuel/errorutils"
 	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
-	"net/smtp"
-	"strings"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/logging"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"github.com/sirupsen/logrus"
+	netMail "net/mail"
+	"time"
 )
 
 type (
-	EmailType string
-
 	SMTPClient interface {
-		SendEmail(mimeType string, emailType EmailType, subject, message string, receivers []string, cc []string) error
+		// SendEmail renders the message from the templates of its type when it has no body yet, then sends it
+		SendEmail(ctx context.Context, message mail.Message) error
 	}
 
 	SMTPClientImpl struct {
-		Host           string
-		Port           string
-		User           string
-		Password       string
-		UseTLS         bool
 		CSEmailAddress string
+		FromName       string
+		// Transport delivers the built emails, see NewMailTransport
+		Transport   MailTransport
+		Unsubscribe UnsubscribeTokens
 	}
 )
 
-const (
-	EMAIL_TYPE_OTP EmailType = "otp"
-)
-
-func NewSMTPClient(cfg config.Config) SMTPClient {
+func NewSMTPClient(cfg config.Config, unsubscribe UnsubscribeTokens) SMTPClient {
 	smtpConfig := cfg.SMTPConfig
+
+	transport, err := NewMailTransport(smtpConfig.Transport, SMTPTransport{
+		Host:     smtpConfig.Host,
+		Port:     smtpConfig.Port,
+		User:     smtpConfig.User,
+		Password: smtpConfig.Password,
+		UseTLS:   smtpConfig.UseTLS,
+		Timeout:  time.Duration(smtpConfig.Timeout) * time.Second,
+	}, smtpConfig.FileDir)
+	if err != nil {
+		logrus.Fatalf("error when NewSMTPClient, error: %s\n", err.Error())
+	}
+
 	return &SMTPClientImpl{
-		Host:           smtpConfig.Host,
-		Port:           smtpConfig.Port,
-		User:           smtpConfig.User,
-		Password:       smtpConfig.Password,
 		CSEmailAddress: smtpConfig.CSEmailAddress,
+		FromName:       smtpConfig.FromName,
+		Transport:      transport,
+		Unsubscribe:    unsubscribe,
 	}
 }
 
-func (s *SMTPClientImpl) SendEmail(mimeType string, emailType EmailType, subject, message string, receivers []string, cc []string) error {
-	typeSender := map[EmailType]string{
-		EMAIL_TYPE_OTP: s.CSEmailAddress,
-		"default":      s.CSEmailAddress,
+func (s *SMTPClientImpl) SendEmail(ctx context.Context, message mail.Message) error {
+	log := logging.WithContext(ctx)
+
+	typeSender := map[mail.EmailType]string{
+		mail.EMAIL_TYPE_OTP_SIGNUP: s.CSEmailAddress,
+		"default":                  s.CSEmailAddress,
 	}
 
-	sender := typeSender["default"]
-	if email, ok := typeSender[emailType]; ok {
-		sender = email
+	if message.From == "" {
+		sender := typeSender["default"]
+		if email, ok := typeSender[message.Type]; ok {
+			sender = email
+		}
+		message.From = (&netMail.Address{Name: s.FromName, Address: sender}).String()
 	}
 
-	mime := ""
-	if mimeType == "html" {
-		mime = "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	if message.Category.IsOptional() {
+		if message.UserId == "" {
+			log.Error("non-transactional email without recipient user:", message.Type)
+			return errorutils.ErrorInternalServer.CustomMessage("missing recipient user of non-transactional email")
+		}
+		message = s.withUnsubscribe(message)
 	}
 
-	body := "From: " + sender + "\n" +
-		"To: " + strings.Join(receivers, ",") + "\n" +
-		"Cc: " + strings.Join(cc, ",") + "\n" +
-		"Subject: " + subject + "\n" +
-		mime + "\n" +
-		message
+	message, err := mail.Render(message)
+	if err != nil {
+		log.Error("error when rendering email:", err)
+		return errorutils.ErrorInternalServer.CustomMessage(err.Error())
+	}
 
-	smtpAuth := smtp.PlainAuth("", s.User, s.Password, s.Host)
+	body, err := message.Bytes(time.Now())
+	if err != nil {
+		log.Error("error when building email:", err)
+		return errorutils.ErrorInternalServer.CustomMessage(err.Error())
+	}
 
-	if err := smtp.SendMail(s.Host+":"+s.Port, smtpAuth, sender, append(receivers, cc...), []byte(body)); err != nil {
+	sender, err := netMail.ParseAddress(message.From)
+	if err != nil {
+		log.Error("invalid email sender:", err)
+		return errorutils.ErrorInternalServer.CustomMessage(err.Error())
+	}
+
+	if err := s.Transport.Deliver(ctx, sender.Address, message.Recipients(), body); err != nil {
+		log.Error("error when sending email:", err)
 		return errorutils.ErrorInternalServer.CustomMessage(err.Error())
 	}
 
 	return nil
 }
+
+// withUnsubscribe adds the one-click unsubscribe headers (RFC 8058) and exposes the link to the templates
+func (s *SMTPClientImpl) withUnsubscribe(message mail.Message) mail.Message {
+	unsubscribeURL := s.Unsubscribe.URL(message.UserId, message.Category)
+
+	headers := map[string]string{}
+	for key, value := range message.Headers {
+		headers[key] = value
+	}
+	headers["List-Unsubscribe"] = "<" + unsubscribeURL + ">"
+	headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+	message.Headers = headers
+
+	data := mail.Data{}
+	for key, value := range message.Data {
+		data[key] = value
+	}
+	data["UnsubscribeURL"] = unsubscribeURL
+	message.Data = data
+
+	return message
+}
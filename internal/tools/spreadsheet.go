@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"strings"
+)
+
+const (
+	SPREADSHEET_FORMAT_CSV  = "csv"
+	SPREADSHEET_FORMAT_XLSX = "xlsx"
+
+	// csvFlushRows is how many rows are buffered before they're flushed to the response
+	csvFlushRows = 100
+)
+
+var ErrUnsupportedSpreadsheetFormat = errors.New("unsupported spreadsheet format")
+
+var spreadsheetContentTypes = map[string]string{
+	SPREADSHEET_FORMAT_CSV:  "text/csv; charset=utf-8",
+	SPREADSHEET_FORMAT_XLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type (
+	// SpreadsheetWriter writes rows one at a time, Close must be called to complete the file
+	SpreadsheetWriter interface {
+		WriteRow(values ...any) error
+		Close() error
+	}
+
+	csvWriter struct {
+		writer *csv.Writer
+		rows   int
+	}
+
+	// bomWriter writes the UTF-8 byte order mark before the first bytes, so nothing reaches the output
+	// (e.g. the response) until there's content to send
+	bomWriter struct {
+		out     io.Writer
+		written bool
+	}
+
+	// xlsxWriter streams the rows into the sheet, excelize keeps them in a temporary file once
+	// they outgrow its memory buffer, the workbook is written out on Close
+	xlsxWriter struct {
+		out    io.Writer
+		file   *excelize.File
+		stream *excelize.StreamWriter
+		rows   int
+	}
+)
+
+// SpreadsheetContentType returns the MIME type of the format, ok is false when the format isn't supported
+func SpreadsheetContentType(format string) (string, bool) {
+	contentType, ok := spreadsheetContentTypes[format]
+	return contentType, ok
+}
+
+// NewSpreadsheetWriter starts a CSV or XLSX file written to w, sheetName names the sheet of an XLSX file.
+// Nothing is written to w before the first rows are flushed, so a failure before that can still be reported.
+func NewSpreadsheetWriter(format, sheetName string, w io.Writer) (SpreadsheetWriter, error) {
+	switch format {
+	case SPREADSHEET_FORMAT_CSV:
+		// the byte order mark makes spreadsheet apps read the file as UTF-8
+		return &csvWriter{writer: csv.NewWriter(&bomWriter{out: w})}, nil
+	case SPREADSHEET_FORMAT_XLSX:
+		file := excelize.NewFile()
+		if err := file.SetSheetName(file.GetSheetName(0), sheetName); err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		stream, err := file.NewStreamWriter(sheetName)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		return &xlsxWriter{out: w, file: file, stream: stream}, nil
+	default:
+		return nil, ErrUnsupportedSpreadsheetFormat
+	}
+}
+
+func (c *csvWriter) WriteRow(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = escapeFormula(v)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+
+	if err := c.writer.Write(record); err != nil {
+		return err
+	}
+
+	c.rows++
+	if c.rows%csvFlushRows == 0 {
+		c.writer.Flush()
+		return c.writer.Error()
+	}
+
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (b *bomWriter) Write(p []byte) (int, error) {
+	if !b.written {
+		if _, err := b.out.Write([]byte("\xEF\xBB\xBF")); err != nil {
+			return 0, err
+		}
+		b.written = true
+	}
+
+	return b.out.Write(p)
+}
+
+func (x *xlsxWriter) WriteRow(values ...any) error {
+	x.rows++
+
+	cell, err := excelize.CoordinatesToCellName(1, x.rows)
+	if err != nil {
+		return err
+	}
+
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+
+	return x.file.Write(x.out)
+}
+
+// escapeFormula keeps spreadsheet apps from evaluating a text starting like a formula
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
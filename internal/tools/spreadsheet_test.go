@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"bytes"
+	"errors"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+)
+
+func TestCSVSpreadsheetWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]any
+		want string
+	}{
+		{name: "no rows"},
+		{
+			name: "values",
+			rows: [][]any{{"title", "amount", "notes"}, {"Weekly, big", 3, nil}, {`say "hi"`, 1.5, true}},
+			want: "\xEF\xBB\xBFtitle,amount,notes\n\"Weekly, big\",3,\n\"say \"\"hi\"\"\",1.5,true\n",
+		},
+		{
+			name: "formulas",
+			rows: [][]any{{"=SUM(A1:A2)", "+1", "-1", "@cmd", "a=b"}},
+			want: "\xEF\xBB\xBF'=SUM(A1:A2),'+1,'-1,'@cmd,a=b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			writer, err := NewSpreadsheetWriter(SPREADSHEET_FORMAT_CSV, "Routines", &out)
+			if err != nil {
+				t.Fatalf("NewSpreadsheetWriter: %v", err)
+			}
+
+			for _, row := range tt.rows {
+				if err := writer.WriteRow(row...); err != nil {
+					t.Fatalf("WriteRow: %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVSpreadsheetWriterFlush(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewSpreadsheetWriter(SPREADSHEET_FORMAT_CSV, "Routines", &out)
+	if err != nil {
+		t.Fatalf("NewSpreadsheetWriter: %v", err)
+	}
+
+	for i := 1; i <= csvFlushRows; i++ {
+		if err := writer.WriteRow("row", i); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+
+		if flushed := out.Len() > 0; flushed != (i == csvFlushRows) {
+			t.Fatalf("after %d rows flushed = %t", i, flushed)
+		}
+	}
+}
+
+func TestXLSXSpreadsheetWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewSpreadsheetWriter(SPREADSHEET_FORMAT_XLSX, "Routines", &out)
+	if err != nil {
+		t.Fatalf("NewSpreadsheetWriter: %v", err)
+	}
+
+	rows := [][]any{{"title", "amount"}, {"Weekly", 3}, {"=SUM(B1:B2)", nil}}
+	for _, row := range rows {
+		if err := writer.WriteRow(row...); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&out)
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer file.Close()
+
+	if got := file.GetSheetList(); !reflect.DeepEqual(got, []string{"Routines"}) {
+		t.Errorf("sheets = %q", got)
+	}
+
+	got, err := file.GetRows("Routines")
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+
+	want := [][]string{{"title", "amount"}, {"Weekly", "3"}, {"=SUM(B1:B2)"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestNewSpreadsheetWriterFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr error
+	}{
+		{format: SPREADSHEET_FORMAT_CSV},
+		{format: SPREADSHEET_FORMAT_XLSX},
+		{format: "pdf", wantErr: ErrUnsupportedSpreadsheetFormat},
+	}
+
+	for _, tt := range tests {
+		_, err := NewSpreadsheetWriter(tt.format, "Routines", &bytes.Buffer{})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("NewSpreadsheetWriter(%q) error = %v, want %v", tt.format, err, tt.wantErr)
+		}
+
+		_, ok := SpreadsheetContentType(tt.format)
+		if ok != (tt.wantErr == nil) {
+			t.Errorf("SpreadsheetContentType(%q) ok = %t", tt.format, ok)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"strings"
+)
+
+const (
+	// TRACKING_OPEN_PATH serves the tracking pixel, TRACKING_CLICK_PATH redirects to the tracked link
+	TRACKING_OPEN_PATH  = "/t/open/"
+	TRACKING_CLICK_PATH = "/t/click/"
+
+	trackingKeyPrefix = "tracking:"
+)
+
+var ErrInvalidTrackingToken = errors.New("invalid tracking token")
+
+type (
+	// TrackingTokens signs the campaign, recipient and link of the tracking URLs put in campaign emails
+	TrackingTokens interface {
+		// Enabled is the global switch, when it's off no tracking is added to emails and no event is recorded
+		Enabled() bool
+		OpenURL(campaignId, userId string) string
+		ClickURL(campaignId, userId, target string) string
+		Verify(token string) (*TrackingClaims, error)
+	}
+
+	TrackingClaims struct {
+		CampaignId string `json:"c"`
+		UserId     string `json:"u"`
+		// Target is the link a click is redirected to, empty for opens
+		Target string `json:"l,omitempty"`
+	}
+
+	TrackingTokensImpl struct {
+		enabled bool
+		secret  []byte
+		baseUrl string
+	}
+)
+
+func NewTrackingTokens(cfg config.Config) TrackingTokens {
+	secret := cfg.CookieSecret
+	if secret == "" {
+		secret = cfg.JWTSecret
+	}
+
+	return &TrackingTokensImpl{
+		enabled: cfg.EmailTrackingEnabled,
+		secret:  []byte(trackingKeyPrefix + secret),
+		baseUrl: strings.TrimSuffix(cfg.Host.BaseUrl, "/"),
+	}
+}
+
+func (t *TrackingTokensImpl) Enabled() bool {
+	return t.enabled
+}
+
+func (t *TrackingTokensImpl) OpenURL(campaignId, userId string) string {
+	return t.baseUrl + TRACKING_OPEN_PATH + t.sign(TrackingClaims{CampaignId: campaignId, UserId: userId})
+}
+
+func (t *TrackingTokensImpl) ClickURL(campaignId, userId, target string) string {
+	return t.baseUrl + TRACKING_CLICK_PATH + t.sign(TrackingClaims{CampaignId: campaignId, UserId: userId, Target: target})
+}
+
+func (t *TrackingTokensImpl) Verify(token string) (*TrackingClaims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(t.signature(payload))) {
+		return nil, ErrInvalidTrackingToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidTrackingToken
+	}
+
+	var claims TrackingClaims
+	if err := json.Unmarshal(decoded, &claims); err != nil || claims.CampaignId == "" || claims.UserId == "" {
+		return nil, ErrInvalidTrackingToken
+	}
+
+	return &claims, nil
+}
+
+func (t *TrackingTokensImpl) sign(claims TrackingClaims) string {
+	decoded, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(decoded)
+	return payload + "." + t.signature(payload)
+}
+
+func (t *TrackingTokensImpl) signature(value string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/config"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/mail"
+	"net/url"
+	"strings"
+)
+
+const (
+	// UNSUBSCRIBE_PATH is the page the unsubscribe links point to, it also accepts the one-click POST (RFC 8058)
+	UNSUBSCRIBE_PATH = "/unsubscribe"
+
+	// unsubscribeKeyPrefix keeps the signatures apart from the other values signed with the same secret
+	unsubscribeKeyPrefix = "unsubscribe:"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+type (
+	// UnsubscribeTokens signs the user and category of an unsubscribe link,
+	// so the link works without logging in and can't be forged for another user
+	UnsubscribeTokens interface {
+		Sign(userId string, category mail.Category) string
+		Verify(token string) (userId string, category mail.Category, err error)
+		// URL is the absolute unsubscribe link for the user and category
+		URL(userId string, category mail.Category) string
+	}
+
+	UnsubscribeTokensImpl struct {
+		secret  []byte
+		baseUrl string
+	}
+)
+
+func NewUnsubscribeTokens(cfg config.Config) UnsubscribeTokens {
+	secret := cfg.CookieSecret
+	if secret == "" {
+		secret = cfg.JWTSecret
+	}
+
+	return &UnsubscribeTokensImpl{
+		secret:  []byte(unsubscribeKeyPrefix + secret),
+		baseUrl: strings.TrimSuffix(cfg.Host.BaseUrl, "/"),
+	}
+}
+
+func (u *UnsubscribeTokensImpl) Sign(userId string, category mail.Category) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userId + ":" + string(category)))
+	return payload + "." + u.sign(payload)
+}
+
+func (u *UnsubscribeTokensImpl) Verify(token string) (string, mail.Category, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(u.sign(payload))) {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	userId, category, ok := strings.Cut(string(decoded), ":")
+	if !ok || userId == "" || !mail.Category(category).IsOptional() {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	return userId, mail.Category(category), nil
+}
+
+func (u *UnsubscribeTokensImpl) URL(userId string, category mail.Category) string {
+	return u.baseUrl + UNSUBSCRIBE_PATH + "?token=" + url.QueryEscape(u.Sign(userId, category))
+}
+
+func (u *UnsubscribeTokensImpl) sign(value string) string {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+// Package worker runs the background jobs of the service next to the HTTP server.
+package worker
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+type (
+	// Job is a unit of background work. It's called again on the next tick even when it fails.
+	Job func(ctx context.Context) error
+
+	Runner struct {
+		wg sync.WaitGroup
+	}
+)
+
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Every runs the job right away and then every interval, until ctx is cancelled
+func (r *Runner) Every(ctx context.Context, name string, interval time.Duration, job Job) {
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		log := logrus.WithField("worker", name)
+		log.Printf("worker started, running every %s", interval)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := job(ctx); err != nil && ctx.Err() == nil {
+				log.Error("error when running worker:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Println("worker stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until every job has returned after their context got cancelled
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
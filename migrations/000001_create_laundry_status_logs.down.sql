@@ -0,0 +1 @@
+DROP TABLE IF EXISTS laundry_status_logs;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS laundry_status_logs (
+    id         BIGSERIAL PRIMARY KEY,
+    laundry_id VARCHAR(32) NOT NULL REFERENCES laundries (id) ON DELETE CASCADE,
+    status     SMALLINT    NOT NULL,
+    created_by VARCHAR(32) NOT NULL REFERENCES users (id),
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_laundry_status_logs_laundry_id ON laundry_status_logs (laundry_id, created_at);
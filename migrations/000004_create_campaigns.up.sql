@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id            VARCHAR(32)  PRIMARY KEY,
+    "name"        VARCHAR(255) NOT NULL,
+    subject       TEXT         NOT NULL,
+    html_body     TEXT         NOT NULL,
+    text_body     TEXT,
+    audience      VARCHAR(32)  NOT NULL,
+    inactive_days INT,
+    status        VARCHAR(16)  NOT NULL DEFAULT 'draft',
+    scheduled_at  TIMESTAMP,
+    sent_at       TIMESTAMP,
+    created_by    VARCHAR(32)  NOT NULL REFERENCES users (id),
+    created_at    TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_due ON campaigns (status, scheduled_at);
+
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    id          BIGSERIAL PRIMARY KEY,
+    campaign_id VARCHAR(32)  NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    user_id     VARCHAR(32)  NOT NULL REFERENCES users (id),
+    email       VARCHAR(255) NOT NULL,
+    -- idempotency key of the email in email_outbox, where the delivery status is tracked
+    outbox_key  VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    UNIQUE (campaign_id, user_id)
+);
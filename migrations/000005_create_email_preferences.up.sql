@@ -0,0 +1,8 @@
+-- users are subscribed to every optional category until they opt out, so only the choices made are stored
+CREATE TABLE IF NOT EXISTS email_preferences (
+    user_id    VARCHAR(32) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category   VARCHAR(32) NOT NULL,
+    subscribed BOOLEAN     NOT NULL,
+    updated_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, category)
+);
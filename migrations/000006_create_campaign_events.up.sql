@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS campaign_events (
+    id          BIGSERIAL PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    user_id     VARCHAR(32) NOT NULL REFERENCES users (id),
+    event_type  VARCHAR(16) NOT NULL,
+    url         TEXT,
+    created_at  TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_events_campaign ON campaign_events (campaign_id, event_type, user_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS digest_sends;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
+
+-- one row per user and week, the digest of a week is only ever queued once
+CREATE TABLE IF NOT EXISTS digest_sends (
+    user_id    VARCHAR(32) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    week_start DATE        NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, week_start)
+);
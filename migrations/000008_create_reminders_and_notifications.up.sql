@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS reminders (
+    id         VARCHAR(32)  PRIMARY KEY,
+    laundry_id VARCHAR(32)  NOT NULL REFERENCES laundries (id) ON DELETE CASCADE,
+    user_id    VARCHAR(32)  NOT NULL REFERENCES users (id),
+    message    VARCHAR(255) NOT NULL,
+    remind_at  TIMESTAMP    NOT NULL,
+    status     VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    fired_at   TIMESTAMP,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_laundry_id ON reminders (laundry_id, remind_at);
+CREATE INDEX IF NOT EXISTS idx_reminders_due ON reminders (remind_at) WHERE status = 'pending';
+
+-- in-app notifications, source_key makes creating the notification of an event idempotent
+CREATE TABLE IF NOT EXISTS notifications (
+    id         VARCHAR(32)  PRIMARY KEY,
+    user_id    VARCHAR(32)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type       VARCHAR(32)  NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT         NOT NULL,
+    laundry_id VARCHAR(32)  REFERENCES laundries (id) ON DELETE CASCADE,
+    source_key VARCHAR(128) NOT NULL UNIQUE,
+    read_at    TIMESTAMP,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, created_at DESC);
@@ -0,0 +1,5 @@
+ALTER TABLE laundries DROP CONSTRAINT IF EXISTS uq_laundries_series_occurrence;
+ALTER TABLE laundries DROP COLUMN IF EXISTS occurrence_at;
+ALTER TABLE laundries DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS laundry_series_exceptions;
+DROP TABLE IF EXISTS laundry_series;
@@ -0,0 +1,31 @@
+-- a recurring routine: starts_at is the wall-clock time of the first occurrence in the series timezone
+CREATE TABLE IF NOT EXISTS laundry_series (
+    id              VARCHAR(32)  PRIMARY KEY,
+    user_id         VARCHAR(32)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title           VARCHAR(255) NOT NULL,
+    rrule           TEXT         NOT NULL,
+    starts_at       TIMESTAMP    NOT NULL,
+    timezone        VARCHAR(64)  NOT NULL,
+    items           JSONB        NOT NULL DEFAULT '[]',
+    is_active       BOOLEAN      NOT NULL DEFAULT TRUE,
+    -- occurrences up to this time have been materialized as laundries
+    generated_until TIMESTAMP,
+    created_at      TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_laundry_series_user_id ON laundry_series (user_id);
+CREATE INDEX IF NOT EXISTS idx_laundry_series_generation ON laundry_series (generated_until) WHERE is_active;
+
+-- occurrences that were skipped or edited, they're left alone when the series is regenerated
+CREATE TABLE IF NOT EXISTS laundry_series_exceptions (
+    series_id     VARCHAR(32) NOT NULL REFERENCES laundry_series (id) ON DELETE CASCADE,
+    occurrence_at TIMESTAMP   NOT NULL,
+    kind          VARCHAR(16) NOT NULL,
+    created_at    TIMESTAMP   NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (series_id, occurrence_at)
+);
+
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS series_id VARCHAR(32) REFERENCES laundry_series (id) ON DELETE SET NULL;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS occurrence_at TIMESTAMP;
+ALTER TABLE laundries ADD CONSTRAINT uq_laundries_series_occurrence UNIQUE (series_id, occurrence_at);
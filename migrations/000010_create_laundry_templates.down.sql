@@ -0,0 +1 @@
+DROP TABLE IF EXISTS laundry_templates;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS laundry_templates (
+    id         VARCHAR(32)  PRIMARY KEY,
+    user_id    VARCHAR(32)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(255) NOT NULL,
+    items      JSONB        NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
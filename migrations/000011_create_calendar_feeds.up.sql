@@ -0,0 +1,6 @@
+-- the secret token of the iCal subscription URL of each user, replacing it revokes the old URL
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id    VARCHAR(32) PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    token      VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_laundries_vendor_id;
+ALTER TABLE laundries DROP COLUMN IF EXISTS cost;
+ALTER TABLE laundries DROP COLUMN IF EXISTS weight_kg;
+ALTER TABLE laundries DROP COLUMN IF EXISTS pickup_date;
+ALTER TABLE laundries DROP COLUMN IF EXISTS drop_off_date;
+ALTER TABLE laundries DROP COLUMN IF EXISTS vendor_id;
+DROP TABLE IF EXISTS vendor_prices;
+DROP TABLE IF EXISTS vendors;
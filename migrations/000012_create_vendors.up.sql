@@ -0,0 +1,32 @@
+-- laundry shops the routines can be sent to
+CREATE TABLE IF NOT EXISTS vendors (
+    id         VARCHAR(32)  PRIMARY KEY,
+    user_id    VARCHAR(32)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(255) NOT NULL,
+    address    TEXT,
+    contact    VARCHAR(255),
+    -- removed vendors are kept so the routines sent to them keep their spend
+    is_active  BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_vendors_user_id ON vendors (user_id);
+
+-- the price list of a vendor, each category is charged per kg of the routine weight or per item
+CREATE TABLE IF NOT EXISTS vendor_prices (
+    vendor_id   VARCHAR(32)    NOT NULL REFERENCES vendors (id) ON DELETE CASCADE,
+    category_id VARCHAR(32)    NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    unit        VARCHAR(8)     NOT NULL,
+    price       NUMERIC(12, 2) NOT NULL,
+    PRIMARY KEY (vendor_id, category_id)
+);
+
+-- the cost is computed from the price list when the routine is linked, later price changes don't alter it
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS vendor_id VARCHAR(32) REFERENCES vendors (id) ON DELETE SET NULL;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS drop_off_date DATE;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS pickup_date DATE;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS weight_kg NUMERIC(8, 2);
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS cost NUMERIC(12, 2);
+
+CREATE INDEX IF NOT EXISTS idx_laundries_vendor_id ON laundries (vendor_id) WHERE vendor_id IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_laundries_discrepancy;
+ALTER TABLE laundries DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE laundries DROP COLUMN IF EXISTS resolution_note;
+ALTER TABLE laundries DROP COLUMN IF EXISTS has_discrepancy;
+ALTER TABLE laundries DROP COLUMN IF EXISTS total_returned_items;
+ALTER TABLE laundry_items DROP COLUMN IF EXISTS returned_amount;
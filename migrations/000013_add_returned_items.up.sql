@@ -0,0 +1,10 @@
+-- the amount of each item that came back, NULL until the returns of the routine are recorded
+ALTER TABLE laundry_items ADD COLUMN IF NOT EXISTS returned_amount INT;
+
+-- the returned counterpart of total_items, a discrepancy is an item that didn't come back in its sent amount
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS total_returned_items INT;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS has_discrepancy BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS resolution_note TEXT;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_laundries_discrepancy ON laundries (user_id) WHERE has_discrepancy;
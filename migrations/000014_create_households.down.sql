@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_laundries_household_id;
+DROP INDEX IF EXISTS idx_categories_household_id;
+ALTER TABLE laundries DROP COLUMN IF EXISTS household_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS household_id;
+DROP TABLE IF EXISTS household_invitations;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
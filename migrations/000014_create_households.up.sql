@@ -0,0 +1,41 @@
+-- households share their categories and routines between their members
+CREATE TABLE IF NOT EXISTS households (
+    id         VARCHAR(32)  PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    created_by VARCHAR(32)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+-- a user belongs to one household at most, the role is owner, member or viewer
+CREATE TABLE IF NOT EXISTS household_members (
+    household_id VARCHAR(32) NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    user_id      VARCHAR(32) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         VARCHAR(16) NOT NULL,
+    created_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (household_id, user_id),
+    CONSTRAINT uq_household_members_user_id UNIQUE (user_id)
+);
+
+-- invitations are sent by email, the link carries the signed id of the invitation
+CREATE TABLE IF NOT EXISTS household_invitations (
+    id           VARCHAR(32)  PRIMARY KEY,
+    household_id VARCHAR(32)  NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    email        VARCHAR(255) NOT NULL,
+    role         VARCHAR(16)  NOT NULL,
+    invited_by   VARCHAR(32)  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at   TIMESTAMP    NOT NULL,
+    accepted_at  TIMESTAMP,
+    accepted_by  VARCHAR(32)  REFERENCES users (id) ON DELETE SET NULL,
+    revoked_at   TIMESTAMP,
+    created_at   TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_household_invitations_household_id ON household_invitations (household_id);
+
+-- the household the category or routine was shared with, NULL for the ones kept by their user
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS household_id VARCHAR(32) REFERENCES households (id) ON DELETE SET NULL;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS household_id VARCHAR(32) REFERENCES households (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_categories_household_id ON categories (household_id) WHERE household_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_laundries_household_id ON laundries (household_id) WHERE household_id IS NOT NULL;
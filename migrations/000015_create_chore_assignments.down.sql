@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_chore_swaps_requested_by;
+DROP INDEX IF EXISTS idx_chore_swaps_requested_to;
+DROP TABLE IF EXISTS chore_swaps;
+DROP TABLE IF EXISTS laundry_series_rotation_members;
+DROP TABLE IF EXISTS laundry_series_rotations;
+DROP INDEX IF EXISTS idx_laundry_assignments_user_id;
+DROP TABLE IF EXISTS laundry_assignments;
@@ -0,0 +1,46 @@
+-- the household member whose turn it is to do a routine, or one of its steps
+CREATE TABLE IF NOT EXISTS laundry_assignments (
+    laundry_id  VARCHAR(32) NOT NULL REFERENCES laundries (id) ON DELETE CASCADE,
+    step        VARCHAR(16) NOT NULL,
+    user_id     VARCHAR(32) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    assigned_by VARCHAR(32) REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP   NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (laundry_id, step)
+);
+
+CREATE INDEX IF NOT EXISTS idx_laundry_assignments_user_id ON laundry_assignments (user_id);
+
+-- the generated routines of a series are assigned to the members of its rotation in turn, next_turn is the
+-- position of the member who gets the next one
+CREATE TABLE IF NOT EXISTS laundry_series_rotations (
+    series_id  VARCHAR(32) PRIMARY KEY REFERENCES laundry_series (id) ON DELETE CASCADE,
+    step       VARCHAR(16) NOT NULL,
+    next_turn  INT         NOT NULL DEFAULT 0,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS laundry_series_rotation_members (
+    series_id VARCHAR(32) NOT NULL REFERENCES laundry_series_rotations (series_id) ON DELETE CASCADE,
+    user_id   VARCHAR(32) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    position  INT         NOT NULL,
+    PRIMARY KEY (series_id, user_id)
+);
+
+-- a member asks another one to take a turn, optionally taking one of theirs in exchange
+CREATE TABLE IF NOT EXISTS chore_swaps (
+    id                  VARCHAR(32) PRIMARY KEY,
+    laundry_id          VARCHAR(32) NOT NULL REFERENCES laundries (id) ON DELETE CASCADE,
+    step                VARCHAR(16) NOT NULL,
+    requested_by        VARCHAR(32) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    requested_to        VARCHAR(32) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    exchange_laundry_id VARCHAR(32) REFERENCES laundries (id) ON DELETE CASCADE,
+    exchange_step       VARCHAR(16),
+    status              VARCHAR(16) NOT NULL,
+    created_at          TIMESTAMP   NOT NULL DEFAULT NOW(),
+    responded_at        TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chore_swaps_requested_to ON chore_swaps (requested_to, status);
+CREATE INDEX IF NOT EXISTS idx_chore_swaps_requested_by ON chore_swaps (requested_by, status);
@@ -0,0 +1,6 @@
+UPDATE laundries
+SET laundry_date = ((laundry_date + laundry_time) AT TIME ZONE timezone) AT TIME ZONE 'UTC'
+WHERE laundry_time IS NOT NULL AND timezone IS NOT NULL;
+
+ALTER TABLE laundries DROP COLUMN IF EXISTS timezone;
+ALTER TABLE laundries DROP COLUMN IF EXISTS laundry_time;
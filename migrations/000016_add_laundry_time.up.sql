@@ -0,0 +1,19 @@
+-- laundry_date is the local date of the routine, stored at midnight. The routines planned at a time of the day
+-- (e.g. the occurrences of a series) also have the wall-clock time and the timezone it's in.
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS laundry_time TIME;
+ALTER TABLE laundries ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+
+-- the occurrences were stored as UTC instants, they move to the date and time in the series timezone
+UPDATE laundries l
+SET laundry_time = ((l.laundry_date AT TIME ZONE 'UTC') AT TIME ZONE s.timezone)::TIME,
+    timezone     = s.timezone,
+    laundry_date = ((l.laundry_date AT TIME ZONE 'UTC') AT TIME ZONE s.timezone)::DATE
+FROM laundry_series s
+WHERE s.id = l.series_id;
+
+-- the other routines with a time were imported from a calendar as UTC instants
+UPDATE laundries
+SET laundry_time = laundry_date::TIME,
+    timezone     = 'UTC',
+    laundry_date = laundry_date::DATE
+WHERE laundry_time IS NULL AND laundry_date::TIME <> '00:00';
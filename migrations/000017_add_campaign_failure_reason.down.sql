@@ -0,0 +1,3 @@
+UPDATE campaigns SET status = 'draft' WHERE status = 'failed';
+
+ALTER TABLE campaigns DROP COLUMN IF EXISTS failure_reason;
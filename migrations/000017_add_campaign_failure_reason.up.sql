@@ -0,0 +1,2 @@
+-- the reason a campaign failed, e.g. an email that couldn't be built from its templates
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS failure_reason TEXT;
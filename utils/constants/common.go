@@ -8,6 +8,33 @@ const (
 	HEADER_AUTHORIZATION = "Authorization"
 )
 
+const (
+	HEADER_ACCEPT_LANGUAGE = "Accept-Language"
+	HEADER_CSRF_TOKEN      = "X-CSRF-Token"
+	HEADER_REQUESTED_WITH  = "X-Requested-With"
+)
+
 const (
 	COOKIE_AUTH_TOKEN = "auth_token"
+	COOKIE_CSRF_TOKEN = "csrf_token"
+	COOKIE_FLASH      = "flash"
+	COOKIE_LOCALE     = "lang"
+)
+
+const (
+	FORM_CSRF_TOKEN = "_csrf"
+	QUERY_LOCALE    = "lang"
+)
+
+// context keys of the data shared by every HTML page
+const (
+	CSRF_TOKEN     = "csrf-token"
+	VIEW_ENV       = "view-env"
+	FLASH_MESSAGES = "flash-messages"
+)
+
+// context keys of the negotiated locale
+const (
+	LOCALE          = "locale"
+	LOCALE_EXPLICIT = "locale-explicit"
 )
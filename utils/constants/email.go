@@ -1,5 +0,0 @@
-package constants
-
-const (
-	SUBJECT_OTP_SIGNUP = "Your Signup OTP - Laundry Tracking"
-)
@@ -0,0 +1,35 @@
+package constants
+
+type HouseholdRole string
+
+const (
+	// HOUSEHOLD_ROLE_OWNER manages the household, its members and invitations
+	HOUSEHOLD_ROLE_OWNER HouseholdRole = "owner"
+	// HOUSEHOLD_ROLE_MEMBER adds and updates the shared categories and routines
+	HOUSEHOLD_ROLE_MEMBER HouseholdRole = "member"
+	// HOUSEHOLD_ROLE_VIEWER only sees the shared categories and routines
+	HOUSEHOLD_ROLE_VIEWER HouseholdRole = "viewer"
+)
+
+func (r HouseholdRole) IsValid() bool {
+	switch r {
+	case HOUSEHOLD_ROLE_OWNER, HOUSEHOLD_ROLE_MEMBER, HOUSEHOLD_ROLE_VIEWER:
+		return true
+	}
+	return false
+}
+
+// HouseholdEditorRoles lists the roles that can change the shared categories and routines
+func HouseholdEditorRoles() []HouseholdRole {
+	return []HouseholdRole{HOUSEHOLD_ROLE_OWNER, HOUSEHOLD_ROLE_MEMBER}
+}
+
+// CanEdit reports whether the role can change the shared categories and routines
+func (r HouseholdRole) CanEdit() bool {
+	for _, role := range HouseholdEditorRoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package constants
+
+type LaundryStatus int
+
+const (
+	LAUNDRY_LIST_LIMIT = 10
+
+	// LAUNDRY_UPCOMING_LIMIT is how many upcoming routines the weekly digest lists
+	LAUNDRY_UPCOMING_LIMIT = 5
+
+	// LAUNDRY_IMPORT_MAX_FILE_SIZE and LAUNDRY_IMPORT_MAX_ROWS bound the files a routine import accepts
+	LAUNDRY_IMPORT_MAX_FILE_SIZE = 1 << 20
+	LAUNDRY_IMPORT_MAX_ROWS      = 500
+
+	// LAUNDRY_IMPORT_ROW_ values are the outcome of each row of a CSV import
+	LAUNDRY_IMPORT_ROW_CREATED = "created"
+	LAUNDRY_IMPORT_ROW_SKIPPED = "skipped"
+	LAUNDRY_IMPORT_ROW_FAILED  = "failed"
+
+	// LAUNDRY_TITLE_MAX_LENGTH is the longest title a routine can have
+	LAUNDRY_TITLE_MAX_LENGTH = 255
+)
+
+const (
+	LAUNDRY_STATUS_PLANNED   LaundryStatus = 0
+	LAUNDRY_STATUS_WASHING   LaundryStatus = 1
+	LAUNDRY_STATUS_DRYING    LaundryStatus = 2
+	LAUNDRY_STATUS_DONE      LaundryStatus = 3
+	LAUNDRY_STATUS_CANCELLED LaundryStatus = 4
+)
+
+var laundryStatusKeys = map[LaundryStatus]string{
+	LAUNDRY_STATUS_PLANNED:   "planned",
+	LAUNDRY_STATUS_WASHING:   "washing",
+	LAUNDRY_STATUS_DRYING:    "drying",
+	LAUNDRY_STATUS_DONE:      "done",
+	LAUNDRY_STATUS_CANCELLED: "cancelled",
+}
+
+// laundryStatusTransitions lists the statuses a routine can move to from its current status
+var laundryStatusTransitions = map[LaundryStatus][]LaundryStatus{
+	LAUNDRY_STATUS_PLANNED: {LAUNDRY_STATUS_WASHING, LAUNDRY_STATUS_CANCELLED},
+	LAUNDRY_STATUS_WASHING: {LAUNDRY_STATUS_DRYING, LAUNDRY_STATUS_DONE},
+	LAUNDRY_STATUS_DRYING:  {LAUNDRY_STATUS_DONE},
+}
+
+// LaundryStatuses lists every status in the order a routine goes through them
+func LaundryStatuses() []LaundryStatus {
+	return []LaundryStatus{LAUNDRY_STATUS_PLANNED, LAUNDRY_STATUS_WASHING, LAUNDRY_STATUS_DRYING, LAUNDRY_STATUS_DONE, LAUNDRY_STATUS_CANCELLED}
+}
+
+// Key is the stable name of the status, used for translations and exports
+func (s LaundryStatus) Key() string {
+	if key, ok := laundryStatusKeys[s]; ok {
+		return key
+	}
+	return "unknown"
+}
+
+func (s LaundryStatus) NextStatuses() []LaundryStatus {
+	return laundryStatusTransitions[s]
+}
+
+func (s LaundryStatus) CanMoveTo(target LaundryStatus) bool {
+	for _, next := range laundryStatusTransitions[s] {
+		if next == target {
+			return true
+		}
+	}
+	return false
+}
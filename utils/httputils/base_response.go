@@ -2,6 +2,7 @@ package httputils
 
 import (
 	"github.com/audricimanuel/errorutils"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
 	"github.com/gin-gonic/gin"
 	"math"
 )
@@ -29,6 +30,7 @@ func SetHttpResponse(ctx *gin.Context, data interface{}, err error, meta *BaseMe
 
 	statusCode, message := errorutils.GetStatusCode(err)
 	if message != errorutils.SUCCESS {
+		message = i18n.TranslateError(i18n.LocaleFromContext(ctx), message)
 		errMsg = &message
 	}
 
@@ -0,0 +1,129 @@
+package httputils
+
+import (
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"github.com/audricimanuel/laundry-routine-tracking-service/utils/constants"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+type (
+	// ViewUser is the logged-in user as exposed to the templates
+	ViewUser struct {
+		Id       string
+		Email    string
+		FullName string
+	}
+
+	FlashMessage struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+
+	// FlashReader reads and clears the flash messages of the request, they're only read by the full pages
+	FlashReader func() []FlashMessage
+
+	Pagination struct {
+		Page    int
+		HasPrev bool
+		HasNext bool
+		PrevURL string
+		NextURL string
+	}
+)
+
+// SetHtmlResponse renders a full page, adding the data shared by every page:
+// "user", "csrf_token", "flashes", "env" and "locale"
+func SetHtmlResponse(ctx *gin.Context, statusCode int, name string, data gin.H) {
+	viewData := newViewData(ctx, data)
+
+	if readFlashes, ok := ctx.Get(constants.FLASH_MESSAGES); ok {
+		if flashes := readFlashes.(FlashReader)(); len(flashes) > 0 {
+			viewData["flashes"] = flashes
+		}
+	}
+
+	ctx.HTML(statusCode, name, viewData)
+}
+
+// SetHtmlFragmentResponse renders a fragment loaded into a page. The flash messages are left for the next full
+// page, a fragment would use them up without showing them.
+func SetHtmlFragmentResponse(ctx *gin.Context, statusCode int, name string, data gin.H) {
+	ctx.HTML(statusCode, name, newViewData(ctx, data))
+}
+
+func newViewData(ctx *gin.Context, data gin.H) gin.H {
+	viewData := gin.H{
+		"user":       nil,
+		"csrf_token": ctx.GetString(constants.CSRF_TOKEN),
+		"flashes":    []FlashMessage{},
+		"env":        ctx.GetString(constants.VIEW_ENV),
+		"locale":     i18n.LocaleFromContext(ctx),
+		"locales":    i18n.SupportedLocales(),
+	}
+
+	if userDataCtx, ok := ctx.Get(constants.USER_DATA); ok {
+		if userData, ok := userDataCtx.(model.UserClaims); ok {
+			viewData["user"] = &ViewUser{
+				Id:       userData.UserId,
+				Email:    userData.Email,
+				FullName: userData.FullName,
+			}
+		}
+	}
+
+	for key, value := range data {
+		viewData[key] = value
+	}
+
+	return viewData
+}
+
+// NewPagination builds the previous/next links of the current URL, keeping the other query params
+func NewPagination(ctx *gin.Context, page int, hasNext bool) *Pagination {
+	if page < 1 {
+		page = 1
+	}
+
+	pageURL := func(target int) string {
+		query := ctx.Request.URL.Query()
+		query.Set("page", strconv.Itoa(target))
+		return ctx.Request.URL.Path + "?" + query.Encode()
+	}
+
+	result := &Pagination{
+		Page:    page,
+		HasPrev: page > 1,
+		HasNext: hasNext,
+	}
+
+	if result.HasPrev {
+		result.PrevURL = pageURL(page - 1)
+	}
+
+	if result.HasNext {
+		result.NextURL = pageURL(page + 1)
+	}
+
+	return result
+}
+
+func (u *ViewUser) DisplayName() string {
+	if u.FullName != "" {
+		return u.FullName
+	}
+	return u.Email
+}
+
+func (u *ViewUser) Initials() string {
+	var initials string
+	for _, word := range strings.Fields(u.DisplayName()) {
+		initials += strings.ToUpper(string([]rune(word)[0]))
+		if len(initials) == 2 {
+			break
+		}
+	}
+	return initials
+}
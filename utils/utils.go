@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"github.com/google/uuid"
@@ -18,6 +19,16 @@ func TimeNow() time.Time {
 	return time.Now().UTC()
 }
 
+// LoadTimezone loads an IANA timezone, e.g. Asia/Jakarta. The name is also used by the database to convert
+// times, so aliases only Go understands such as "Local" aren't accepted.
+func LoadTimezone(name string) (*time.Location, error) {
+	location, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" || location.String() != name {
+		return nil, errors.New("invalid timezone")
+	}
+	return location, nil
+}
+
 func ConvertStrToInt(number string, defaultResult int) int {
 	result, err := strconv.Atoi(number)
 	if err != nil {
@@ -113,3 +124,12 @@ func GenerateOTP(length int) (string, error) {
 	}
 	return string(otp), nil
 }
+
+// GenerateToken returns a random hex token made of the given number of bytes
+func GenerateToken(length int) (string, error) {
+	token := make([]byte, length)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,118 @@
+package view
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	ASSET_URL_PREFIX      = "/static/"
+	assetHashLength       = 10
+	cacheControlImmutable = "public, max-age=31536000, immutable"
+	cacheControlNoCache   = "no-cache"
+)
+
+type (
+	// Assets serves the static files with content-hashed URLs, e.g. dashboard.js is
+	// exposed as /static/dashboard.3fa1c09b2e.js so it can be cached forever.
+	Assets struct {
+		files   fs.FS
+		devMode bool
+		// hashed maps the original file name to its hashed name
+		hashed map[string]string
+		// original maps the hashed file name back to the original one
+		original map[string]string
+	}
+)
+
+func NewAssets(devMode bool) (*Assets, error) {
+	staticFiles, err := fs.Sub(Files(devMode), STATIC_DIR)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := &Assets{
+		files:    staticFiles,
+		devMode:  devMode,
+		hashed:   map[string]string{},
+		original: map[string]string{},
+	}
+
+	// in dev mode the files change on disk, so they are served without hashes
+	if devMode {
+		return assets, nil
+	}
+
+	err = fs.WalkDir(staticFiles, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(staticFiles, name)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(content)
+		ext := path.Ext(name)
+		hashedName := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:assetHashLength] + ext
+
+		assets.hashed[name] = hashedName
+		assets.original[hashedName] = name
+		return nil
+	})
+
+	return assets, err
+}
+
+// URL returns the public URL of a static file, used by the "asset" template function
+func (a *Assets) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if hashedName, ok := a.hashed[name]; ok {
+		return ASSET_URL_PREFIX + hashedName
+	}
+	return ASSET_URL_PREFIX + name
+}
+
+// Handler serves the static files. Hashed URLs are cached for a year, everything else must be revalidated.
+func (a *Assets) Handler() gin.HandlerFunc {
+	startedAt := time.Now()
+
+	return func(ctx *gin.Context) {
+		name := strings.TrimPrefix(ctx.Param("filepath"), "/")
+
+		cacheControl := cacheControlNoCache
+		if originalName, ok := a.original[name]; ok {
+			name = originalName
+			cacheControl = cacheControlImmutable
+		}
+
+		stat, err := fs.Stat(a.files, name)
+		if err != nil || stat.IsDir() {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		content, err := fs.ReadFile(a.files, name)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		// embedded files have no modification time, fall back to the process start time
+		modTime := stat.ModTime()
+		if modTime.IsZero() {
+			modTime = startedAt
+		}
+
+		ctx.Header("Cache-Control", cacheControl)
+		http.ServeContent(ctx.Writer, ctx.Request, name, modTime, bytes.NewReader(content))
+	}
+}
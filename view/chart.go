@@ -0,0 +1,245 @@
+package view
+
+import (
+	"fmt"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/model"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The charts are drawn as inline SVG on the server so the pages don't need a charting script.
+// They scale to the width of their container through the viewBox.
+const (
+	chartWidth       = 640
+	chartHeight      = 260
+	chartPaddingLeft = 40
+	chartPaddingTop  = 12
+	chartPaddingEnd  = 12
+	chartAxisHeight  = 28
+	chartGridLines   = 4
+	chartMaxLabels   = 12
+	chartFontSize    = 11
+
+	barRowHeight  = 28
+	barLabelWidth = 140
+
+	donutSize   = 200
+	donutRadius = 70
+	donutStroke = 32
+
+	chartEmptyColor = "#e5e7eb"
+	chartTextColor  = "#6b7280"
+	chartGridColor  = "#f3f4f6"
+)
+
+// chartPalette colors the datasets and slices in order, datasetColors overrides it for well-known datasets
+var (
+	chartPalette  = []string{"#2563eb", "#0891b2", "#f59e0b", "#16a34a", "#dc2626", "#7c3aed", "#db2777", "#65a30d"}
+	datasetColors = map[string]string{
+		"done":      "#16a34a",
+		"cancelled": "#dc2626",
+	}
+)
+
+// ColumnChart draws the datasets of the series side by side for every label, e.g. the routines per month
+func ColumnChart(series model.ChartSeries) template.HTML {
+	plotWidth := float64(chartWidth - chartPaddingLeft - chartPaddingEnd)
+	plotHeight := float64(chartHeight - chartPaddingTop - chartAxisHeight)
+	maxValue, step := chartScale(seriesMax(series))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="w-full h-auto" role="img" font-size="%d" font-family="sans-serif">`, chartWidth, chartHeight, chartFontSize)
+
+	// horizontal grid with the value of each line
+	for i := 0; i <= chartGridLines; i++ {
+		value := step * float64(i)
+		y := chartPaddingTop + plotHeight - value/maxValue*plotHeight
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s"/>`, chartPaddingLeft, y, chartWidth-chartPaddingEnd, y, chartGridColor)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle" fill="%s">%s</text>`, chartPaddingLeft-6, y, chartTextColor, formatChartValue(value))
+	}
+
+	if len(series.Labels) > 0 && len(series.Datasets) > 0 {
+		groupWidth := plotWidth / float64(len(series.Labels))
+		barWidth := groupWidth * 0.8 / float64(len(series.Datasets))
+		labelEvery := int(math.Ceil(float64(len(series.Labels)) / chartMaxLabels))
+
+		for i, label := range series.Labels {
+			groupX := chartPaddingLeft + groupWidth*float64(i)
+
+			for j, dataset := range series.Datasets {
+				value := datasetValue(dataset, i)
+				height := value / maxValue * plotHeight
+				x := groupX + groupWidth*0.1 + barWidth*float64(j)
+				fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s</title></rect>`,
+					x, chartPaddingTop+plotHeight-height, barWidth, height, datasetColor(dataset, j),
+					chartTitle(label, dataset.Label, value))
+			}
+
+			if i%labelEvery == 0 {
+				fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%s</text>`,
+					groupX+groupWidth/2, chartPaddingTop+plotHeight+18, chartTextColor, template.HTMLEscapeString(label))
+			}
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	b.WriteString(chartLegend(series))
+
+	return template.HTML(b.String())
+}
+
+// BarChart draws the first dataset of the series as a horizontal bar per label, e.g. the items per category
+func BarChart(series model.ChartSeries) template.HTML {
+	height := barRowHeight*len(series.Labels) + chartPaddingTop
+	plotWidth := float64(chartWidth - barLabelWidth - chartPaddingEnd - 40)
+	maxValue, _ := chartScale(seriesMax(series))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="w-full h-auto" role="img" font-size="%d" font-family="sans-serif">`, chartWidth, height, chartFontSize)
+
+	if len(series.Datasets) > 0 {
+		dataset := series.Datasets[0]
+		for i, label := range series.Labels {
+			value := datasetValue(dataset, i)
+			width := value / maxValue * plotWidth
+			y := float64(chartPaddingTop + barRowHeight*i)
+
+			fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle" fill="%s">%s</text>`,
+				barLabelWidth-8, y+barRowHeight/2-4, chartTextColor, template.HTMLEscapeString(truncateLabel(label, 20)))
+			fmt.Fprintf(&b, `<rect x="%d" y="%.1f" width="%.1f" height="%d" rx="3" fill="%s"><title>%s</title></rect>`,
+				barLabelWidth, y, width, barRowHeight-8, chartPalette[i%len(chartPalette)], chartTitle(label, dataset.Label, value))
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" dominant-baseline="middle" fill="%s">%s</text>`,
+				barLabelWidth+width+6, y+barRowHeight/2-4, chartTextColor, formatChartValue(value))
+		}
+	}
+
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
+
+// DonutChart draws the first dataset of the series as the slices of a ring with a legend of the shares
+func DonutChart(series model.ChartSeries) template.HTML {
+	var dataset model.ChartDataset
+	if len(series.Datasets) > 0 {
+		dataset = series.Datasets[0]
+	}
+
+	total := 0.0
+	for _, value := range dataset.Data {
+		total += value
+	}
+
+	center := donutSize / 2
+	circumference := 2 * math.Pi * donutRadius
+
+	var b strings.Builder
+	b.WriteString(`<div class="flex flex-col sm:flex-row items-center gap-6">`)
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="w-48 h-48 shrink-0" role="img" font-family="sans-serif">`, donutSize, donutSize)
+	fmt.Fprintf(&b, `<circle cx="%d" cy="%d" r="%d" fill="none" stroke="%s" stroke-width="%d"/>`, center, center, donutRadius, chartEmptyColor, donutStroke)
+
+	// every slice is a dashed ring starting where the previous one ended, from the top going clockwise
+	offset := 0.0
+	for i, label := range series.Labels {
+		value := datasetValue(dataset, i)
+		if total == 0 || value == 0 {
+			continue
+		}
+
+		length := value / total * circumference
+		fmt.Fprintf(&b, `<circle cx="%d" cy="%d" r="%d" fill="none" stroke="%s" stroke-width="%d" stroke-dasharray="%.2f %.2f" stroke-dashoffset="%.2f" transform="rotate(-90 %d %d)"><title>%s</title></circle>`,
+			center, center, donutRadius, chartPalette[i%len(chartPalette)], donutStroke,
+			length, circumference-length, -offset, center, center, chartTitle(label, dataset.Label, value))
+		offset += length
+	}
+
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="middle" font-size="24" font-weight="600" fill="#1f2937">%s</text>`, center, center, formatChartValue(total))
+	b.WriteString(`</svg>`)
+
+	b.WriteString(`<ul class="text-sm space-y-1">`)
+	for i, label := range series.Labels {
+		value := datasetValue(dataset, i)
+
+		share := 0.0
+		if total > 0 {
+			share = value / total * 100
+		}
+
+		fmt.Fprintf(&b, `<li class="flex items-center gap-2"><span class="inline-block w-3 h-3 rounded-sm" style="background-color: %s"></span><span class="text-gray-700">%s</span><span class="text-gray-500">%s (%.0f%%)</span></li>`,
+			chartPalette[i%len(chartPalette)], template.HTMLEscapeString(label), formatChartValue(value), share)
+	}
+	b.WriteString(`</ul></div>`)
+
+	return template.HTML(b.String())
+}
+
+func chartLegend(series model.ChartSeries) string {
+	var b strings.Builder
+	b.WriteString(`<div class="flex flex-wrap gap-4 text-sm mt-2">`)
+	for i, dataset := range series.Datasets {
+		fmt.Fprintf(&b, `<span class="flex items-center gap-2"><span class="inline-block w-3 h-3 rounded-sm" style="background-color: %s"></span><span class="text-gray-700">%s</span></span>`,
+			datasetColor(dataset, i), template.HTMLEscapeString(dataset.Label))
+	}
+	b.WriteString(`</div>`)
+	return b.String()
+}
+
+// chartScale rounds the highest value up to a number the grid lines split evenly, it returns the top and the step
+func chartScale(maxValue float64) (float64, float64) {
+	if maxValue <= 0 {
+		return chartGridLines, 1
+	}
+
+	step := math.Ceil(maxValue / chartGridLines)
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, factor := range []float64{1, 2, 2.5, 5, 10} {
+		if niceStep := factor * magnitude; niceStep >= step && niceStep == math.Trunc(niceStep) {
+			step = niceStep
+			break
+		}
+	}
+
+	return step * chartGridLines, step
+}
+
+func seriesMax(series model.ChartSeries) float64 {
+	result := 0.0
+	for _, dataset := range series.Datasets {
+		for _, value := range dataset.Data {
+			result = math.Max(result, value)
+		}
+	}
+	return result
+}
+
+func datasetValue(dataset model.ChartDataset, index int) float64 {
+	if index < len(dataset.Data) {
+		return dataset.Data[index]
+	}
+	return 0
+}
+
+func datasetColor(dataset model.ChartDataset, index int) string {
+	if color, ok := datasetColors[dataset.Key]; ok {
+		return color
+	}
+	return chartPalette[index%len(chartPalette)]
+}
+
+func chartTitle(label, datasetLabel string, value float64) string {
+	return template.HTMLEscapeString(fmt.Sprintf("%s · %s: %s", label, datasetLabel, formatChartValue(value)))
+}
+
+func formatChartValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func truncateLabel(label string, length int) string {
+	runes := []rune(label)
+	if len(runes) <= length {
+		return label
+	}
+	return string(runes[:length-1]) + "…"
+}
@@ -0,0 +1,144 @@
+package view
+
+import (
+	"fmt"
+	"github.com/audricimanuel/laundry-routine-tracking-service/internal/i18n"
+	"github.com/gin-gonic/gin/render"
+	"html/template"
+	"io/fs"
+	"path"
+)
+
+const (
+	// LAYOUT_TEMPLATE is the entry point every page is rendered through
+	LAYOUT_TEMPLATE = "base"
+
+	layoutsPattern   = "layouts/*.html"
+	partialsPattern  = "partials/*.html"
+	pagesPattern     = "pages/*.html"
+	fragmentsPattern = "fragments/*.html"
+)
+
+type (
+	// Renderer implements gin's render.HTMLRender on top of the embedded templates.
+	//
+	// Pages (templates/pages) are wrapped in the base layout and fill its blocks,
+	// fragments (templates/fragments) are rendered on their own for partial updates.
+	// Both can use the shared partials. Templates are looked up by file name,
+	// e.g. ctx.HTML(http.StatusOK, "dashboard.html", data).
+	//
+	// In dev mode the templates are parsed again on every request.
+	Renderer struct {
+		devMode   bool
+		funcs     template.FuncMap
+		templates map[string]*templateEntry
+	}
+
+	templateEntry struct {
+		template *template.Template
+		// name of the template to execute
+		entrypoint string
+	}
+)
+
+func NewRenderer(devMode bool, assets *Assets) (*Renderer, error) {
+	r := &Renderer{
+		devMode: devMode,
+		funcs: template.FuncMap{
+			"asset":    assets.URL,
+			"t":        i18n.T,
+			"date":     i18n.FormatDate,
+			"datetime": i18n.FormatDateTime,
+			// inline SVG charts of a model.ChartSeries
+			"columnchart": ColumnChart,
+			"barchart":    BarChart,
+			"donutchart":  DonutChart,
+		},
+	}
+
+	templates, err := r.parse()
+	if err != nil {
+		return nil, err
+	}
+	r.templates = templates
+
+	return r, nil
+}
+
+func (r *Renderer) parse() (map[string]*templateEntry, error) {
+	templateFiles, err := fs.Sub(Files(r.devMode), TEMPLATES_DIR)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := template.New("").Funcs(r.funcs).ParseFS(templateFiles, layoutsPattern, partialsPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]*templateEntry{}
+
+	addTemplates := func(pattern string, withLayout bool) error {
+		files, err := fs.Glob(templateFiles, pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			name := path.Base(file)
+			if _, ok := result[name]; ok {
+				return fmt.Errorf("duplicate template name %s", name)
+			}
+
+			set, err := shared.Clone()
+			if err != nil {
+				return err
+			}
+
+			if set, err = set.ParseFS(templateFiles, file); err != nil {
+				return err
+			}
+
+			entrypoint := name
+			if withLayout {
+				entrypoint = LAYOUT_TEMPLATE
+			}
+
+			result[name] = &templateEntry{template: set, entrypoint: entrypoint}
+		}
+
+		return nil
+	}
+
+	if err := addTemplates(pagesPattern, true); err != nil {
+		return nil, err
+	}
+
+	if err := addTemplates(fragmentsPattern, false); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *Renderer) Instance(name string, data any) render.Render {
+	templates := r.templates
+	if r.devMode {
+		parsed, err := r.parse()
+		if err != nil {
+			panic(err)
+		}
+		templates = parsed
+	}
+
+	entry, ok := templates[name]
+	if !ok {
+		panic(fmt.Sprintf("html template %s is not found", name))
+	}
+
+	return render.HTML{
+		Template: entry.template,
+		Name:     entry.entrypoint,
+		Data:     data,
+	}
+}
@@ -1,21 +1,76 @@
 document.addEventListener("DOMContentLoaded", () => {
-  const buttons = document.querySelectorAll("button.view-details");
+  const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+  const modal = document.getElementById("viewDetailsModal");
+  const modalContent = document.getElementById("viewDetailsContent");
 
-  buttons.forEach(button => {
-    button.addEventListener("click", () => {
-      const card = button.closest("div.bg-white");
-      const title = card.querySelector("h3").textContent.trim();
-      console.log("Clicked View Details for:", title);
+  if (!modal) {
+    return;
+  }
+
+  const openModal = () => {
+    modal.classList.remove("hidden");
+    modal.classList.add("flex");
+  };
+
+  const closeModal = () => {
+    modal.classList.remove("flex");
+    modal.classList.add("hidden");
+  };
+
+  // renderDetail swaps the fragment returned by the server into the modal,
+  // sending the user back to the login page when the session is gone
+  const renderDetail = async (response) => {
+    if (response.status === 401) {
+      window.location.href = "/login";
+      return;
+    }
+    modalContent.innerHTML = await response.text();
+  };
+
+  const loadDetail = async (laundryId) => {
+    modalContent.innerHTML = '<p class="text-sm text-gray-500">Loading...</p>';
+    openModal();
 
-      document.getElementById('viewDetailsModal').classList.remove('hidden');
-      document.getElementById('viewDetailsModal').classList.add('flex');
+    try {
+      const response = await fetch(`/laundry/${encodeURIComponent(laundryId)}/details`, {
+        credentials: "same-origin",
+        headers: { "X-Requested-With": "fetch" },
+      });
+      await renderDetail(response);
+    } catch (err) {
+      modalContent.innerHTML = '<p class="text-sm text-red-600">Failed to load routine details.</p>';
+    }
+  };
+
+  document.querySelectorAll("button.view-details").forEach(button => {
+    button.addEventListener("click", () => {
+      const card = button.closest("[data-laundry-id]");
+      loadDetail(card.dataset.laundryId);
     });
   });
 
-  const closeViewDetailsModalButton = document.getElementById("closeViewDetailsModal")
+  modalContent.addEventListener("submit", async (e) => {
+    const form = e.target.closest("[data-status-form]");
+    if (!form) {
+      return;
+    }
+
+    e.preventDefault();
+
+    const submitButton = form.querySelector("button[type=submit]");
+    submitButton.disabled = true;
+    try {
+      const response = await fetch(form.action, {
+        method: "POST",
+        credentials: "same-origin",
+        headers: { "X-CSRF-Token": csrfToken, "X-Requested-With": "fetch" },
+        body: new URLSearchParams(new FormData(form)),
+      });
+      await renderDetail(response);
+    } catch (err) {
+      submitButton.disabled = false;
+    }
+  });
 
-  closeViewDetailsModalButton.addEventListener("click", () => {
-    document.getElementById('viewDetailsModal').classList.remove('flex');
-    document.getElementById('viewDetailsModal').classList.add('hidden');
-  })
-});
\ No newline at end of file
+  document.getElementById("closeViewDetailsModal").addEventListener("click", closeModal);
+});
@@ -1,22 +0,0 @@
-document.getElementById('signin-form').addEventListener('submit', async (e) => {
-  e.preventDefault();
-
-  const form = e.target;
-  const email = form.email.value;
-  const password = form.password.value;
-
-  const response = await fetch('/api/v1/auth/login', {
-    method: 'POST',
-    headers: {
-      'Content-Type': 'application/json',
-    },
-    body: JSON.stringify({ email, password }),
-  });
-
-  if (response.ok) {
-    window.location.href = '/';
-  } else {
-    const error = await response.json();
-    alert(error.error_message || 'Sign-in failed');
-  }
-});
\ No newline at end of file
@@ -0,0 +1,30 @@
+// Package view holds the HTML templates and static assets of the web UI.
+// Both are embedded into the binary so the server does not depend on the
+// working directory it is started from.
+package view
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+)
+
+const (
+	TEMPLATES_DIR = "templates"
+	STATIC_DIR    = "static"
+
+	// DEV_SOURCE_DIR is where the files are read from in dev mode, relative to the project root
+	DEV_SOURCE_DIR = "view"
+)
+
+//go:embed templates static
+var embedded embed.FS
+
+// Files returns the file system holding the templates and static assets.
+// In dev mode they are read from disk so changes show up without a rebuild.
+func Files(devMode bool) fs.FS {
+	if devMode {
+		return os.DirFS(DEV_SOURCE_DIR)
+	}
+	return embedded
+}